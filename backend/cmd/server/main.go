@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"time"
+	_ "time/tzdata" // event time zones must resolve even without system zoneinfo
 
 	"github.com/gin-gonic/gin"
 	"github.com/robaa12/mawid/config"
@@ -19,7 +21,7 @@ import (
 func testEventSorting(eventRepo *repository.EventRepository) {
 	fmt.Println("Testing event sorting...")
 	
-	events, total, err := eventRepo.GetAll(1, 20, 0)
+	events, total, err := eventRepo.GetAll(1, 20, repository.EventFilter{})
 	if err != nil {
 		fmt.Printf("Error fetching events: %v\n", err)
 		return
@@ -33,7 +35,7 @@ func testEventSorting(eventRepo *repository.EventRepository) {
 	
 	for i, e := range events {
 		tStr := ""
-		if e.EventDate.After(now) {
+		if e.EndDate.After(now) {
 			d := int(e.EventDate.Sub(now).Hours() / 24)
 			if d == 0 {
 				tStr = "TODAY!"
@@ -61,6 +63,43 @@ func testEventSorting(eventRepo *repository.EventRepository) {
 	fmt.Println("\nSorting test complete.")
 }
 
+// purgeTrash permanently removes everything that has been in the trash for
+// longer than the given number of days (30 by default)
+func purgeTrash(eventService *services.EventService, args []string) {
+	days := 30
+	if len(args) > 0 {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil || parsed < 0 {
+			log.Fatalf("Invalid purge age %q, expected a number of days", args[0])
+		}
+		days = parsed
+	}
+
+	report, err := eventService.PurgeTrash(time.Duration(days) * 24 * time.Hour)
+	if err != nil {
+		log.Fatalf("Purge failed: %v", err)
+	}
+
+	log.Printf("Purged items deleted more than %d days ago: %d events, %d bookings, %d categories, %d images",
+		days, report.Events, report.Bookings, report.Categories, report.Images)
+}
+
+// purgeTokens removes refresh tokens and token revocations that have expired,
+// along with failed login counters that no longer count
+func purgeTokens(authService *services.AuthService) {
+	purged, err := authService.PurgeExpiredTokens()
+	if err != nil {
+		log.Fatalf("Token purge failed: %v", err)
+	}
+	log.Printf("Purged %d expired tokens", purged)
+
+	throttles, err := authService.PurgeLoginThrottles()
+	if err != nil {
+		log.Fatalf("Login throttle purge failed: %v", err)
+	}
+	log.Printf("Purged %d login throttles", throttles)
+}
+
 func main() {
 	startTime := time.Now()
 	log.Printf("Mawid server starting at %s", startTime.Format(time.RFC3339))
@@ -76,6 +115,10 @@ func main() {
 	userRepo := repository.NewUserRepository(database)
 	eventRepo := repository.NewEventRepository(database)
 	bookingRepo := repository.NewBookingRepository(database)
+	waitlistRepo := repository.NewWaitlistRepository(database)
+	orgRepo := repository.NewOrganizationRepository(database)
+	tokenRepo := repository.NewTokenRepository(database)
+	throttleRepo := repository.NewLoginThrottleRepository(database)
 	err := userRepo.CreateAdminIfNotExists(cfg.AdminEmail)
 	if err != nil {
 		log.Printf("Failed to create admin user: %v", err)
@@ -86,17 +129,28 @@ func main() {
 		return
 	}
 
-	authService := services.NewAuthService(userRepo, cfg)
+	storageService := utils.NewStorageService(cfg)
+	authService := services.NewAuthService(userRepo, orgRepo, tokenRepo, throttleRepo, bookingRepo, waitlistRepo, storageService, utils.NewMailer(cfg), cfg)
 	authHandler := handlers.NewAuthHandler(authService)
 
-	storageService := utils.NewStorageService(cfg)
-	eventService := services.NewEventService(eventRepo, storageService, bookingRepo)
+	eventService := services.NewEventService(eventRepo, orgRepo, storageService, bookingRepo, waitlistRepo)
 	eventHandler := handlers.NewEventHandler(eventService)
 
-	bookingService := services.NewBookingService(bookingRepo, eventRepo, userRepo)
+	if len(os.Args) > 1 && os.Args[1] == "--purge" {
+		purgeTrash(eventService, os.Args[2:])
+		purgeTokens(authService)
+		return
+	}
+
+	bookingService := services.NewBookingService(bookingRepo, eventRepo, userRepo, waitlistRepo, cfg)
 	bookingHandler := handlers.NewBookingHandler(bookingService)
 
 	router := gin.Default()
+	// The client IP keys login throttling, so forwarding headers are only
+	// believed when they come from a configured proxy
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
 	api.SetupRoutes(router, authHandler, eventHandler, bookingHandler, cfg)
 
 	log.Printf("✅ Server initialized in %v", time.Since(startTime))
@@ -4,6 +4,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -22,6 +24,10 @@ type Config struct {
 	JWTSecret  string
 	ServerPort string
 	AdminEmail string
+	// Access tokens are short-lived; clients renew them with a refresh
+	// token, which lasts much longer and is rotated on every use
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
 
 	// Storage settings
 	SupabaseURL       string
@@ -29,6 +35,39 @@ type Config struct {
 	SupabaseBucket    string
 	SupabasePublicURL string
 	MaxUploadSize     int64
+
+	// Booking settings
+	WaitlistClaimWindow time.Duration
+	MaxSeatsPerBooking  int
+	SeatHoldDuration    time.Duration
+
+	// Mail settings. MailDriver is "smtp" to deliver mail or "file" to
+	// write it to MailOutboxDir instead, for development and tests.
+	MailDriver    string
+	SMTPHost      string
+	SMTPPort      string
+	SMTPUsername  string
+	SMTPPassword  string
+	MailFrom      string
+	MailOutboxDir string
+	// AppBaseURL is where links in emails point to
+	AppBaseURL           string
+	EmailVerificationTTL time.Duration
+	// PasswordResetURL is the frontend page password reset links open, with
+	// the token appended as a query parameter
+	PasswordResetURL string
+	PasswordResetTTL time.Duration
+
+	// Login throttling. An email is locked out for LoginLockoutDuration
+	// after LoginMaxAttempts failures within that time, a client IP after
+	// LoginIPMaxAttempts.
+	LoginMaxAttempts     int
+	LoginIPMaxAttempts   int
+	LoginLockoutDuration time.Duration
+	// TrustedProxies are the addresses or CIDR ranges of the reverse proxies
+	// whose X-Forwarded-For header is believed. Without any, the client IP
+	// is the address the request came from.
+	TrustedProxies []string
 }
 
 // Load Server configration
@@ -55,12 +94,40 @@ func LoadConfig() *Config {
 		ServerPort: getEnv("SERVER_PORT", "8080"),
 		AdminEmail: getEnv("ADMIN_EMAIL", "admin@mawid.com"),
 
+		// Token lifetimes
+		AccessTokenTTL:  time.Duration(GetEnvAsInt("ACCESS_TOKEN_MINUTES", 15)) * time.Minute,
+		RefreshTokenTTL: time.Duration(GetEnvAsInt("REFRESH_TOKEN_DAYS", 30)) * 24 * time.Hour,
+
 		// Storage Settings
 		SupabaseURL:       getEnv("SUPABASE_URL", ""),
 		SupabaseKey:       getEnv("SUPABASE_KEY", ""),
 		SupabaseBucket:    getEnv("SUPABASE_BUCKET", "event-images"),
 		SupabasePublicURL: getEnv("SUPABASE_PUBLIC_URL", ""),
 		MaxUploadSize:     maxUploadSize,
+
+		// Booking settings
+		WaitlistClaimWindow: time.Duration(GetEnvAsInt("WAITLIST_CLAIM_WINDOW_MINUTES", 60)) * time.Minute,
+		MaxSeatsPerBooking:  GetEnvAsInt("MAX_SEATS_PER_BOOKING", 10),
+		SeatHoldDuration:    time.Duration(GetEnvAsInt("SEAT_HOLD_MINUTES", 15)) * time.Minute,
+
+		// Mail settings
+		MailDriver:           getEnv("MAIL_DRIVER", "file"),
+		SMTPHost:             getEnv("SMTP_HOST", ""),
+		SMTPPort:             getEnv("SMTP_PORT", "587"),
+		SMTPUsername:         getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:         getEnv("SMTP_PASSWORD", ""),
+		MailFrom:             getEnv("MAIL_FROM", "Mawid <no-reply@mawid.com>"),
+		MailOutboxDir:        getEnv("MAIL_OUTBOX_DIR", "outbox"),
+		AppBaseURL:           getEnv("APP_BASE_URL", "http://localhost:8080"),
+		EmailVerificationTTL: time.Duration(GetEnvAsInt("EMAIL_VERIFICATION_HOURS", 48)) * time.Hour,
+		PasswordResetURL:     getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		PasswordResetTTL:     time.Duration(GetEnvAsInt("PASSWORD_RESET_MINUTES", 60)) * time.Minute,
+
+		// Login throttling
+		LoginMaxAttempts:     GetEnvAsInt("LOGIN_MAX_ATTEMPTS", 5),
+		LoginIPMaxAttempts:   GetEnvAsInt("LOGIN_IP_MAX_ATTEMPTS", 50),
+		LoginLockoutDuration: time.Duration(GetEnvAsInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		TrustedProxies:       getEnvAsList("TRUSTED_PROXIES"),
 	}
 }
 
@@ -72,6 +139,18 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// getEnvAsList splits a comma separated variable, returning nil when it is
+// unset or empty
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func GetEnvAsInt(key string, defaultValue int) int {
 	valueStr := getEnv(key, "")
 	if value, err := strconv.Atoi(valueStr); err == nil {
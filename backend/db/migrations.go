@@ -3,6 +3,7 @@ package db
 import (
 	"log"
 
+	"github.com/robaa12/mawid/internal/utils"
 	"github.com/robaa12/mawid/pkg/models"
 	"gorm.io/gorm"
 )
@@ -10,12 +11,161 @@ import (
 func RunMigrations(db *gorm.DB) error {
 	log.Println("Running database migrations... ")
 
-	err := db.AutoMigrate(&models.User{}, &models.Category{}, &models.Event{}, &models.EventTag{}, &models.Tag{}, &models.Booking{})
+	// Users who signed up before email verification existed keep booking
+	verifiedBefore := db.Migrator().HasTable(&models.User{}) && !db.Migrator().HasColumn(&models.User{}, "email_verified_at")
+	// Attendees booked before they were linked to users are matched by email
+	attendeesUnlinked := db.Migrator().HasTable(&models.Attendee{}) && !db.Migrator().HasColumn(&models.Attendee{}, "user_id")
+
+	err := db.AutoMigrate(&models.Organization{}, &models.User{}, &models.Category{}, &models.Event{}, &models.TicketType{}, &models.EventTag{}, &models.Tag{}, &models.Booking{}, &models.Attendee{}, &models.BookingStatusHistory{}, &models.WaitlistEntry{}, &models.EventSeries{}, &models.Venue{}, &models.EventTranslation{}, &models.CategoryTranslation{}, &models.RefreshToken{}, &models.RevokedToken{}, &models.PasswordResetToken{}, &models.LoginThrottle{})
 	if err != nil {
 		log.Printf("Migration failed: %v", err)
 		return err
 	}
 
+	if verifiedBefore {
+		if err := db.Exec("UPDATE users SET email_verified_at = NOW() WHERE email_verified_at IS NULL").Error; err != nil {
+			log.Printf("Migration failed: %v", err)
+			return err
+		}
+	}
+
+	if attendeesUnlinked {
+		err := db.Exec(`UPDATE attendees SET user_id = bookings.user_id
+			FROM bookings JOIN users ON users.id = bookings.user_id
+			WHERE attendees.booking_id = bookings.id AND attendees.email = users.email`).Error
+		if err != nil {
+			log.Printf("Migration failed: %v", err)
+			return err
+		}
+	}
+
+	// Bookings used to store a zero time instead of NULL when not deleted
+	if err := db.Exec("UPDATE bookings SET deleted_at = NULL WHERE deleted_at < '1900-01-01'").Error; err != nil {
+		log.Printf("Migration failed: %v", err)
+		return err
+	}
+
+	// Events created before end times existed end when they start
+	if err := db.Exec("UPDATE events SET end_date = event_date WHERE end_date IS NULL").Error; err != nil {
+		log.Printf("Migration failed: %v", err)
+		return err
+	}
+
+	// Categories created before slugs existed get one from their name
+	if err := backfillCategorySlugs(db); err != nil {
+		log.Printf("Migration failed: %v", err)
+		return err
+	}
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_slug ON categories(slug)").Error; err != nil {
+		log.Printf("Migration failed: %v", err)
+		return err
+	}
+
+	// Category names used to be unique across every organization, parent
+	// and the trash. They are now unique among live siblings only.
+	if err := scopeCategoryNames(db); err != nil {
+		log.Printf("Migration failed: %v", err)
+		return err
+	}
+
+	// Tags used to be matched case-sensitively, leaving duplicates such as
+	// "Music" and "music". Fold each group into its oldest tag.
+	if err := mergeDuplicateTags(db); err != nil {
+		log.Printf("Migration failed: %v", err)
+		return err
+	}
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name_lower ON tags(LOWER(name))").Error; err != nil {
+		log.Printf("Migration failed: %v", err)
+		return err
+	}
+
+	if err := setupEventSearch(db); err != nil {
+		log.Printf("Migration failed: %v", err)
+		return err
+	}
+
 	log.Println("Migration completed successfully")
 	return nil
 }
+
+func backfillCategorySlugs(db *gorm.DB) error {
+	var categories []models.Category
+	if err := db.Unscoped().Order("id ASC").Find(&categories).Error; err != nil {
+		return err
+	}
+
+	taken := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		if category.Slug != "" {
+			taken[category.Slug] = true
+		}
+	}
+
+	for _, category := range categories {
+		if category.Slug != "" {
+			continue
+		}
+
+		slug, _ := utils.UniqueSlug(utils.Slugify(category.Name), "category", func(slug string) (bool, error) {
+			return taken[slug], nil
+		})
+		if err := db.Unscoped().Model(&models.Category{}).Where("id = ?", category.ID).Update("slug", slug).Error; err != nil {
+			return err
+		}
+		taken[slug] = true
+	}
+	return nil
+}
+
+// categoryNameIndex keeps the names of live categories under one parent of
+// one organization distinct, regardless of letter case
+const categoryNameIndex = `CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_scope_name
+	ON categories (COALESCE(organization_id, 0), COALESCE(parent_id, 0), LOWER(name))
+	WHERE deleted_at IS NULL`
+
+func scopeCategoryNames(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, constraint := range []string{"uni_categories_name", "categories_name_key"} {
+			if err := tx.Exec("ALTER TABLE categories DROP CONSTRAINT IF EXISTS " + constraint).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("DROP INDEX IF EXISTS idx_categories_name").Error; err != nil {
+			return err
+		}
+
+		// Names that only differ in letter case would break the new index,
+		// so all but the oldest of them get their ID appended
+		err := tx.Exec(`UPDATE categories SET name = LEFT(name, 90) || ' (' || id || ')'
+			WHERE deleted_at IS NULL AND id NOT IN (
+				SELECT MIN(id) FROM categories WHERE deleted_at IS NULL
+				GROUP BY COALESCE(organization_id, 0), COALESCE(parent_id, 0), LOWER(name))`).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(categoryNameIndex).Error
+	})
+}
+
+func mergeDuplicateTags(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		duplicates := "SELECT id FROM tags WHERE id NOT IN (SELECT MIN(id) FROM tags GROUP BY LOWER(name))"
+
+		err := tx.Exec(`INSERT INTO event_tags (event_id, tag_id, created_at)
+			SELECT event_tags.event_id, keep.id, event_tags.created_at
+			FROM event_tags
+			JOIN tags ON tags.id = event_tags.tag_id
+			JOIN (SELECT MIN(id) AS id, LOWER(name) AS name FROM tags GROUP BY LOWER(name)) AS keep ON keep.name = LOWER(tags.name)
+			WHERE tags.id <> keep.id
+			ON CONFLICT DO NOTHING`).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM event_tags WHERE tag_id IN (" + duplicates + ")").Error; err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM tags WHERE id IN (" + duplicates + ")").Error
+	})
+}
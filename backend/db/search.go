@@ -0,0 +1,103 @@
+package db
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// eventSearchStatements keep events.search_vector, the weighted full-text
+// document of an event, up to date. Names weigh most, then tags and the
+// category, then the venue and finally the description. The 'simple'
+// configuration is used because events are written in both Arabic and
+// English. Changes to tags, tag names and category names clear the vector
+// of the affected events so the events trigger rebuilds it.
+var eventSearchStatements = []string{
+	"ALTER TABLE events ADD COLUMN IF NOT EXISTS search_vector tsvector",
+
+	`CREATE OR REPLACE FUNCTION event_search_vector(event_id bigint, name text, description text, venue text, category_id bigint)
+	RETURNS tsvector LANGUAGE sql STABLE AS $$
+		SELECT setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce((
+				SELECT string_agg(tags.name, ' ') FROM event_tags
+				JOIN tags ON tags.id = event_tags.tag_id
+				WHERE event_tags.event_id = event_search_vector.event_id), '')), 'B') ||
+			setweight(to_tsvector('simple', coalesce((
+				SELECT categories.name FROM categories
+				WHERE categories.id = event_search_vector.category_id), '')), 'B') ||
+			setweight(to_tsvector('simple', coalesce(venue, '')), 'C') ||
+			setweight(to_tsvector('simple', coalesce(description, '')), 'D')
+	$$`,
+
+	`CREATE OR REPLACE FUNCTION events_search_vector_update() RETURNS trigger LANGUAGE plpgsql AS $$
+	BEGIN
+		NEW.search_vector := event_search_vector(NEW.id, NEW.name, NEW.description, NEW.venue, NEW.category_id);
+		RETURN NEW;
+	END
+	$$`,
+	"DROP TRIGGER IF EXISTS events_search_vector_update ON events",
+	`CREATE TRIGGER events_search_vector_update BEFORE INSERT OR UPDATE ON events
+	FOR EACH ROW EXECUTE FUNCTION events_search_vector_update()`,
+
+	`CREATE OR REPLACE FUNCTION event_tags_search_vector_update() RETURNS trigger LANGUAGE plpgsql AS $$
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			UPDATE events SET search_vector = NULL WHERE id = OLD.event_id;
+			RETURN OLD;
+		END IF;
+		UPDATE events SET search_vector = NULL WHERE id = NEW.event_id;
+		RETURN NEW;
+	END
+	$$`,
+	"DROP TRIGGER IF EXISTS event_tags_search_vector_update ON event_tags",
+	`CREATE TRIGGER event_tags_search_vector_update AFTER INSERT OR DELETE ON event_tags
+	FOR EACH ROW EXECUTE FUNCTION event_tags_search_vector_update()`,
+
+	`CREATE OR REPLACE FUNCTION tags_search_vector_update() RETURNS trigger LANGUAGE plpgsql AS $$
+	BEGIN
+		UPDATE events SET search_vector = NULL
+		WHERE id IN (SELECT event_id FROM event_tags WHERE tag_id = NEW.id);
+		RETURN NEW;
+	END
+	$$`,
+	"DROP TRIGGER IF EXISTS tags_search_vector_update ON tags",
+	`CREATE TRIGGER tags_search_vector_update AFTER UPDATE OF name ON tags
+	FOR EACH ROW EXECUTE FUNCTION tags_search_vector_update()`,
+
+	`CREATE OR REPLACE FUNCTION categories_search_vector_update() RETURNS trigger LANGUAGE plpgsql AS $$
+	BEGIN
+		UPDATE events SET search_vector = NULL WHERE category_id = NEW.id;
+		RETURN NEW;
+	END
+	$$`,
+	"DROP TRIGGER IF EXISTS categories_search_vector_update ON categories",
+	`CREATE TRIGGER categories_search_vector_update AFTER UPDATE OF name ON categories
+	FOR EACH ROW EXECUTE FUNCTION categories_search_vector_update()`,
+
+	// Build the vector of events created before search existed
+	"UPDATE events SET search_vector = NULL WHERE search_vector IS NULL",
+	"CREATE INDEX IF NOT EXISTS idx_events_search_vector ON events USING gin (search_vector)",
+}
+
+// setupEventSearch installs full-text search over events and the trigram
+// indexes used when a search finds no exact matches. Trigram matching is
+// optional: without the pg_trgm extension searches fall back to ILIKE.
+func setupEventSearch(db *gorm.DB) error {
+	for _, statement := range eventSearchStatements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Println("Warning: Could not create pg_trgm extension. Searches will not tolerate typos.")
+		return nil
+	}
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_events_name_trgm ON events USING gin (name gin_trgm_ops)").Error; err != nil {
+		log.Println("Warning: Could not create trigram index on event names.")
+	}
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_events_venue_trgm ON events USING gin (venue gin_trgm_ops)").Error; err != nil {
+		log.Println("Warning: Could not create trigram index on event venues.")
+	}
+	return nil
+}
@@ -25,11 +25,24 @@ func SetupDatabase(db *gorm.DB) error {
 	// Auto-migrate the schemas
 	log.Println("Migrating database schemas...")
 	err = db.AutoMigrate(
+		&models.Organization{},
 		&models.User{},
 		&models.Event{},
+		&models.TicketType{},
 		&models.Category{},
 		&models.Tag{},
 		&models.Booking{},
+		&models.Attendee{},
+		&models.BookingStatusHistory{},
+		&models.WaitlistEntry{},
+		&models.EventSeries{},
+		&models.Venue{},
+		&models.EventTranslation{},
+		&models.CategoryTranslation{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.PasswordResetToken{},
+		&models.LoginThrottle{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to auto-migrate schemas: %w", err)
@@ -42,6 +55,11 @@ func SetupDatabase(db *gorm.DB) error {
 		return fmt.Errorf("failed to add indexes: %w", err)
 	}
 
+	log.Println("Setting up event search...")
+	if err := setupEventSearch(db); err != nil {
+		return fmt.Errorf("failed to set up event search: %w", err)
+	}
+
 	// Add database constraints
 	log.Println("Setting up database constraints...")
 	if err != nil {
@@ -61,6 +79,9 @@ func addIndexes(db *gorm.DB) error {
 	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_events_event_date ON events(event_date)").Error; err != nil {
 		return err
 	}
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_events_end_date ON events(end_date)").Error; err != nil {
+		return err
+	}
 	// Improved name search with trigram index for better search performance
 	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
 		log.Println("Warning: Could not create pg_trgm extension. Text search performance might be affected.")
@@ -78,6 +99,19 @@ func addIndexes(db *gorm.DB) error {
 		return err
 	}
 
+	// Category indexes
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_slug ON categories(slug)").Error; err != nil {
+		return err
+	}
+	if err := db.Exec(categoryNameIndex).Error; err != nil {
+		return err
+	}
+
+	// Tag names are unique regardless of letter case
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name_lower ON tags(LOWER(name))").Error; err != nil {
+		return err
+	}
+
 	// User indexes
 	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email)").Error; err != nil {
 		return err
@@ -93,6 +127,10 @@ func addIndexes(db *gorm.DB) error {
 	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_bookings_user_status ON bookings(user_id, status)").Error; err != nil {
 		return err
 	}
+	// Add index for counting booked seats per event during capacity checks
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_bookings_event_status ON bookings(event_id, status)").Error; err != nil {
+		return err
+	}
 	// Add index for booking date for more efficient sorting
 	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_bookings_booking_date ON bookings(booking_date DESC)").Error; err != nil {
 		return err
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Supported locales. Content and messages without a translation fall back
+// to DefaultLocale.
+const (
+	LocaleEnglish = "en"
+	LocaleArabic  = "ar"
+	DefaultLocale = LocaleEnglish
+)
+
+// localeKey is where the locale of a request is kept in the gin context
+const localeKey = "locale"
+
+// Localizable is implemented by response data that carries translated
+// content. Localize returns a copy in the given locale, leaving cached
+// values untouched.
+type Localizable interface {
+	Localize(locale string) any
+}
+
+// IsSupportedLocale reports whether content can be translated into locale
+func IsSupportedLocale(locale string) bool {
+	return locale == LocaleEnglish || locale == LocaleArabic
+}
+
+// NormalizeLocale reduces a language tag such as "ar-EG" or "EN_us" to one
+// of the supported locales, or returns an empty string if there is none
+func NormalizeLocale(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if IsSupportedLocale(tag) {
+		return tag
+	}
+	return ""
+}
+
+// ResolveLocale picks the locale of a request. An explicit lang parameter
+// wins, then the supported language the Accept-Language header prefers
+// most, then DefaultLocale.
+func ResolveLocale(lang, acceptLanguage string) string {
+	if locale := NormalizeLocale(lang); locale != "" {
+		return locale
+	}
+
+	type candidate struct {
+		locale  string
+		quality float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		locale := NormalizeLocale(fields[0])
+		if locale == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{locale, quality})
+		}
+	}
+
+	// Languages of equal quality keep the order the client listed them in
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	if len(candidates) > 0 {
+		return candidates[0].locale
+	}
+	return DefaultLocale
+}
+
+// SetLocale records the locale a request is served in
+func SetLocale(c *gin.Context, locale string) {
+	c.Set(localeKey, locale)
+}
+
+// Locale returns the locale of a request, resolving it from the request
+// itself when no middleware has done so yet
+func Locale(c *gin.Context) string {
+	if locale, ok := c.Get(localeKey); ok {
+		if value, ok := locale.(string); ok {
+			return value
+		}
+	}
+	return ResolveLocale(c.Query("lang"), c.GetHeader("Accept-Language"))
+}
+
+// Translate looks a message up in the catalog. Messages are keyed by their
+// English text, which is also what is returned when there is no
+// translation.
+func Translate(locale, message string) string {
+	if translated, ok := messages[locale][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// T translates a message into the locale of a request
+func T(c *gin.Context, message string) string {
+	return Translate(Locale(c), message)
+}
+
+// LocalizedError is an error whose message is made from a catalog entry and
+// the values filled into it. Unlike a message formatted up front, it can be
+// translated before the values go in.
+type LocalizedError struct {
+	Format string
+	Args   []any
+	err    error
+}
+
+// Errorf works like fmt.Errorf, including %w, for errors that are shown to
+// users
+func Errorf(format string, args ...any) error {
+	return &LocalizedError{Format: format, Args: args, err: fmt.Errorf(format, args...)}
+}
+
+func (e *LocalizedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *LocalizedError) Unwrap() error {
+	return errors.Unwrap(e.err)
+}
+
+// LocalizeError translates an error's message into locale. The errors a
+// LocalizedError is made from are translated along with it.
+func LocalizeError(locale string, err error) string {
+	localized, ok := err.(*LocalizedError)
+	if !ok {
+		return Translate(locale, err.Error())
+	}
+
+	args := make([]any, len(localized.Args))
+	for i, arg := range localized.Args {
+		if inner, ok := arg.(error); ok {
+			arg = errors.New(LocalizeError(locale, inner))
+		}
+		args[i] = arg
+	}
+	return fmt.Errorf(Translate(locale, localized.Format), args...).Error()
+}
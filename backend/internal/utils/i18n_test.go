@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolveLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		lang           string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "nothing asked for", want: LocaleEnglish},
+		{name: "lang parameter", lang: "ar", want: LocaleArabic},
+		{name: "lang parameter wins over the header", lang: "en", acceptLanguage: "ar", want: LocaleEnglish},
+		{name: "unsupported lang parameter falls back to the header", lang: "fr", acceptLanguage: "ar", want: LocaleArabic},
+		{name: "regional lang parameter", lang: "AR_eg", want: LocaleArabic},
+		{name: "regional tag", acceptLanguage: "ar-EG", want: LocaleArabic},
+		{name: "first of equal quality", acceptLanguage: "ar-SA, en-US", want: LocaleArabic},
+		{name: "highest quality", acceptLanguage: "en;q=0.5, ar;q=0.8", want: LocaleArabic},
+		{name: "implicit quality is highest", acceptLanguage: "ar;q=0.9, en", want: LocaleEnglish},
+		{name: "unsupported languages are skipped", acceptLanguage: "fr-FR, de;q=0.9, ar;q=0.1", want: LocaleArabic},
+		{name: "quality zero is refused", acceptLanguage: "ar;q=0, fr", want: LocaleEnglish},
+		{name: "wildcard", acceptLanguage: "*", want: LocaleEnglish},
+		{name: "malformed quality counts as highest", acceptLanguage: "en;q=0.5, ar;q=high", want: LocaleArabic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveLocale(tt.lang, tt.acceptLanguage); got != tt.want {
+				t.Errorf("ResolveLocale(%q, %q) = %q, want %q", tt.lang, tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizeError(t *testing.T) {
+	notFound := errors.New("event not found")
+	err := Errorf("failed to delete event %d: %w", 7, Errorf("%w: %s to %s", notFound, "draft", "cancelled"))
+
+	if !errors.Is(err, notFound) {
+		t.Error("wrapped error is lost")
+	}
+	if got, want := LocalizeError(LocaleEnglish, err), err.Error(); got != want {
+		t.Errorf("English message = %q, want %q", got, want)
+	}
+
+	want := "تعذر حذف الفعالية 7: الفعالية غير موجودة: من draft إلى cancelled"
+	if got := LocalizeError(LocaleArabic, err); got != want {
+		t.Errorf("Arabic message = %q, want %q", got, want)
+	}
+	if got := LocalizeError(LocaleArabic, errors.New("no such message")); got != "no such message" {
+		t.Errorf("untranslated message = %q", got)
+	}
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robaa12/mawid/config"
+)
+
+// MailMessage is a plain text email to a single recipient
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails to users
+type Mailer interface {
+	Send(msg MailMessage) error
+}
+
+// NewMailer returns the mailer chosen by MAIL_DRIVER. Without an SMTP host
+// mail goes to the outbox directory, so nothing is sent by accident.
+func NewMailer(cfg *config.Config) Mailer {
+	if cfg.MailDriver == "smtp" && cfg.SMTPHost != "" {
+		return NewSMTPMailer(cfg)
+	}
+	if cfg.MailDriver == "smtp" {
+		log.Printf("SMTP_HOST is not set, writing mail to %s instead", cfg.MailOutboxDir)
+	}
+	return NewFileMailer(cfg.MailOutboxDir, cfg.MailFrom)
+}
+
+// SMTPMailer sends mail through an SMTP server, authenticating when a
+// username is configured
+type SMTPMailer struct {
+	Addr string
+	From string
+	Auth smtp.Auth
+}
+
+func NewSMTPMailer(cfg *config.Config) *SMTPMailer {
+	mailer := &SMTPMailer{
+		Addr: net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort),
+		From: cfg.MailFrom,
+	}
+	if cfg.SMTPUsername != "" {
+		mailer.Auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return mailer
+}
+
+func (m *SMTPMailer) Send(msg MailMessage) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	if err := smtp.SendMail(m.Addr, m.Auth, from.Address, []string{msg.To}, composeMail(m.From, msg)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// FileMailer writes each email to its own .eml file in Dir instead of
+// sending it, for local development and tests
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{Dir: dir, From: from}
+}
+
+func (m *FileMailer) Send(msg MailMessage) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create outbox: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.New().String())
+	path := filepath.Join(m.Dir, name)
+	if err := os.WriteFile(path, composeMail(m.From, msg), 0o644); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+
+	log.Printf("Mail to %s written to %s", msg.To, path)
+	return nil
+}
+
+func composeMail(from string, msg MailMessage) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,381 @@
+package utils
+
+// messages is the catalog of user-facing API messages, keyed by locale and
+// then by the English message. English needs no entries.
+var messages = map[string]map[string]string{
+	LocaleArabic: {
+		// Authentication
+		"Authorization header required":  "يلزم إرسال ترويسة التفويض",
+		"Invalid authorization format":   "صيغة التفويض غير صالحة",
+		"Invalid token":                  "رمز الدخول غير صالح",
+		"Unauthorized":                   "غير مصرح",
+		"Forbidden":                      "غير مسموح",
+		"Token has been revoked":         "تم إلغاء رمز الدخول",
+		"Admin access required":          "يتطلب صلاحيات المسؤول",
+		"Organizer access required":      "يتطلب صلاحيات المنظم",
+		"User not authenticated":         "المستخدم غير مسجل الدخول",
+		"Invalid email or password":      "البريد الإلكتروني أو كلمة المرور غير صحيحة",
+		"Login failed":                   "فشل تسجيل الدخول",
+		"Token refreshed successfully":   "تم تجديد الجلسة بنجاح",
+		"Token refresh failed":           "تعذر تجديد الجلسة",
+		"Logged out successfully":        "تم تسجيل الخروج بنجاح",
+		"Logout failed":                  "تعذر تسجيل الخروج",
+		"Email verified successfully":    "تم تأكيد البريد الإلكتروني بنجاح",
+		"Email verification failed":      "تعذر تأكيد البريد الإلكتروني",
+		"Verification token is required": "رمز التأكيد مطلوب",
+		"Password reset failed":          "تعذرت إعادة تعيين كلمة المرور",
+		"Password change failed":         "تعذر تغيير كلمة المرور",
+		"Password changed successfully":  "تم تغيير كلمة المرور بنجاح",
+		"Password reset successfully, please log in with your new password":        "تمت إعادة تعيين كلمة المرور بنجاح، يرجى تسجيل الدخول بكلمة المرور الجديدة",
+		"If an account exists for this email, a password reset link has been sent": "إذا كان هناك حساب مرتبط بهذا البريد الإلكتروني، فقد أُرسل إليه رابط إعادة تعيين كلمة المرور",
+		"Profile updated successfully":                                             "تم تحديث الملف الشخصي بنجاح",
+		"Failed to update profile":                                                 "تعذر تحديث الملف الشخصي",
+		"A user with this email already exists":                                    "يوجد مستخدم بهذا البريد الإلكتروني بالفعل",
+		"User unlocked successfully":                                               "تم رفع القفل عن المستخدم بنجاح",
+		"Failed to unlock user":                                                    "تعذر رفع القفل عن المستخدم",
+		"Account deleted successfully":                                             "تم حذف الحساب بنجاح",
+		"Failed to delete account":                                                 "تعذر حذف الحساب",
+		"Verification email sent":                                                  "تم إرسال رسالة التأكيد",
+		"Failed to resend verification email":                                      "تعذر إعادة إرسال رسالة التأكيد",
+		"Login successful":                                                         "تم تسجيل الدخول بنجاح",
+		"Registration failed":                                                      "فشل إنشاء الحساب",
+		"User registered successfully":                                             "تم إنشاء الحساب بنجاح",
+		"Profile not found":                                                        "الملف الشخصي غير موجود",
+		"Profile retrieved successfully":                                           "تم جلب الملف الشخصي بنجاح",
+		"Failed to retrieve user profile":                                          "تعذر جلب الملف الشخصي",
+		"Failed to retrieve users":                                                 "تعذر جلب المستخدمين",
+		"Invalid user ID":                                                          "معرف المستخدم غير صالح",
+
+		// Organizations
+		"Invalid organization ID":              "معرف المنظمة غير صالح",
+		"Organizations retrieved successfully": "تم جلب المنظمات بنجاح",
+		"Organization created successfully":    "تم إنشاء المنظمة بنجاح",
+		"Organization updated successfully":    "تم تحديث المنظمة بنجاح",
+		"Organization deleted successfully":    "تم حذف المنظمة بنجاح",
+		"Organizers retrieved successfully":    "تم جلب المنظمين بنجاح",
+		"Organizer assigned successfully":      "تم تعيين المنظم بنجاح",
+		"Organizer removed successfully":       "تمت إزالة المنظم بنجاح",
+		"Failed to retrieve organizations":     "تعذر جلب المنظمات",
+		"Failed to create organization":        "تعذر إنشاء المنظمة",
+		"Failed to update organization":        "تعذر تحديث المنظمة",
+		"Failed to delete organization":        "تعذر حذف المنظمة",
+		"Failed to retrieve organizers":        "تعذر جلب المنظمين",
+		"Failed to assign organizer":           "تعذر تعيين المنظم",
+		"Failed to remove organizer":           "تعذرت إزالة المنظم",
+
+		// Events
+		"Invalid event ID":                      "معرف الفعالية غير صالح",
+		"Invalid input data":                    "البيانات المدخلة غير صالحة",
+		"Missing required fields":               "توجد حقول مطلوبة مفقودة",
+		"Failed to parse form data":             "تعذرت قراءة بيانات النموذج",
+		"Event not found":                       "الفعالية غير موجودة",
+		"Events retrieved successfully":         "تم جلب الفعاليات بنجاح",
+		"Event retrieved successfully":          "تم جلب الفعالية بنجاح",
+		"Event created successfully":            "تم إنشاء الفعالية بنجاح",
+		"Event updated successfully":            "تم تحديث الفعالية بنجاح",
+		"Event deleted successfully":            "تم حذف الفعالية بنجاح",
+		"Event restored successfully":           "تمت استعادة الفعالية بنجاح",
+		"Event status updated successfully":     "تم تحديث حالة الفعالية بنجاح",
+		"Deleted events retrieved successfully": "تم جلب الفعاليات المحذوفة بنجاح",
+		"Recent events retrieved successfully":  "تم جلب أحدث الفعاليات بنجاح",
+		"Nearby events retrieved successfully":  "تم جلب الفعاليات القريبة بنجاح",
+		"No events found":                       "لم يتم العثور على فعاليات",
+		"No events found matching your search":  "لا توجد فعاليات مطابقة لبحثك",
+		"Search query is required":              "يلزم إدخال نص البحث",
+		"Search results":                        "نتائج البحث",
+		"Suggestions retrieved successfully":    "تم جلب الاقتراحات بنجاح",
+		"Failed to retrieve events":             "تعذر جلب الفعاليات",
+		"Failed to retrieve recent events":      "تعذر جلب أحدث الفعاليات",
+		"Failed to retrieve deleted events":     "تعذر جلب الفعاليات المحذوفة",
+		"Failed to retrieve suggestions":        "تعذر جلب الاقتراحات",
+		"Failed to search events":               "تعذر البحث في الفعاليات",
+		"Failed to search nearby events":        "تعذر البحث عن الفعاليات القريبة",
+		"Failed to create event":                "تعذر إنشاء الفعالية",
+		"Failed to update event":                "تعذر تحديث الفعالية",
+		"Failed to delete event":                "تعذر حذف الفعالية",
+		"Failed to restore event":               "تعذرت استعادة الفعالية",
+		"Failed to update event status":         "تعذر تحديث حالة الفعالية",
+		"Invalid from date":                     "تاريخ البداية غير صالح",
+		"Invalid to date":                       "تاريخ النهاية غير صالح",
+		"Invalid minimum price":                 "الحد الأدنى للسعر غير صالح",
+		"Invalid maximum price":                 "الحد الأقصى للسعر غير صالح",
+		"Invalid latitude":                      "خط العرض غير صالح",
+		"Invalid longitude":                     "خط الطول غير صالح",
+		"Invalid radius":                        "نصف القطر غير صالح",
+		"Invalid locale":                        "اللغة غير مدعومة",
+		"Translations retrieved successfully":   "تم جلب الترجمات بنجاح",
+		"Translation saved successfully":        "تم حفظ الترجمة بنجاح",
+		"Translation deleted successfully":      "تم حذف الترجمة بنجاح",
+		"Failed to retrieve translations":       "تعذر جلب الترجمات",
+		"Failed to save translation":            "تعذر حفظ الترجمة",
+		"Failed to delete translation":          "تعذر حذف الترجمة",
+
+		// Ticket types
+		"Invalid ticket type ID":              "معرف نوع التذكرة غير صالح",
+		"Ticket types retrieved successfully": "تم جلب أنواع التذاكر بنجاح",
+		"Ticket type created successfully":    "تم إنشاء نوع التذكرة بنجاح",
+		"Ticket type updated successfully":    "تم تحديث نوع التذكرة بنجاح",
+		"Ticket type deleted successfully":    "تم حذف نوع التذكرة بنجاح",
+		"Failed to create ticket type":        "تعذر إنشاء نوع التذكرة",
+		"Failed to update ticket type":        "تعذر تحديث نوع التذكرة",
+		"Failed to delete ticket type":        "تعذر حذف نوع التذكرة",
+
+		// Series
+		"Invalid series ID":                   "معرف السلسلة غير صالح",
+		"Event series not found":              "سلسلة الفعاليات غير موجودة",
+		"Event series retrieved successfully": "تم جلب سلسلة الفعاليات بنجاح",
+		"Event series created successfully":   "تم إنشاء سلسلة الفعاليات بنجاح",
+		"Event series updated successfully":   "تم تحديث سلسلة الفعاليات بنجاح",
+		"Event series deleted successfully":   "تم حذف سلسلة الفعاليات بنجاح",
+		"Failed to create event series":       "تعذر إنشاء سلسلة الفعاليات",
+		"Failed to update event series":       "تعذر تحديث سلسلة الفعاليات",
+		"Failed to delete event series":       "تعذر حذف سلسلة الفعاليات",
+
+		// Categories
+		"Invalid category ID":                       "معرف التصنيف غير صالح",
+		"Invalid target category ID":                "معرف التصنيف البديل غير صالح",
+		"Category not found":                        "التصنيف غير موجود",
+		"Categories retrieved successfully":         "تم جلب التصنيفات بنجاح",
+		"Category retrieved successfully":           "تم جلب التصنيف بنجاح",
+		"Category created successfully":             "تم إنشاء التصنيف بنجاح",
+		"Category updated successfully":             "تم تحديث التصنيف بنجاح",
+		"Category deleted successfully":             "تم حذف التصنيف بنجاح",
+		"Category restored successfully":            "تمت استعادة التصنيف بنجاح",
+		"Deleted categories retrieved successfully": "تم جلب التصنيفات المحذوفة بنجاح",
+		"Failed to retrieve categories":             "تعذر جلب التصنيفات",
+		"Failed to retrieve deleted categories":     "تعذر جلب التصنيفات المحذوفة",
+		"Failedd to create category":                "تعذر إنشاء التصنيف",
+		"Failed to update category":                 "تعذر تحديث التصنيف",
+		"Failed to delete category":                 "تعذر حذف التصنيف",
+		"Failed to restore category":                "تعذرت استعادة التصنيف",
+
+		// Tags
+		"Invalid tag ID":              "معرف الوسم غير صالح",
+		"Tags retrieved successfully": "تم جلب الوسوم بنجاح",
+		"Tag renamed successfully":    "تمت إعادة تسمية الوسم بنجاح",
+		"Tags merged successfully":    "تم دمج الوسوم بنجاح",
+		"Tag deleted successfully":    "تم حذف الوسم بنجاح",
+		"Failed to retrieve tags":     "تعذر جلب الوسوم",
+		"Failed to rename tag":        "تعذرت إعادة تسمية الوسم",
+		"Failed to merge tags":        "تعذر دمج الوسوم",
+		"Failed to delete tag":        "تعذر حذف الوسم",
+
+		// Venues
+		"Invalid venue ID":              "معرف المكان غير صالح",
+		"Venue not found":               "المكان غير موجود",
+		"Venues retrieved successfully": "تم جلب الأماكن بنجاح",
+		"Venue retrieved successfully":  "تم جلب المكان بنجاح",
+		"Venue created successfully":    "تم إنشاء المكان بنجاح",
+		"Venue updated successfully":    "تم تحديث المكان بنجاح",
+		"Venue deleted successfully":    "تم حذف المكان بنجاح",
+		"Failed to retrieve venues":     "تعذر جلب الأماكن",
+		"Failed to create venue":        "تعذر إنشاء المكان",
+		"Failed to update venue":        "تعذر تحديث المكان",
+		"Failed to delete venue":        "تعذر حذف المكان",
+
+		// Bookings
+		"Invalid booking ID":                                      "معرف الحجز غير صالح",
+		"Booking created successfully":                            "تم إنشاء الحجز بنجاح",
+		"Booking confirmed successfully":                          "تم تأكيد الحجز بنجاح",
+		"Booking restored successfully":                           "تمت استعادة الحجز بنجاح",
+		"Booking status updated sucessfully":                      "تم تحديث حالة الحجز بنجاح",
+		"Booking check completed":                                 "تم التحقق من الحجز",
+		"Booking history retrieved successfully":                  "تم جلب سجل الحجز بنجاح",
+		"Bookings retrieved successfully":                         "تم جلب الحجوزات بنجاح",
+		"Deleted bookings retrieved successfully":                 "تم جلب الحجوزات المحذوفة بنجاح",
+		"Attendees retrieved successfully":                        "تم جلب الحضور بنجاح",
+		"No bookings found":                                       "لم يتم العثور على حجوزات",
+		"Seats held, confirm the booking before the hold expires": "تم حجز المقاعد مؤقتًا، أكّد الحجز قبل انتهاء المهلة",
+		"Seats held until %s, confirm the booking before then":    "تم حجز المقاعد مؤقتًا حتى %s، أكّد الحجز قبل ذلك",
+		"Event is sold out, you have been added to the waitlist":  "نفدت تذاكر الفعالية، وتمت إضافتك إلى قائمة الانتظار",
+		"Failed to create booking":                                "تعذر إنشاء الحجز",
+		"Failed to confirm booking":                               "تعذر تأكيد الحجز",
+		"Failed to check booking":                                 "تعذر التحقق من الحجز",
+		"Failed to get bookings":                                  "تعذر جلب الحجوزات",
+		"Failed to get deleted bookings":                          "تعذر جلب الحجوزات المحذوفة",
+		"Failed to retrieve bookings":                             "تعذر جلب الحجوزات",
+		"Failed to retrieve booking history":                      "تعذر جلب سجل الحجز",
+		"Failed to retrieve attendees":                            "تعذر جلب الحضور",
+		"Failed to update booking status":                         "تعذر تحديث حالة الحجز",
+		"Failed to restore booking":                               "تعذرت استعادة الحجز",
+
+		// Waitlist
+		"Invalid waitlist entry ID":       "معرف طلب الانتظار غير صالح",
+		"Joined waitlist successfully":    "تمت إضافتك إلى قائمة الانتظار بنجاح",
+		"Left waitlist successfully":      "تمت إزالتك من قائمة الانتظار بنجاح",
+		"Waitlist retrieved successfully": "تم جلب قائمة الانتظار بنجاح",
+		"Failed to join waitlist":         "تعذرت الإضافة إلى قائمة الانتظار",
+		"Failed to leave waitlist":        "تعذرت الإزالة من قائمة الانتظار",
+		"Failed to retrieve waitlist":     "تعذر جلب قائمة الانتظار",
+		"Failed to claim waitlist offer":  "تعذرت المطالبة بعرض قائمة الانتظار",
+
+		// General
+		"Internal server error":                 "خطأ داخلي في الخادم",
+		"Rate limit exceeded. Try again later.": "تم تجاوز حد الطلبات، حاول مرة أخرى لاحقًا",
+
+		// Error details returned by the services
+		"invalid email or password":                                                   "البريد الإلكتروني أو كلمة المرور غير صحيحة",
+		"user with this email already exists":                                         "يوجد مستخدم بهذا البريد الإلكتروني بالفعل",
+		"invalid email format":                                                        "صيغة البريد الإلكتروني غير صالحة",
+		"password must be at least 8 characters long":                                 "يجب ألا تقل كلمة المرور عن 8 أحرف",
+		"password must contain both letters and numbers":                              "يجب أن تحتوي كلمة المرور على أحرف وأرقام",
+		"user not found":                                                              "المستخدم غير موجود",
+		"event not found":                                                             "الفعالية غير موجودة",
+		"category not found":                                                          "التصنيف غير موجود",
+		"booking not found":                                                           "الحجز غير موجود",
+		"venue not found":                                                             "المكان غير موجود",
+		"series not found":                                                            "السلسلة غير موجودة",
+		"tag not found":                                                               "الوسم غير موجود",
+		"ticket type not found":                                                       "نوع التذكرة غير موجود",
+		"waitlist entry not found":                                                    "طلب الانتظار غير موجود",
+		"event is sold out":                                                           "نفدت تذاكر الفعالية",
+		"event is not open for booking":                                               "الفعالية غير متاحة للحجز",
+		"this ticket type is sold out":                                                "نفدت تذاكر هذا النوع",
+		"this ticket type is not on sale":                                             "هذا النوع من التذاكر غير معروض للبيع",
+		"you have already booked this event":                                          "لقد حجزت هذه الفعالية بالفعل",
+		"you have reached the ticket limit for this event":                            "لقد بلغت الحد الأقصى للتذاكر في هذه الفعالية",
+		"your seat hold has expired":                                                  "انتهت مهلة حجز المقاعد",
+		"your waitlist offer has expired":                                             "انتهت صلاحية عرض قائمة الانتظار",
+		"you are already on the waitlist for this event":                              "أنت مسجل بالفعل في قائمة انتظار هذه الفعالية",
+		"quantity must be at least 1":                                                 "يجب أن تكون الكمية 1 على الأقل",
+		"every attendee needs a name":                                                 "يجب إدخال اسم لكل حاضر",
+		"you do not have permission to manage this resource":                          "ليست لديك صلاحية لإدارة هذا المورد",
+		"invalid or expired refresh token":                                            "رمز التجديد غير صالح أو منتهي الصلاحية",
+		"refresh token was already used, please log in again":                         "سبق استخدام رمز التجديد، يرجى تسجيل الدخول مرة أخرى",
+		"invalid cursor":                                                              "مؤشر الصفحات غير صالح",
+		"translation not found":                                                       "الترجمة غير موجودة",
+		"a category with this name already exists":                                    "يوجد تصنيف بهذا الاسم بالفعل",
+		"unsupported locale":                                                          "اللغة غير مدعومة",
+		"invalid or expired verification link":                                        "رابط التأكيد غير صالح أو منتهي الصلاحية",
+		"email is already verified":                                                   "تم تأكيد البريد الإلكتروني مسبقاً",
+		"a verification email was sent recently, please try again later":              "أُرسلت رسالة تأكيد مؤخراً، يرجى المحاولة لاحقاً",
+		"invalid or expired password reset token":                                     "رمز إعادة تعيين كلمة المرور غير صالح أو منتهي الصلاحية",
+		"current password is incorrect":                                               "كلمة المرور الحالية غير صحيحة",
+		"new password must be different from the current one":                         "يجب أن تختلف كلمة المرور الجديدة عن الحالية",
+		"too many failed login attempts, please try again later":                      "محاولات تسجيل دخول فاشلة كثيرة، يرجى المحاولة لاحقاً",
+		"admin accounts cannot be deleted":                                            "لا يمكن حذف حسابات المشرفين",
+		"invalid phone number":                                                        "رقم الهاتف غير صالح",
+		"name must be between 1 and 100 characters":                                   "يجب أن يتراوح طول الاسم بين 1 و100 حرف",
+		"please verify your email address before booking":                             "يرجى تأكيد بريدك الإلكتروني قبل الحجز",
+		"a category cannot be its own parent":                                         "لا يمكن أن يكون التصنيف أصلاً لنفسه",
+		"a category cannot be moved under one of its own subcategories":               "لا يمكن نقل التصنيف تحت أحد تصنيفاته الفرعية",
+		"a publish time is required to schedule an event":                             "يلزم تحديد وقت النشر لجدولة الفعالية",
+		"a tag with this name already exists":                                         "يوجد وسم بهذا الاسم بالفعل",
+		"a venue or venue_id is required":                                             "يلزم تحديد المكان أو معرّف المكان",
+		"admins cannot be assigned to an organization":                                "لا يمكن تعيين المشرفين في مؤسسة",
+		"booking not found in trash":                                                  "الحجز غير موجود في سلة المحذوفات",
+		"booking status was changed by another request":                               "تم تغيير حالة الحجز بواسطة طلب آخر",
+		"cannot delete a ticket type that has bookings":                               "لا يمكن حذف نوع تذكرة له حجوزات",
+		"cannot merge a tag into itself":                                              "لا يمكن دمج الوسم في نفسه",
+		"cannot move events to the category being deleted":                            "لا يمكن نقل الفعاليات إلى التصنيف الجاري حذفه",
+		"capacity and ticket limit cannot be negative":                                "لا يمكن أن تكون السعة أو حد التذاكر سالبة",
+		"capacity cannot be negative":                                                 "لا يمكن أن تكون السعة سالبة",
+		"category belongs to another organization":                                    "التصنيف تابع لمؤسسة أخرى",
+		"category not found in trash":                                                 "التصنيف غير موجود في سلة المحذوفات",
+		"category still has events":                                                   "لا يزال التصنيف يحتوي على فعاليات",
+		"choose either a category to move the events to or a forced delete, not both": "اختر إما تصنيفاً تُنقل إليه الفعاليات أو الحذف القسري، وليس كليهما",
+		"event is not part of a series":                                               "الفعالية ليست جزءاً من سلسلة",
+		"event must end after it starts":                                              "يجب أن تنتهي الفعالية بعد بدايتها",
+		"event not found in trash":                                                    "الفعالية غير موجودة في سلة المحذوفات",
+		"event still has seats available":                                             "لا تزال هناك مقاعد متاحة في الفعالية",
+		"invalid booking status transition":                                           "لا يمكن نقل الحجز إلى هذه الحالة",
+		"invalid date format":                                                         "صيغة التاريخ غير صالحة",
+		"invalid end date format":                                                     "صيغة تاريخ الانتهاء غير صالحة",
+		"invalid event status transition":                                             "لا يمكن نقل الفعالية إلى هذه الحالة",
+		"invalid publish time format":                                                 "صيغة وقت النشر غير صالحة",
+		"invalid sale end date format":                                                "صيغة تاريخ انتهاء البيع غير صالحة",
+		"invalid sale start date format":                                              "صيغة تاريخ بدء البيع غير صالحة",
+		"invalid start date format":                                                   "صيغة تاريخ البداية غير صالحة",
+		"invalid status":                                                              "الحالة غير صالحة",
+		"latitude must be within ±90 and longitude within ±180":                       "يجب أن يكون خط العرض بين ‎±90 وخط الطول بين ‎±180",
+		"no seat has been offered for this waitlist entry":                            "لم يُعرض أي مقعد لطلب الانتظار هذا",
+		"only pending bookings can be confirmed":                                      "لا يمكن تأكيد إلا الحجوزات المعلقة",
+		"organization not found":                                                      "المؤسسة غير موجودة",
+		"organization still owns events or categories":                                "لا تزال المؤسسة تملك فعاليات أو تصنيفات",
+		"parent category belongs to another organization":                             "التصنيف الأصل تابع لمؤسسة أخرى",
+		"parent category not found":                                                   "التصنيف الأصل غير موجود",
+		"price and quota cannot be negative":                                          "لا يمكن أن يكون السعر أو الحصة سالبة",
+		"price cannot be negative":                                                    "لا يمكن أن يكون السعر سالباً",
+		"prices must not be negative":                                                 "يجب ألا تكون الأسعار سالبة",
+		"publish time must be in the future":                                          "يجب أن يكون وقت النشر في المستقبل",
+		"sale end must be after sale start":                                           "يجب أن ينتهي البيع بعد بدايته",
+		"slug must contain letters or digits":                                         "يجب أن يحتوي المعرّف النصي على أحرف أو أرقام",
+		"source tags not found":                                                       "الوسوم المصدر غير موجودة",
+		"tag name is required":                                                        "اسم الوسم مطلوب",
+		"target tag not found":                                                        "الوسم الهدف غير موجود",
+		"the booking's event is in the trash, restore the event instead":              "فعالية هذا الحجز في سلة المحذوفات، استعد الفعالية بدلاً من ذلك",
+		"the end of the date range must not be before its start":                      "يجب ألا تسبق نهاية نطاق التاريخ بدايته",
+		"the event's category is in the trash, restore the category first":            "تصنيف الفعالية في سلة المحذوفات، استعد التصنيف أولاً",
+		"the maximum price must not be below the minimum price":                       "يجب ألا يقل السعر الأعلى عن السعر الأدنى",
+		"ticket limit cannot be negative":                                             "لا يمكن أن يكون حد التذاكر سالباً",
+		"ticket type is required for this event":                                      "يلزم اختيار نوع التذكرة لهذه الفعالية",
+		"ticket type name is required":                                                "اسم نوع التذكرة مطلوب",
+		"ticket type not found for this event":                                        "نوع التذكرة غير موجود لهذه الفعالية",
+		"unauthorized to claim this waitlist entry":                                   "غير مصرح لك بالمطالبة بطلب الانتظار هذا",
+		"unauthorized to update this booking":                                         "غير مصرح لك بتعديل هذا الحجز",
+		"unauthorized to update this waitlist entry":                                  "غير مصرح لك بتعديل طلب الانتظار هذا",
+		"unauthorized to view this booking":                                           "غير مصرح لك بعرض هذا الحجز",
+		"user is not an organizer of this organization":                               "المستخدم ليس منظماً في هذه المؤسسة",
+		"waitlist entry is no longer active":                                          "طلب الانتظار لم يعد نشطاً",
+
+		// Error details with values filled in, which go in after translation
+		"%w, merge tag %d into tag %d instead": "%w، ادمج الوسم %d في الوسم %d بدلاً من ذلك",
+		"%w: %d events, %d recurring series and %d subcategories use it, move them to another category or force the deletion": "%w: تستخدمه %d فعالية و%d سلسلة متكررة و%d تصنيف فرعي، انقلها إلى تصنيف آخر أو اختر الحذف القسري",
+		"%w: %s to %s": "%w: من %s إلى %s",
+		"a booking cannot include more than %d seats":                                     "لا يمكن أن يتضمن الحجز أكثر من %d مقاعد",
+		"authentication error: %w":                                                        "خطأ في المصادقة: %w",
+		"capacity cannot be lower than the %d seats already booked":                       "لا يمكن أن تقل السعة عن %d مقعداً محجوزاً بالفعل",
+		"capacity cannot be lower than the %d seats already booked for the %s occurrence": "لا يمكن أن تقل السعة عن %d مقعداً محجوزاً بالفعل في موعد %s",
+		"event not found: %w":                                                             "الفعالية غير موجودة: %w",
+		"expected %d attendees, got %d":                                                   "المتوقع %d من الحضور، وتم إرسال %d",
+		"failed to assign organizer: %w":                                                  "تعذر تعيين المنظم: %w",
+		"failed to associate tags with event: %w":                                         "تعذر ربط الوسوم بالفعالية: %w",
+		"failed to cancel bookings: %w":                                                   "تعذر إلغاء الحجوزات: %w",
+		"failed to close the waitlist: %w":                                                "تعذر إغلاق قائمة الانتظار: %w",
+		"failed to count password reset request: %w":                                      "تعذر احتساب طلب إعادة تعيين كلمة المرور: %w",
+		"failed to create organization: %w":                                               "تعذر إنشاء المؤسسة: %w",
+		"failed to create user: %w":                                                       "تعذر إنشاء المستخدم: %w",
+		"failed to delete associated bookings: %w":                                        "تعذر حذف الحجوزات المرتبطة: %w",
+		"failed to delete event %d: %w":                                                   "تعذر حذف الفعالية %d: %w",
+		"failed to delete series %d: %w":                                                  "تعذر حذف السلسلة %d: %w",
+		"failed to delete tag: %w":                                                        "تعذر حذف الوسم: %w",
+		"failed to demote organizer %d: %w":                                               "تعذر إلغاء صلاحيات المنظم %d: %w",
+		"failed to detach events of series %d: %w":                                        "تعذر فصل فعاليات السلسلة %d: %w",
+		"failed to exclude occurrence from its series: %w":                                "تعذر استبعاد الموعد من سلسلته: %w",
+		"failed to fetch events for category: %w":                                         "تعذر جلب فعاليات التصنيف: %w",
+		"failed to generate token: %w":                                                    "تعذر إنشاء رمز الدخول: %w",
+		"failed to hash password: %w":                                                     "تعذرت معالجة كلمة المرور: %w",
+		"failed to merge tags: %w":                                                        "تعذر دمج الوسوم: %w",
+		"failed to move events: %w":                                                       "تعذر نقل الفعاليات: %w",
+		"failed to move subcategories: %w":                                                "تعذر نقل التصنيفات الفرعية: %w",
+		"failed to purge bookings: %w":                                                    "تعذر الحذف النهائي للحجوزات: %w",
+		"failed to purge categories: %w":                                                  "تعذر الحذف النهائي للتصنيفات: %w",
+		"failed to purge event %d: %w":                                                    "تعذر الحذف النهائي للفعالية %d: %w",
+		"failed to rename venue on its events: %w":                                        "تعذر تحديث اسم المكان في فعالياته: %w",
+		"failed to restore bookings: %w":                                                  "تعذرت استعادة الحجوزات: %w",
+		"failed to restore event %d: %w":                                                  "تعذرت استعادة الفعالية %d: %w",
+		"failed to retrieve user profile: %w":                                             "تعذر جلب الملف الشخصي: %w",
+		"failed to retrieve users: %w":                                                    "تعذر جلب المستخدمين: %w",
+		"failed to send password reset email: %w":                                         "تعذر إرسال رسالة إعادة تعيين كلمة المرور: %w",
+		"failed to store password reset token: %w":                                        "تعذر حفظ رمز إعادة تعيين كلمة المرور: %w",
+		"failed to store refresh token: %w":                                               "تعذر حفظ رمز التجديد: %w",
+		"failed to update organization: %w":                                               "تعذر تحديث المؤسسة: %w",
+		"failed to update password: %w":                                                   "تعذر تحديث كلمة المرور: %w",
+		"failed to update profile: %w":                                                    "تعذر تحديث الملف الشخصي: %w",
+		"failed to update series %d: %w":                                                  "تعذر تحديث السلسلة %d: %w",
+		"failed to verify email: %w":                                                      "تعذر تأكيد البريد الإلكتروني: %w",
+		"invalid date %q, expected RFC 3339 such as 2025-06-01T19:30:00+03:00":            "التاريخ %q غير صالح، المتوقع صيغة RFC 3339 مثل 2025-06-01T19:30:00+03:00",
+		"invalid delete scope %q":                                                         "نطاق الحذف %q غير صالح",
+		"invalid edit scope %q":                                                           "نطاق التعديل %q غير صالح",
+		"invalid exception date %q":                                                       "تاريخ الاستثناء %q غير صالح",
+		"new events cannot start out as %s":                                               "لا يمكن إنشاء فعالية جديدة بالحالة %s",
+		"only the event's organizer can mark a booking as %s":                             "لا يمكن إلا لمنظم الفعالية تعيين حالة الحجز إلى %s",
+		"quota cannot be lower than the %d tickets already sold":                          "لا يمكن أن تقل الحصة عن %d تذكرة مبيعة بالفعل",
+		"radius must be between 0 and %d km":                                              "يجب أن يكون نصف القطر بين 0 و%d كم",
+		"slug %q is already in use":                                                       "المعرّف النصي %q مستخدم بالفعل",
+		"unknown event status %q":                                                         "حالة الفعالية %q غير معروفة",
+		"unknown sort order %q, expected date, price, price_desc, newest, popularity or relevance": "ترتيب الفرز %q غير معروف، القيم المتاحة date وprice وprice_desc وnewest وpopularity وrelevance",
+		"unknown time zone %q": "المنطقة الزمنية %q غير معروفة",
+		"venue is used by %d events, move them to another venue first": "المكان مستخدم في %d فعالية، انقلها إلى مكان آخر أولاً",
+	},
+}
@@ -1,6 +1,10 @@
 package utils
 
-import "github.com/gin-gonic/gin"
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
 
 type Response struct {
 	Success bool   `json:"success"`
@@ -9,7 +13,25 @@ type Response struct {
 	Error   any    `json:"error,omitempty"`
 }
 
+// SuccessResponse answers in the locale of the request, translating the
+// message and any content the data carries translations for
 func SuccessResponse(c *gin.Context, statusCode int, message string, data any) {
+	locale := Locale(c)
+	successResponse(c, statusCode, locale, Translate(locale, message), data)
+}
+
+// SuccessResponsef is SuccessResponse for a message with values in it. The
+// format is translated and the values are filled in afterwards.
+func SuccessResponsef(c *gin.Context, statusCode int, data any, format string, args ...any) {
+	locale := Locale(c)
+	successResponse(c, statusCode, locale, fmt.Sprintf(Translate(locale, format), args...), data)
+}
+
+func successResponse(c *gin.Context, statusCode int, locale, message string, data any) {
+	if content, ok := data.(Localizable); ok {
+		data = content.Localize(locale)
+	}
+
 	c.JSON(statusCode, Response{
 		Success: true,
 		Message: message,
@@ -17,10 +39,20 @@ func SuccessResponse(c *gin.Context, statusCode int, message string, data any) {
 	})
 }
 
+// ErrorResponse answers in the locale of the request. Error details given
+// as errors or text are translated too when the catalog knows them.
 func ErrorResponse(c *gin.Context, statusCode int, message string, err any) {
+	locale := Locale(c)
+	switch detail := err.(type) {
+	case error:
+		err = LocalizeError(locale, detail)
+	case string:
+		err = Translate(locale, detail)
+	}
+
 	c.JSON(statusCode, Response{
 		Success: false,
-		Message: message,
+		Message: Translate(locale, message),
 		Error:   err,
 	})
 }
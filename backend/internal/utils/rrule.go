@@ -0,0 +1,384 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RRule is the subset of an RFC 5545 recurrence rule supported for event
+// series: DAILY, WEEKLY, MONTHLY and YEARLY frequencies with INTERVAL, COUNT,
+// UNTIL, BYDAY, BYMONTHDAY and BYMONTH. Weeks start on Monday.
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []RRuleWeekday
+	ByMonthDay []int
+	ByMonth    []int
+}
+
+// RRuleWeekday is a BYDAY entry such as "MO" or, for monthly and yearly
+// rules, "2TU" (second Tuesday) and "-1FR" (last Friday)
+type RRuleWeekday struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// maxRRuleIterations bounds how many periods are walked while expanding a rule
+const maxRRuleIterations = 10000
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// ParseRRule parses a rule such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10". An
+// optional "RRULE:" prefix is accepted.
+func ParseRRule(value string) (*RRule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, errors.New("recurrence rule is empty")
+	}
+
+	rule := &RRule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(val)
+			if err == nil && rule.Interval < 1 {
+				err = errors.New("must be positive")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(val)
+			if err == nil && rule.Count < 1 {
+				err = errors.New("must be positive")
+			}
+		case "UNTIL":
+			var until time.Time
+			until, err = parseRRuleTime(val)
+			rule.Until = &until
+		case "BYDAY":
+			rule.ByDay, err = parseRRuleWeekdays(val)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseRRuleInts(val, -31, 31)
+		case "BYMONTH":
+			rule.ByMonth, err = parseRRuleInts(val, 1, 12)
+		case "WKST":
+			if strings.ToUpper(val) != "MO" {
+				err = errors.New("only MO is supported")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in recurrence rule: %w", strings.ToUpper(key), err)
+		}
+	}
+
+	switch rule.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	case "":
+		return nil, errors.New("recurrence rule requires FREQ")
+	default:
+		return nil, fmt.Errorf("unsupported recurrence frequency %q", rule.Freq)
+	}
+
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, errors.New("recurrence rule cannot have both COUNT and UNTIL")
+	}
+
+	for _, d := range rule.ByDay {
+		if d.N != 0 && rule.Freq != "MONTHLY" && rule.Freq != "YEARLY" {
+			return nil, errors.New("numbered BYDAY values need a MONTHLY or YEARLY frequency")
+		}
+	}
+
+	return rule, nil
+}
+
+// String formats the rule back into its RFC 5545 text form
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, d := range r.ByDay {
+			code := strings.ToUpper(d.Weekday.String()[:2])
+			if d.N != 0 {
+				code = strconv.Itoa(d.N) + code
+			}
+			days = append(days, code)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrences expands the rule from dtstart and returns the occurrences from
+// the given time up to the horizon, skipping any listed in exdates. At most
+// limit occurrences are returned; earlier ones do not count towards it, so
+// long-running series keep producing events. COUNT is still counted from
+// dtstart, and before exdates are removed, as RFC 5545 requires.
+func (r *RRule) Occurrences(dtstart time.Time, exdates []time.Time, from, horizon time.Time, limit int) []time.Time {
+	excluded := make(map[int64]bool, len(exdates))
+	for _, ex := range exdates {
+		excluded[ex.Unix()] = true
+	}
+
+	var result []time.Time
+	generated := 0
+
+	for i := 0; i < maxRRuleIterations; i++ {
+		for _, occ := range r.period(dtstart, i) {
+			if occ.Before(dtstart) {
+				continue
+			}
+			if r.Until != nil && occ.After(*r.Until) {
+				return result
+			}
+			if r.Count > 0 && generated >= r.Count {
+				return result
+			}
+			if !occ.Before(horizon) || len(result) >= limit {
+				return result
+			}
+
+			generated++
+			if !occ.Before(from) && !excluded[occ.Unix()] {
+				result = append(result, occ)
+			}
+		}
+	}
+
+	return result
+}
+
+// CountBefore returns how many occurrences the rule generates before the
+// given time, including excluded ones. It is used to split COUNT rules.
+func (r *RRule) CountBefore(dtstart, before time.Time) int {
+	return len(r.Occurrences(dtstart, nil, dtstart, before, maxRRuleIterations))
+}
+
+// period returns the sorted candidate occurrences of the i-th period
+func (r *RRule) period(dtstart time.Time, i int) []time.Time {
+	loc := dtstart.Location()
+	hour, minute, sec := dtstart.Clock()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hour, minute, sec, 0, loc)
+	}
+
+	var candidates []time.Time
+	switch r.Freq {
+	case "DAILY":
+		day := dtstart.AddDate(0, 0, i*r.Interval)
+		if r.matchesWeekday(day) && r.matchesMonthDay(day) && r.matchesMonth(day) {
+			candidates = append(candidates, day)
+		}
+
+	case "WEEKLY":
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		monday := dtstart.AddDate(0, 0, -offset+i*r.Interval*7)
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []RRuleWeekday{{Weekday: dtstart.Weekday()}}
+		}
+		for _, d := range days {
+			day := monday.AddDate(0, 0, (int(d.Weekday)+6)%7)
+			if r.matchesMonth(day) {
+				candidates = append(candidates, day)
+			}
+		}
+
+	case "MONTHLY":
+		first := time.Date(dtstart.Year(), dtstart.Month(), 1, 0, 0, 0, 0, loc).AddDate(0, i*r.Interval, 0)
+		if r.matchesMonth(first) {
+			candidates = r.monthDays(first.Year(), first.Month(), dtstart.Day(), at)
+		}
+
+	case "YEARLY":
+		year := dtstart.Year() + i*r.Interval
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []int{int(dtstart.Month())}
+		}
+		for _, m := range months {
+			candidates = append(candidates, r.monthDays(year, time.Month(m), dtstart.Day(), at)...)
+		}
+	}
+
+	sort.Slice(candidates, func(a, b int) bool { return candidates[a].Before(candidates[b]) })
+	return candidates
+}
+
+// monthDays expands BYMONTHDAY and BYDAY within one month, defaulting to the
+// start date's day of month. Days that do not exist in the month are skipped.
+func (r *RRule) monthDays(year int, month time.Month, defaultDay int, at func(int, time.Month, int) time.Time) []time.Time {
+	daysInMonth := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	seen := make(map[int]bool)
+
+	add := func(day int) {
+		if day >= 1 && day <= daysInMonth {
+			seen[day] = true
+		}
+	}
+
+	for _, d := range r.ByMonthDay {
+		if d < 0 {
+			d = daysInMonth + d + 1
+		}
+		add(d)
+	}
+
+	for _, wd := range r.ByDay {
+		var matches []int
+		for day := 1; day <= daysInMonth; day++ {
+			if time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() == wd.Weekday {
+				matches = append(matches, day)
+			}
+		}
+		switch {
+		case wd.N == 0:
+			for _, day := range matches {
+				add(day)
+			}
+		case wd.N > 0 && wd.N <= len(matches):
+			add(matches[wd.N-1])
+		case wd.N < 0 && -wd.N <= len(matches):
+			add(matches[len(matches)+wd.N])
+		}
+	}
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		add(defaultDay)
+	}
+
+	days := make([]time.Time, 0, len(seen))
+	for day := range seen {
+		days = append(days, at(year, month, day))
+	}
+	return days
+}
+
+func (r *RRule) matchesWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, d := range r.ByDay {
+		if d.Weekday == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RRule) matchesMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	daysInMonth := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, d := range r.ByMonthDay {
+		if d == t.Day() || (d < 0 && daysInMonth+d+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RRule) matchesMonth(t time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if time.Month(m) == t.Month() {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRRuleTime(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", value)
+}
+
+func parseRRuleWeekdays(value string) ([]RRuleWeekday, error) {
+	var days []RRuleWeekday
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToUpper(strings.TrimSpace(item))
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+
+		weekday, ok := rruleWeekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid weekday %q", item)
+			}
+		}
+		days = append(days, RRuleWeekday{Weekday: weekday, N: n})
+	}
+	return days, nil
+}
+
+func parseRRuleInts(value string, min, max int) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || n == 0 || n < min || n > max {
+			return nil, fmt.Errorf("invalid value %q", item)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestRRuleOccurrences(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+
+	at := func(loc *time.Location, value string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+		if err != nil {
+			t.Fatalf("invalid test time %q: %v", value, err)
+		}
+		return parsed
+	}
+	utc := func(value string) time.Time { return at(time.UTC, value) }
+
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		exdates []time.Time
+		from    time.Time
+		limit   int
+		want    []time.Time
+	}{
+		{
+			name:    "weekly by day",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5",
+			dtstart: utc("2025-01-06 10:00"),
+			want: []time.Time{
+				utc("2025-01-06 10:00"), utc("2025-01-08 10:00"), utc("2025-01-13 10:00"),
+				utc("2025-01-15 10:00"), utc("2025-01-20 10:00"),
+			},
+		},
+		{
+			name:    "last friday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR;COUNT=4",
+			dtstart: utc("2025-01-31 18:00"),
+			want: []time.Time{
+				utc("2025-01-31 18:00"), utc("2025-02-28 18:00"), utc("2025-03-28 18:00"),
+				utc("2025-04-25 18:00"),
+			},
+		},
+		{
+			name:    "missing month days are skipped",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=30;COUNT=3",
+			dtstart: utc("2025-01-30 12:00"),
+			want:    []time.Time{utc("2025-01-30 12:00"), utc("2025-03-30 12:00"), utc("2025-04-30 12:00")},
+		},
+		{
+			name:    "count includes excluded dates",
+			rule:    "FREQ=DAILY;COUNT=4",
+			dtstart: utc("2025-03-01 09:00"),
+			exdates: []time.Time{utc("2025-03-02 09:00")},
+			want:    []time.Time{utc("2025-03-01 09:00"), utc("2025-03-03 09:00"), utc("2025-03-04 09:00")},
+		},
+		{
+			name:    "until is inclusive",
+			rule:    "FREQ=WEEKLY;UNTIL=20250120T100000Z",
+			dtstart: utc("2025-01-06 10:00"),
+			want:    []time.Time{utc("2025-01-06 10:00"), utc("2025-01-13 10:00"), utc("2025-01-20 10:00")},
+		},
+		{
+			name:    "local time is kept across a DST change",
+			rule:    "FREQ=WEEKLY;COUNT=3",
+			dtstart: at(newYork, "2025-03-02 09:00"),
+			want: []time.Time{
+				at(newYork, "2025-03-02 09:00"), at(newYork, "2025-03-09 09:00"), at(newYork, "2025-03-16 09:00"),
+			},
+		},
+		{
+			name:    "limit counts from the given time",
+			rule:    "FREQ=DAILY",
+			dtstart: utc("2025-01-01 08:00"),
+			from:    utc("2025-01-10 00:00"),
+			limit:   2,
+			want:    []time.Time{utc("2025-01-10 08:00"), utc("2025-01-11 08:00")},
+		},
+		{
+			name:    "count is counted from the start",
+			rule:    "FREQ=DAILY;COUNT=5",
+			dtstart: utc("2025-01-01 08:00"),
+			from:    utc("2025-01-04 00:00"),
+			want:    []time.Time{utc("2025-01-04 08:00"), utc("2025-01-05 08:00")},
+		},
+	}
+
+	horizon := utc("2030-01-01 00:00")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) failed: %v", tt.rule, err)
+			}
+
+			from := tt.from
+			if from.IsZero() {
+				from = tt.dtstart
+			}
+			limit := tt.limit
+			if limit == 0 {
+				limit = 100
+			}
+
+			got := rule.Occurrences(tt.dtstart, tt.exdates, from, horizon, limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %d %v", len(got), got, len(tt.want), tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) || got[i].Location() != tt.want[i].Location() {
+					t.Errorf("occurrence %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRRuleCountBefore(t *testing.T) {
+	rule, err := ParseRRule("FREQ=WEEKLY;BYDAY=TU,TH;COUNT=10")
+	if err != nil {
+		t.Fatalf("ParseRRule failed: %v", err)
+	}
+
+	dtstart := time.Date(2025, time.January, 7, 19, 0, 0, 0, time.UTC)
+	before := time.Date(2025, time.January, 21, 19, 0, 0, 0, time.UTC)
+	if got := rule.CountBefore(dtstart, before); got != 4 {
+		t.Errorf("CountBefore = %d, want 4", got)
+	}
+}
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		wantErr bool
+		want    string
+	}{
+		{rule: "RRULE:FREQ=weekly;BYDAY=mo,we;COUNT=10", want: "FREQ=WEEKLY;COUNT=10;BYDAY=MO,WE"},
+		{rule: "FREQ=MONTHLY;INTERVAL=2;BYDAY=-1FR", want: "FREQ=MONTHLY;INTERVAL=2;BYDAY=-1FR"},
+		{rule: "FREQ=DAILY;UNTIL=20250301", want: "FREQ=DAILY;UNTIL=20250301T000000Z"},
+		{rule: "", wantErr: true},
+		{rule: "COUNT=3", wantErr: true},
+		{rule: "FREQ=HOURLY", wantErr: true},
+		{rule: "FREQ=DAILY;COUNT=3;UNTIL=20250301", wantErr: true},
+		{rule: "FREQ=WEEKLY;BYDAY=2MO", wantErr: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{rule: "FREQ=DAILY;BYSETPOS=1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		rule, err := ParseRRule(tt.rule)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseRRule(%q) succeeded, want an error", tt.rule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRRule(%q) failed: %v", tt.rule, err)
+			continue
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("ParseRRule(%q).String() = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Slugify turns a name into a lowercase, hyphen separated URL segment.
+// Letters outside ASCII are kept so Arabic names still give a readable slug.
+func Slugify(name string) string {
+	var b strings.Builder
+	pendingDash := false
+
+	for _, r := range strings.ToLower(name) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pendingDash = b.Len() > 0
+			continue
+		}
+		if pendingDash {
+			b.WriteByte('-')
+			pendingDash = false
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// UniqueSlug returns base, or base with the first numeric suffix that is not
+// taken yet. An empty base falls back to fallback.
+func UniqueSlug(base, fallback string, taken func(slug string) (bool, error)) (string, error) {
+	if base == "" {
+		base = fallback
+	}
+
+	slug := base
+	for n := 2; ; n++ {
+		exists, err := taken(slug)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return slug, nil
+		}
+		slug = base + "-" + strconv.Itoa(n)
+	}
+}
@@ -1,6 +1,11 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
@@ -15,23 +20,35 @@ type JWTClaim struct {
 	UserID uint        `json:"user_id"`
 	Email  string      `json:"email"`
 	Role   models.Role `json:"role"`
+	// OrganizationID is set for organizers and scopes what they may manage
+	OrganizationID *uint `json:"organization_id,omitempty"`
+	// SessionID is the refresh token family the token was issued with
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(user models.User, cfg *config.Config) (string, time.Time, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// GenerateJWT issues a short-lived access token. The tokenID becomes its
+// jti, by which it can be revoked before it expires.
+func GenerateJWT(user models.User, tokenID, sessionID string, cfg *config.Config) (string, time.Time, error) {
+	ttl := cfg.AccessTokenTTL
+	if ttl <= 0 {
+		ttl = 15 * time.Minute
+	}
+	expirationTime := time.Now().Add(ttl)
 
 	claims := JWTClaim{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:         user.ID,
+		Email:          user.Email,
+		Role:           user.Role,
+		OrganizationID: user.OrganizationID,
+		SessionID:      sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "mawid-api",
 			Subject:   fmt.Sprintf("%d", user.ID),
-			ID:        fmt.Sprintf("%d-%d", user.ID, time.Now().Unix()),
+			ID:        tokenID,
 		},
 	}
 
@@ -79,3 +96,95 @@ func ValidateToken(tokenString string, cfg *config.Config) (*JWTClaim, error) {
 
 	return claims, nil
 }
+
+// NewTokenID returns a random identifier for a token or token family
+func NewTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// NewOpaqueToken returns a random secret for a client to present later,
+// such as a refresh token. Only its HashToken should be stored.
+func NewOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashToken is how opaque tokens are stored. They carry enough randomness
+// that a plain SHA-256 is as good as a slow password hash.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// EmailVerificationClaim is carried by the links that confirm a user owns
+// their email address
+type EmailVerificationClaim struct {
+	UserID uint   `json:"user_id"`
+	Email  string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// GenerateEmailVerificationToken signs a token proving the link was sent to
+// email. It is signed with a key of its own so it can never pass as an
+// access token.
+func GenerateEmailVerificationToken(userID uint, email string, cfg *config.Config) (string, error) {
+	ttl := cfg.EmailVerificationTTL
+	if ttl <= 0 {
+		ttl = 48 * time.Hour
+	}
+
+	claims := EmailVerificationClaim{
+		UserID: userID,
+		Email:  email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "mawid-api",
+			Subject:   fmt.Sprintf("%d", userID),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(signingKey(cfg, emailVerificationPurpose))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return tokenString, nil
+}
+
+// ValidateEmailVerificationToken checks the signature and expiry of an email
+// verification token
+func ValidateEmailVerificationToken(tokenString string, cfg *config.Config) (*EmailVerificationClaim, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &EmailVerificationClaim{}, func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return signingKey(cfg, emailVerificationPurpose), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("token validation failed: %w", err)
+	}
+
+	claims, ok := token.Claims.(*EmailVerificationClaim)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+const emailVerificationPurpose = "email-verification"
+
+// signingKey derives a key from the JWT secret for tokens issued for
+// purpose, so tokens of one kind are rejected where another is expected
+func signingKey(cfg *config.Config, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(cfg.JWTSecret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -27,7 +30,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		log.Printf("Register validation error: %v", err)
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
 		return
 	}
 
@@ -43,7 +46,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 			return
 		}
 
-		utils.ErrorResponse(c, http.StatusBadRequest, "Registration failed", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Registration failed", err)
 		return
 	}
 
@@ -63,9 +66,16 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	input.Email = strings.TrimSpace(input.Email)
 	clientIP := c.ClientIP()
 
-	response, err := h.AuthService.Login(input)
+	response, err := h.AuthService.Login(input, clientIP)
 	if err != nil {
 		log.Printf("Login failed for email %s from IP %s: %v", input.Email, clientIP, err)
+
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			utils.ErrorResponse(c, http.StatusTooManyRequests, "Login failed", err)
+			return
+		}
 		utils.ErrorResponse(c, http.StatusUnauthorized, "Login failed", "Invalid email or password")
 		return
 	}
@@ -74,6 +84,175 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Login successful", response)
 }
 
+// Refresh trades a refresh token for a new pair of tokens
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var input services.RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	response, err := h.AuthService.Refresh(input)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Token refresh failed", err)
+			return
+		}
+
+		log.Printf("Token refresh error: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Token refresh failed", "An error occurred while refreshing your session")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Token refreshed successfully", response)
+}
+
+// Logout revokes the caller's access token and session. The body, which may
+// name the refresh token to revoke, is optional.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var input services.LogoutInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+			return
+		}
+	}
+
+	if err := h.AuthService.Logout(currentSession(c), input); err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Logout failed", err)
+			return
+		}
+
+		log.Printf("Logout error: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Logout failed", "An error occurred while logging out")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Logged out successfully", nil)
+}
+
+// VerifyEmail confirms a user's email address from the link mailed to them
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Email verification failed", "Verification token is required")
+		return
+	}
+
+	user, err := h.AuthService.VerifyEmail(token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidVerificationToken) {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Email verification failed", err)
+			return
+		}
+
+		log.Printf("Email verification error: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Email verification failed", "An error occurred while verifying your email")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Email verified successfully", user)
+}
+
+// ResendVerification mails the caller a new verification link
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	userIDValue, ok := userID.(uint)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized", "User not authenticated")
+		return
+	}
+
+	if err := h.AuthService.ResendVerification(userIDValue); err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmailAlreadyVerified):
+			utils.ErrorResponse(c, http.StatusConflict, "Failed to resend verification email", err)
+		case errors.Is(err, services.ErrVerificationRecentlySent):
+			utils.ErrorResponse(c, http.StatusTooManyRequests, "Failed to resend verification email", err)
+		default:
+			log.Printf("Error resending verification email to user %d: %v", userIDValue, err)
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to resend verification email", "An error occurred while sending the verification email")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Verification email sent", nil)
+}
+
+// ForgotPassword mails a password reset link. It answers the same whether
+// or not the email belongs to an account.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var input services.ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	h.AuthService.RequestPasswordReset(input)
+	utils.SuccessResponse(c, http.StatusOK, "If an account exists for this email, a password reset link has been sent", nil)
+}
+
+// ResetPassword sets a new password with the token from a reset email
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var input services.ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	if err := h.AuthService.ResetPassword(input); err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) || strings.HasPrefix(err.Error(), "password must") {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Password reset failed", err)
+			return
+		}
+
+		log.Printf("Password reset error: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Password reset failed", "An error occurred while resetting your password")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Password reset successfully, please log in with your new password", nil)
+}
+
+// ChangePassword replaces the caller's password and answers with a new
+// session, as all existing ones are revoked
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var input services.ChangePasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	response, err := h.AuthService.ChangePassword(currentSession(c), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrIncorrectPassword):
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Password change failed", err)
+		case errors.Is(err, services.ErrPasswordNotChanged) || strings.HasPrefix(err.Error(), "password must"):
+			utils.ErrorResponse(c, http.StatusBadRequest, "Password change failed", err)
+		default:
+			log.Printf("Password change error: %v", err)
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Password change failed", "An error occurred while changing your password")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Password changed successfully", response)
+}
+
+// currentSession describes the access token the request was authenticated with
+func currentSession(c *gin.Context) services.Session {
+	var session services.Session
+	if uid, ok := c.Get("user_id"); ok {
+		session.UserID, _ = uid.(uint)
+	}
+	session.TokenID = c.GetString("token_id")
+	session.SessionID = c.GetString("session_id")
+	session.ExpiresAt = c.GetTime("token_expires_at")
+	return session
+}
+
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -104,6 +283,55 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Profile retrieved successfully", user)
 }
 
+// UpdateProfile changes the caller's name, email, phone or avatar. It takes
+// JSON, or a multipart form when an avatar image is uploaded.
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	var input services.UpdateProfileInput
+	if err := c.ShouldBind(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	avatar, _ := c.FormFile("avatar")
+
+	user, err := h.AuthService.UpdateProfile(currentSession(c).UserID, input, avatar)
+	if err != nil {
+		if errors.Is(err, services.ErrEmailTaken) {
+			utils.ErrorResponse(c, http.StatusConflict, "Failed to update profile", "A user with this email already exists")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update profile", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Profile updated successfully", user)
+}
+
+// DeleteProfile deletes the caller's account after checking their password
+func (h *AuthHandler) DeleteProfile(c *gin.Context) {
+	var input services.DeleteAccountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	session := currentSession(c)
+	if err := h.AuthService.DeleteAccount(session, input); err != nil {
+		switch {
+		case errors.Is(err, services.ErrIncorrectPassword):
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Failed to delete account", err)
+		case errors.Is(err, services.ErrAdminCannotBeDeleted):
+			utils.ErrorResponse(c, http.StatusForbidden, "Failed to delete account", err)
+		default:
+			log.Printf("Error deleting account of user %d: %v", session.UserID, err)
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete account", "An error occurred while deleting your account")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Account deleted successfully", nil)
+}
+
 func (h *AuthHandler) GetAllUsers(c *gin.Context) {
 	role, exists := c.Get("role")
 	if !exists {
@@ -129,3 +357,144 @@ func (h *AuthHandler) GetAllUsers(c *gin.Context) {
 	log.Printf("Admin %v retrieved users list (%d users)", c.GetString("email"), len(users))
 	utils.SuccessResponse(c, http.StatusOK, fmt.Sprintf("Retrieved %d users successfully", len(users)), users)
 }
+
+// UnlockUser lets an admin lift the login lockout of a user
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	user, err := h.AuthService.UnlockUser(uint(userID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to unlock user", err)
+		return
+	}
+
+	log.Printf("Admin %v unlocked login for user %d", c.GetString("email"), userID)
+	utils.SuccessResponse(c, http.StatusOK, "User unlocked successfully", user)
+}
+
+func (h *AuthHandler) GetOrganizations(c *gin.Context) {
+	orgs, err := h.AuthService.GetAllOrganizations()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve organizations", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Organizations retrieved successfully", orgs)
+}
+
+func (h *AuthHandler) CreateOrganization(c *gin.Context) {
+	var input services.OrganizationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	org, err := h.AuthService.CreateOrganization(input)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create organization", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Organization created successfully", org)
+}
+
+func (h *AuthHandler) UpdateOrganization(c *gin.Context) {
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid organization ID", err)
+		return
+	}
+
+	var input services.OrganizationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	org, err := h.AuthService.UpdateOrganization(uint(orgID), input)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update organization", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Organization updated successfully", org)
+}
+
+func (h *AuthHandler) DeleteOrganization(c *gin.Context) {
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid organization ID", err)
+		return
+	}
+
+	if err := h.AuthService.DeleteOrganization(uint(orgID)); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete organization", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Organization deleted successfully", nil)
+}
+
+func (h *AuthHandler) GetOrganizers(c *gin.Context) {
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid organization ID", err)
+		return
+	}
+
+	users, err := h.AuthService.GetOrganizers(uint(orgID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to retrieve organizers", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Organizers retrieved successfully", users)
+}
+
+func (h *AuthHandler) AssignOrganizer(c *gin.Context) {
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid organization ID", err)
+		return
+	}
+
+	var input services.AssignOrganizerInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	user, err := h.AuthService.AssignOrganizer(uint(orgID), input.UserID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to assign organizer", err)
+		return
+	}
+
+	log.Printf("Admin %v assigned user %d to organization %d", c.GetString("email"), input.UserID, orgID)
+	utils.SuccessResponse(c, http.StatusOK, "Organizer assigned successfully", user)
+}
+
+func (h *AuthHandler) RemoveOrganizer(c *gin.Context) {
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid organization ID", err)
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	if err := h.AuthService.RemoveOrganizer(uint(orgID), uint(userID)); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to remove organizer", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Organizer removed successfully", nil)
+}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/robaa12/mawid/internal/utils"
+	"github.com/robaa12/mawid/pkg/models"
 	"github.com/robaa12/mawid/pkg/services"
 )
 
@@ -19,11 +23,49 @@ func NewBookingHandler(bookService *services.BookingService) *BookingHandler {
 	}
 }
 
+// currentActor describes the authenticated caller for management operations.
+// Anonymous callers get a zero Actor, which manages nothing.
+func currentActor(c *gin.Context) services.Actor {
+	var actor services.Actor
+	if uid, ok := c.Get("user_id"); ok {
+		actor.UserID, _ = uid.(uint)
+	}
+	if role, ok := c.Get("role"); ok {
+		actor.Role = models.Role(fmt.Sprintf("%v", role))
+	}
+	if orgID, ok := c.Get("organization_id"); ok {
+		actor.OrganizationID, _ = orgID.(*uint)
+	}
+	return actor
+}
+
+// cursorPagination reports whether a listing was asked for by cursor rather
+// than by page, returning the cursor and the page size. An empty cursor
+// requests the first page.
+func cursorPagination(c *gin.Context) (string, int, bool) {
+	cursor, ok := c.GetQuery("cursor")
+	if !ok {
+		return "", 0, false
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", c.DefaultQuery("page_size", "10")))
+	return cursor, limit, true
+}
+
+// paginationStatus is the status for a failed listing: a bad cursor is the
+// client's fault, anything else is ours
+func paginationStatus(err error) int {
+	if errors.Is(err, services.ErrInvalidCursor) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
 func (h *BookingHandler) CreateBooking(c *gin.Context) {
 	var input services.CreateBookingInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
 		return
 	}
 
@@ -35,11 +77,40 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 
 	booking, err := h.BookingService.CreateBooking(userID.(uint), input)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create booking", err.Error())
+		if errors.Is(err, services.ErrEmailNotVerified) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Failed to create booking", err)
+			return
+		}
+		if errors.Is(err, services.ErrEventSoldOut) && input.JoinWaitlist {
+			entry, err := h.BookingService.JoinWaitlist(userID.(uint), services.JoinWaitlistInput{EventID: input.EventID})
+			if err != nil {
+				utils.ErrorResponse(c, http.StatusBadRequest, "Failed to join waitlist", err)
+				return
+			}
+			utils.SuccessResponse(c, http.StatusAccepted, "Event is sold out, you have been added to the waitlist", entry)
+			return
+		}
+		if errors.Is(err, services.ErrEventSoldOut) || errors.Is(err, services.ErrTicketLimitReached) || errors.Is(err, services.ErrTicketTypeSoldOut) ||
+			errors.Is(err, services.ErrEventNotBookable) {
+			utils.ErrorResponse(c, http.StatusConflict, "Failed to create booking", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create booking", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusCreated, "Booking created successfully", booking)
+	respondSeatsHeld(c, booking)
+}
+
+// respondSeatsHeld answers a new booking, telling the user until when its
+// seats are held for them to confirm it
+func respondSeatsHeld(c *gin.Context, booking *services.BookingResponse) {
+	if booking.HoldExpiresAt == nil {
+		utils.SuccessResponse(c, http.StatusCreated, "Seats held, confirm the booking before the hold expires", booking)
+		return
+	}
+	utils.SuccessResponsef(c, http.StatusCreated, booking, "Seats held until %s, confirm the booking before then",
+		booking.HoldExpiresAt.UTC().Format(time.RFC3339))
 }
 
 func (h *BookingHandler) GetUserBookings(c *gin.Context) {
@@ -49,16 +120,21 @@ func (h *BookingHandler) GetUserBookings(c *gin.Context) {
 		return
 	}
 
-	p, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	ps, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-
-	res, err := h.BookingService.GetUserBookings(uid.(uint), p, ps)
+	var res *services.PaginatedBookings
+	var err error
+	if cursor, limit, ok := cursorPagination(c); ok {
+		res, err = h.BookingService.GetUserBookingsByCursor(uid.(uint), cursor, limit)
+	} else {
+		p, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		ps, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+		res, err = h.BookingService.GetUserBookings(uid.(uint), p, ps)
+	}
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve bookings", err.Error())
+		utils.ErrorResponse(c, paginationStatus(err), "Failed to retrieve bookings", err)
 		return
 	}
 
-	if res.Total == 0 {
+	if len(res.Bookings) == 0 {
 		utils.SuccessResponse(c, http.StatusOK, "No bookings found", res)
 		return
 	}
@@ -75,13 +151,13 @@ func (h *BookingHandler) CheckEventBookings(c *gin.Context) {
 
 	evtID, err := strconv.ParseUint(c.Param("eventId"), 10, 32)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
 		return
 	}
 
 	has, b, err := h.BookingService.CheckUserBooking(uid.(uint), uint(evtID))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check booking", err.Error())
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check booking", err)
 		return
 	}
 
@@ -96,7 +172,7 @@ func (h *BookingHandler) CheckEventBookings(c *gin.Context) {
 }
 
 func (h *BookingHandler) UpdateBookingStatus(c *gin.Context) {
-	uid, exists := c.Get("user_id")
+	_, exists := c.Get("user_id")
 	if !exists {
 		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
 		return
@@ -104,25 +180,78 @@ func (h *BookingHandler) UpdateBookingStatus(c *gin.Context) {
 
 	bid, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid booking ID", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid booking ID", err)
 		return
 	}
 
 	var input services.UpdateBookingStatusInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
 		return
 	}
 
-	b, err := h.BookingService.UpdateBookingStatus(uint(bid), uid.(uint), input)
+	b, err := h.BookingService.UpdateBookingStatus(uint(bid), currentActor(c), input)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update booking status", err.Error())
+		if errors.Is(err, services.ErrInvalidTransition) || errors.Is(err, services.ErrStatusChanged) ||
+			errors.Is(err, services.ErrHoldExpired) {
+			utils.ErrorResponse(c, http.StatusConflict, "Failed to update booking status", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update booking status", err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Booking status updated sucessfully", b)
 }
 
+func (h *BookingHandler) ConfirmBooking(c *gin.Context) {
+	uid, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	bid, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid booking ID", err)
+		return
+	}
+
+	b, err := h.BookingService.ConfirmBooking(uint(bid), uid.(uint))
+	if err != nil {
+		if errors.Is(err, services.ErrHoldExpired) {
+			utils.ErrorResponse(c, http.StatusConflict, "Failed to confirm booking", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to confirm booking", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Booking confirmed successfully", b)
+}
+
+func (h *BookingHandler) GetBookingHistory(c *gin.Context) {
+	_, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	bid, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid booking ID", err)
+		return
+	}
+
+	history, err := h.BookingService.GetBookingHistory(uint(bid), currentActor(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to retrieve booking history", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Booking history retrieved successfully", history)
+}
+
 func (h *BookingHandler) GetAllBookings(c *gin.Context) {
 	_, exists := c.Get("user_id")
 	if !exists {
@@ -130,13 +259,192 @@ func (h *BookingHandler) GetAllBookings(c *gin.Context) {
 		return
 	}
 
+	var bs *services.PaginatedBookings
+	var err error
+	if cursor, limit, ok := cursorPagination(c); ok {
+		bs, err = h.BookingService.GetAllBookingsByCursor(currentActor(c), cursor, limit)
+	} else {
+		p, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		ps, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+		bs, err = h.BookingService.GetAllBookings(currentActor(c), p, ps)
+	}
+	if err != nil {
+		utils.ErrorResponse(c, paginationStatus(err), "Failed to get bookings", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Bookings retrieved successfully", bs)
+}
+
+func (h *BookingHandler) JoinWaitlist(c *gin.Context) {
+	uid, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	var input services.JoinWaitlistInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	entry, err := h.BookingService.JoinWaitlist(uid.(uint), input)
+	if err != nil {
+		if errors.Is(err, services.ErrEmailNotVerified) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Failed to join waitlist", err)
+			return
+		}
+		if errors.Is(err, services.ErrEventNotBookable) {
+			utils.ErrorResponse(c, http.StatusConflict, "Failed to join waitlist", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to join waitlist", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Joined waitlist successfully", entry)
+}
+
+func (h *BookingHandler) GetUserWaitlist(c *gin.Context) {
+	uid, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	entries, err := h.BookingService.GetUserWaitlist(uid.(uint))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve waitlist", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Waitlist retrieved successfully", entries)
+}
+
+func (h *BookingHandler) LeaveWaitlist(c *gin.Context) {
+	uid, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	entryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid waitlist entry ID", err)
+		return
+	}
+
+	if err := h.BookingService.LeaveWaitlist(uint(entryID), uid.(uint)); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to leave waitlist", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Left waitlist successfully", nil)
+}
+
+func (h *BookingHandler) ClaimWaitlistOffer(c *gin.Context) {
+	uid, exists := c.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
+		return
+	}
+
+	entryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid waitlist entry ID", err)
+		return
+	}
+
+	// The body is optional; it only selects a ticket type for tiered events
+	var input services.ClaimWaitlistInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+			return
+		}
+	}
+
+	booking, err := h.BookingService.ClaimWaitlistOffer(uint(entryID), uid.(uint), input)
+	if err != nil {
+		if errors.Is(err, services.ErrEmailNotVerified) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Failed to claim waitlist offer", err)
+			return
+		}
+		if errors.Is(err, services.ErrOfferExpired) || errors.Is(err, services.ErrEventSoldOut) || errors.Is(err, services.ErrTicketTypeSoldOut) ||
+			errors.Is(err, services.ErrEventNotBookable) {
+			utils.ErrorResponse(c, http.StatusConflict, "Failed to claim waitlist offer", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to claim waitlist offer", err)
+		return
+	}
+
+	respondSeatsHeld(c, booking)
+}
+
+func (h *BookingHandler) GetEventWaitlist(c *gin.Context) {
+	evtID, err := strconv.ParseUint(c.Param("eventId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	entries, err := h.BookingService.GetEventWaitlist(currentActor(c), uint(evtID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to retrieve waitlist", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Waitlist retrieved successfully", entries)
+}
+
+func (h *BookingHandler) GetEventAttendees(c *gin.Context) {
+	evtID, err := strconv.ParseUint(c.Param("eventId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
 	p, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	ps, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	bs, err := h.BookingService.GetAllBookings(p, ps)
+	bs, err := h.BookingService.GetEventAttendees(currentActor(c), uint(evtID), p, ps)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get bookings", err.Error())
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to retrieve attendees", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Bookings retrieved successfully", bs)
+	utils.SuccessResponse(c, http.StatusOK, "Attendees retrieved successfully", bs)
+}
+
+func (h *BookingHandler) GetDeletedBookings(c *gin.Context) {
+	p, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	ps, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	bs, err := h.BookingService.GetDeletedBookings(currentActor(c), p, ps)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to get deleted bookings", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Deleted bookings retrieved successfully", bs)
+}
+
+func (h *BookingHandler) RestoreBooking(c *gin.Context) {
+	bid, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid booking ID", err)
+		return
+	}
+
+	b, err := h.BookingService.RestoreBooking(currentActor(c), uint(bid))
+	if err != nil {
+		if errors.Is(err, services.ErrEventSoldOut) || errors.Is(err, services.ErrTicketLimitReached) {
+			utils.ErrorResponse(c, http.StatusConflict, "Failed to restore booking", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to restore booking", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Booking restored successfully", b)
 }
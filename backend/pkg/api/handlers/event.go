@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/robaa12/mawid/internal/utils"
@@ -26,7 +29,7 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 	contentType := c.GetHeader("Content-Type")
 	if contentType != "" && strings.Contains(contentType, "multipart/form-data") {
 		if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
-			utils.ErrorResponse(c, http.StatusBadRequest, "Failed to parse form data", err.Error())
+			utils.ErrorResponse(c, http.StatusBadRequest, "Failed to parse form data", err)
 			return
 		}
 
@@ -37,11 +40,36 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 			input.CategoryID = uint(categoryID)
 		}
 		input.EventDate = c.PostForm("event_date")
+		input.EndDate = c.PostForm("end_date")
+		durationMinutes, err := strconv.Atoi(c.PostForm("duration_minutes"))
+		if err == nil {
+			input.DurationMinutes = durationMinutes
+		}
+		input.TimeZone = c.PostForm("time_zone")
 		input.Venue = c.PostForm("venue")
+		venueID, err := strconv.ParseUint(c.PostForm("venue_id"), 10, 32)
+		if err == nil {
+			input.VenueID = uint(venueID)
+		}
+		input.Status = c.PostForm("status")
+		input.PublishAt = c.PostForm("publish_at")
+		organizationID, err := strconv.ParseUint(c.PostForm("organization_id"), 10, 32)
+		if err == nil {
+			orgID := uint(organizationID)
+			input.OrganizationID = &orgID
+		}
 		price, err := strconv.ParseFloat(c.PostForm("price"), 64)
 		if err == nil {
 			input.Price = price
 		}
+		capacity, err := strconv.Atoi(c.PostForm("capacity"))
+		if err == nil {
+			input.Capacity = capacity
+		}
+		maxTicketsPerUser, err := strconv.Atoi(c.PostForm("max_tickets_per_user"))
+		if err == nil {
+			input.MaxTicketsPerUser = maxTicketsPerUser
+		}
 
 		tagStr := c.PostForm("tags")
 		if tagStr != "" {
@@ -52,21 +80,21 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		}
 	} else {
 		if err := c.ShouldBind(&input); err != nil {
-			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err.Error())
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
 			return
 		}
 	}
 
-	if input.Name == "" || input.Description == "" || input.CategoryID == 0 || input.EventDate == "" || input.Venue == "" {
+	if input.Name == "" || input.Description == "" || input.CategoryID == 0 || input.EventDate == "" || (input.Venue == "" && input.VenueID == 0) {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Missing required fields", nil)
 		return
 	}
 
 	file, _ := c.FormFile("image")
 
-	event, err := h.EventService.CreateEvent(input, file)
+	event, err := h.EventService.CreateEvent(currentActor(c), input, file)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create event", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create event", err)
 		return
 	}
 
@@ -74,27 +102,27 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 }
 
 func (h *EventHandler) GetEvents(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-
-	// Get category filter if provided
-	categoryIDStr := c.Query("category_id")
-	var categoryID uint
-	if categoryIDStr != "" {
-		parsedID, err := strconv.ParseUint(categoryIDStr, 10, 32)
-		if err == nil {
-			categoryID = uint(parsedID)
-		}
+	filter, ok := h.eventListFilter(c)
+	if !ok {
+		return
 	}
 
-	// Returns events sorted by date (upcoming events first)
-	events, err := h.EventService.GetAllEvents(page, pageSize, categoryID)
+	// Events are sorted upcoming first unless another sort is requested
+	var events *services.PaginatedEvents
+	var err error
+	if cursor, limit, ok := cursorPagination(c); ok {
+		events, err = h.EventService.GetAllEventsByCursor(cursor, limit, filter)
+	} else {
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+		events, err = h.EventService.GetAllEvents(page, pageSize, filter)
+	}
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve events", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to retrieve events", err)
 		return
 	}
 
-	if events.Total < 1 {
+	if len(events.Events) == 0 {
 		utils.SuccessResponse(c, http.StatusNotFound, "No events found", nil)
 		return
 	}
@@ -102,18 +130,100 @@ func (h *EventHandler) GetEvents(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Events retrieved successfully", events)
 }
 
+// eventListFilter reads the listing filters shared by the event endpoints,
+// answering the request itself when one of them is invalid. The category is
+// given by ID or by slug, tags as a comma separated list that matches any of
+// them unless tag_match=all, and from and to as RFC 3339 times or plain
+// dates, to covering the whole of its day.
+func (h *EventHandler) eventListFilter(c *gin.Context) (services.EventListFilter, bool) {
+	filter := services.EventListFilter{
+		MatchAllTags: c.Query("tag_match") == "all",
+		FreeOnly:     c.Query("free") == "true",
+		Status:       c.Query("status"),
+		Sort:         c.Query("sort"),
+	}
+
+	if parsedID, err := strconv.ParseUint(c.Query("category_id"), 10, 32); err == nil {
+		filter.CategoryID = uint(parsedID)
+	}
+	if slug := c.Query("category"); slug != "" && filter.CategoryID == 0 {
+		category, err := h.EventService.GetCategoryBySlug(slug)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusNotFound, "Category not found", err)
+			return filter, false
+		}
+		filter.CategoryID = category.ID
+	}
+
+	if tags := c.Query("tags"); tags != "" {
+		filter.Tags = strings.Split(tags, ",")
+	}
+	if parsedID, err := strconv.ParseUint(c.Query("venue_id"), 10, 32); err == nil {
+		filter.VenueID = uint(parsedID)
+	}
+
+	var err error
+	if filter.From, err = parseFilterTime(c.Query("from"), false); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from date", err)
+		return filter, false
+	}
+	if filter.To, err = parseFilterTime(c.Query("to"), true); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to date", err)
+		return filter, false
+	}
+	if filter.MinPrice, err = parseFilterPrice(c.Query("min_price")); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid minimum price", err)
+		return filter, false
+	}
+	if filter.MaxPrice, err = parseFilterPrice(c.Query("max_price")); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid maximum price", err)
+		return filter, false
+	}
+
+	return filter, true
+}
+
+func parseFilterTime(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected RFC 3339 or YYYY-MM-DD", value)
+	}
+	if endOfDay {
+		day = day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return &day, nil
+}
+
+func parseFilterPrice(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price %q", value)
+	}
+	return &price, nil
+}
+
 func (h *EventHandler) GetEventByID(c *gin.Context) {
 	// Parse event ID
 	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
 		return
 	}
 
-	// Get the event
-	event, err := h.EventService.GetEventByID(uint(eventID))
+	// Get the event, admins can also see drafts and scheduled events
+	event, err := h.EventService.GetEventByID(uint(eventID), currentActor(c))
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusNotFound, "Event not found", err.Error())
+		utils.ErrorResponse(c, http.StatusNotFound, "Event not found", err)
 		return
 	}
 
@@ -125,21 +235,34 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 	// parse event ID
 	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
 		return
 	}
 
-	var input services.UpdateEventInput
+	var input services.UpdateSeriesInput
 	if err := c.ShouldBind(&input); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	// Occurrences of a series can be edited together with the rest of it
+	scope := c.DefaultQuery("scope", services.SeriesScopeThis)
+	if scope != services.SeriesScopeThis {
+		series, err := h.EventService.UpdateEventInSeries(currentActor(c), uint(eventID), scope, input)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update event series", err)
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "Event series updated successfully", series)
 		return
 	}
 
 	file, _ := c.FormFile("image")
 
-	event, err := h.EventService.UpdateEvent(uint(eventID), input, file)
+	event, err := h.EventService.UpdateEvent(currentActor(c), uint(eventID), input.UpdateEventInput, file)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update event", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update event", err)
 		return
 	}
 
@@ -150,13 +273,24 @@ func (h *EventHandler) DeleteEvent(c *gin.Context) {
 	// Parse event ID
 	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	scope := c.DefaultQuery("scope", services.SeriesScopeThis)
+	if scope != services.SeriesScopeThis {
+		if err := h.EventService.DeleteEventInSeries(currentActor(c), uint(eventID), scope); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete event series", err)
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "Event series deleted successfully", nil)
 		return
 	}
 
 	// Delete the event
-	if err := h.EventService.DeleteEvent(uint(eventID)); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete event", err.Error())
+	if err := h.EventService.DeleteEvent(currentActor(c), uint(eventID)); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete event", err)
 		return
 	}
 
@@ -171,16 +305,26 @@ func (h *EventHandler) SearchEvents(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	filter, ok := h.eventListFilter(c)
+	if !ok {
+		return
+	}
 
-	events, err := h.EventService.SearchEvents(query, page, pageSize)
+	var events *services.PaginatedEvents
+	var err error
+	if cursor, limit, ok := cursorPagination(c); ok {
+		events, err = h.EventService.SearchEventsByCursor(query, cursor, limit, filter)
+	} else {
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+		events, err = h.EventService.SearchEvents(query, page, pageSize, filter)
+	}
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to search events", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to search events", err)
 		return
 	}
 
-	if events.Total < 1 {
+	if len(events.Events) == 0 {
 		utils.ErrorResponse(c, http.StatusNotFound, "No events found matching your search", nil)
 		return
 	}
@@ -188,10 +332,23 @@ func (h *EventHandler) SearchEvents(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Search results", events)
 }
 
+// GetSuggestions completes the search box as the user types
+func (h *EventHandler) GetSuggestions(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	suggestions, err := h.EventService.GetSuggestions(c.Query("q"), limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve suggestions", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Suggestions retrieved successfully", suggestions)
+}
+
 func (h *EventHandler) GetRecentEvents(c *gin.Context) {
 	events, err := h.EventService.GetRecentEvents()
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve recent events", err.Error())
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve recent events", err)
 		return
 	}
 
@@ -199,28 +356,51 @@ func (h *EventHandler) GetRecentEvents(c *gin.Context) {
 }
 
 func (h *EventHandler) GetCategories(c *gin.Context) {
+	if c.Query("tree") == "true" {
+		tree, err := h.EventService.GetCategoryTree()
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve categories", err)
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "Categories retrieved successfully", tree)
+		return
+	}
+
 	categories, err := h.EventService.GetAllCategories()
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve categories", err.Error())
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve categories", err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Categories retrieved successfully", categories)
 }
 
-func (h *EventHandler) CreateCategory(c *gin.Context) {
-	var input struct {
-		Name string `json:"name" binding:"required"`
+func (h *EventHandler) GetCategoryBySlug(c *gin.Context) {
+	category, err := h.EventService.GetCategoryBySlug(c.Param("slug"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Category not found", err)
+		return
 	}
 
+	utils.SuccessResponse(c, http.StatusOK, "Category retrieved successfully", category)
+}
+
+func (h *EventHandler) CreateCategory(c *gin.Context) {
+	var input services.CategoryInput
+
 	if err := c.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
 		return
 	}
 
-	category, err := h.EventService.CreateCategory(input.Name)
+	category, err := h.EventService.CreateCategory(currentActor(c), input)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failedd to create category", err.Error())
+		if errors.Is(err, services.ErrCategoryExists) {
+			utils.ErrorResponse(c, http.StatusConflict, "Failedd to create category", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failedd to create category", err)
 		return
 	}
 
@@ -231,22 +411,23 @@ func (h *EventHandler) UpdateCategory(c *gin.Context) {
 	// Parse category ID
 	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err)
 		return
 	}
 
-	var input struct {
-		Name string `json:"name" binding:"required"`
-	}
-
+	var input services.CategoryInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
 		return
 	}
 
-	category, err := h.EventService.UpdateCategory(uint(categoryID), input.Name)
+	category, err := h.EventService.UpdateCategory(currentActor(c), uint(categoryID), input)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update category", err.Error())
+		if errors.Is(err, services.ErrCategoryExists) {
+			utils.ErrorResponse(c, http.StatusConflict, "Failed to update category", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update category", err)
 		return
 	}
 
@@ -257,14 +438,578 @@ func (h *EventHandler) DeleteCategory(c *gin.Context) {
 	// parse category ID
 	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err.Error())
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err)
+		return
+	}
+
+	var input services.DeleteCategoryInput
+	if moveTo := c.Query("move_to"); moveTo != "" {
+		targetID, err := strconv.ParseUint(moveTo, 10, 32)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid target category ID", err)
+			return
+		}
+		input.MoveTo = uint(targetID)
+	}
+	input.Force = c.Query("force") == "true"
+
+	report, err := h.EventService.DeleteCategory(currentActor(c), uint(categoryID), input)
+	if err != nil {
+		if errors.Is(err, services.ErrCategoryInUse) {
+			utils.ErrorResponse(c, http.StatusConflict, "Failed to delete category", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete category", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category deleted successfully", report)
+}
+
+func (h *EventHandler) GetTicketTypes(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	ticketTypes, err := h.EventService.GetTicketTypes(uint(eventID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Event not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ticket types retrieved successfully", ticketTypes)
+}
+
+func (h *EventHandler) CreateTicketType(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	var input services.TicketTypeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	ticketType, err := h.EventService.CreateTicketType(currentActor(c), uint(eventID), input)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create ticket type", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Ticket type created successfully", ticketType)
+}
+
+func (h *EventHandler) UpdateTicketType(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	ticketTypeID, err := strconv.ParseUint(c.Param("ticketTypeId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ticket type ID", err)
+		return
+	}
+
+	var input services.TicketTypeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	ticketType, err := h.EventService.UpdateTicketType(currentActor(c), uint(eventID), uint(ticketTypeID), input)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update ticket type", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ticket type updated successfully", ticketType)
+}
+
+func (h *EventHandler) DeleteTicketType(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	ticketTypeID, err := strconv.ParseUint(c.Param("ticketTypeId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ticket type ID", err)
+		return
+	}
+
+	if err := h.EventService.DeleteTicketType(currentActor(c), uint(eventID), uint(ticketTypeID)); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete ticket type", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ticket type deleted successfully", nil)
+}
+
+func (h *EventHandler) CreateSeries(c *gin.Context) {
+	var input services.CreateSeriesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	series, err := h.EventService.CreateSeries(currentActor(c), input)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create event series", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Event series created successfully", series)
+}
+
+func (h *EventHandler) GetSeries(c *gin.Context) {
+	seriesID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid series ID", err)
+		return
+	}
+
+	series, err := h.EventService.GetSeries(uint(seriesID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Event series not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event series retrieved successfully", series)
+}
+
+func (h *EventHandler) UpdateSeries(c *gin.Context) {
+	seriesID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid series ID", err)
+		return
+	}
+
+	var input services.UpdateSeriesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	series, err := h.EventService.UpdateSeries(currentActor(c), uint(seriesID), input)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update event series", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event series updated successfully", series)
+}
+
+func (h *EventHandler) DeleteSeries(c *gin.Context) {
+	seriesID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid series ID", err)
+		return
+	}
+
+	if err := h.EventService.DeleteSeries(currentActor(c), uint(seriesID)); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete event series", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event series deleted successfully", nil)
+}
+
+func (h *EventHandler) GetManagedEvents(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	filter, ok := h.eventListFilter(c)
+	if !ok {
+		return
+	}
+
+	events, err := h.EventService.GetManagedEvents(currentActor(c), page, pageSize, filter)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to retrieve events", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Events retrieved successfully", events)
+}
+
+func (h *EventHandler) UpdateEventStatus(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	var input services.UpdateEventStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	event, err := h.EventService.UpdateEventStatus(currentActor(c), uint(eventID), input)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidEventTransition) {
+			utils.ErrorResponse(c, http.StatusConflict, "Failed to update event status", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update event status", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event status updated successfully", event)
+}
+
+func (h *EventHandler) GetNearbyEvents(c *gin.Context) {
+	lat, err := strconv.ParseFloat(c.Query("lat"), 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid latitude", err)
+		return
+	}
+
+	lng, err := strconv.ParseFloat(c.Query("lng"), 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid longitude", err)
+		return
+	}
+
+	radius, err := strconv.ParseFloat(c.DefaultQuery("radius_km", "10"), 64)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid radius", err)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	events, err := h.EventService.GetNearbyEvents(lat, lng, radius, page, pageSize)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to search nearby events", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Nearby events retrieved successfully", events)
+}
+
+func (h *EventHandler) GetVenues(c *gin.Context) {
+	venues, err := h.EventService.GetAllVenues(c.Query("city"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve venues", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Venues retrieved successfully", venues)
+}
+
+func (h *EventHandler) GetVenueByID(c *gin.Context) {
+	venueID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid venue ID", err)
+		return
+	}
+
+	venue, err := h.EventService.GetVenueByID(uint(venueID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Venue not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Venue retrieved successfully", venue)
+}
+
+func (h *EventHandler) CreateVenue(c *gin.Context) {
+	var input services.VenueInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	venue, err := h.EventService.CreateVenue(input)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to create venue", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Venue created successfully", venue)
+}
+
+func (h *EventHandler) UpdateVenue(c *gin.Context) {
+	venueID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid venue ID", err)
+		return
+	}
+
+	var input services.VenueInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	venue, err := h.EventService.UpdateVenue(uint(venueID), input)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to update venue", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Venue updated successfully", venue)
+}
+
+func (h *EventHandler) DeleteVenue(c *gin.Context) {
+	venueID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid venue ID", err)
+		return
+	}
+
+	if err := h.EventService.DeleteVenue(uint(venueID)); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete venue", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Venue deleted successfully", nil)
+}
+
+func (h *EventHandler) GetTags(c *gin.Context) {
+	tags, err := h.EventService.GetTags()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve tags", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tags retrieved successfully", tags)
+}
+
+func (h *EventHandler) RenameTag(c *gin.Context) {
+	tagID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid tag ID", err)
+		return
+	}
+
+	var input services.RenameTagInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	tag, err := h.EventService.RenameTag(uint(tagID), input)
+	if err != nil {
+		if errors.Is(err, services.ErrTagExists) {
+			utils.ErrorResponse(c, http.StatusConflict, "Failed to rename tag", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to rename tag", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tag renamed successfully", tag)
+}
+
+func (h *EventHandler) MergeTags(c *gin.Context) {
+	var input services.MergeTagsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	tag, err := h.EventService.MergeTags(input)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to merge tags", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tags merged successfully", tag)
+}
+
+func (h *EventHandler) DeleteTag(c *gin.Context) {
+	tagID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid tag ID", err)
+		return
+	}
+
+	if err := h.EventService.DeleteTag(uint(tagID)); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete tag", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tag deleted successfully", nil)
+}
+
+func (h *EventHandler) GetDeletedEvents(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	events, err := h.EventService.GetDeletedEvents(currentActor(c), page, pageSize)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to retrieve deleted events", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Deleted events retrieved successfully", events)
+}
+
+func (h *EventHandler) RestoreEvent(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	event, err := h.EventService.RestoreEvent(currentActor(c), uint(eventID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to restore event", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event restored successfully", event)
+}
+
+func (h *EventHandler) GetDeletedCategories(c *gin.Context) {
+	categories, err := h.EventService.GetDeletedCategories(currentActor(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to retrieve deleted categories", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Deleted categories retrieved successfully", categories)
+}
+
+func (h *EventHandler) RestoreCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err)
+		return
+	}
+
+	category, err := h.EventService.RestoreCategory(currentActor(c), uint(categoryID))
+	if err != nil {
+		if errors.Is(err, services.ErrCategoryExists) {
+			utils.ErrorResponse(c, http.StatusConflict, "Failed to restore category", err)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to restore category", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category restored successfully", category)
+}
+
+func (h *EventHandler) GetEventTranslations(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	translations, err := h.EventService.GetEventTranslations(currentActor(c), uint(eventID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to retrieve translations", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Translations retrieved successfully", translations)
+}
+
+// SetEventTranslation creates or replaces the translation of an event into
+// the locale in the path
+func (h *EventHandler) SetEventTranslation(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	var input services.TranslationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	translation, err := h.EventService.SetEventTranslation(currentActor(c), uint(eventID), c.Param("locale"), input)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to save translation", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Translation saved successfully", translation)
+}
+
+func (h *EventHandler) DeleteEventTranslation(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID", err)
+		return
+	}
+
+	if err := h.EventService.DeleteEventTranslation(currentActor(c), uint(eventID), c.Param("locale")); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete translation", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Translation deleted successfully", nil)
+}
+
+// GetCategoryTranslations lists the translations of the category with the
+// slug in the path
+func (h *EventHandler) GetCategoryTranslations(c *gin.Context) {
+	category, err := h.EventService.GetCategoryBySlug(c.Param("slug"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to retrieve translations", err)
+		return
+	}
+
+	translations, err := h.EventService.GetCategoryTranslations(currentActor(c), category.ID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Failed to retrieve translations", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Translations retrieved successfully", translations)
+}
+
+// SetCategoryTranslation creates or replaces the translation of a category
+// into the locale in the path
+func (h *EventHandler) SetCategoryTranslation(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err)
+		return
+	}
+
+	var input services.TranslationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", err)
+		return
+	}
+
+	translation, err := h.EventService.SetCategoryTranslation(currentActor(c), uint(categoryID), c.Param("locale"), input)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to save translation", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Translation saved successfully", translation)
+}
+
+func (h *EventHandler) DeleteCategoryTranslation(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID", err)
 		return
 	}
 
-	if err := h.EventService.DeleteCategory(uint(categoryID)); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete category", err.Error())
+	if err := h.EventService.DeleteCategoryTranslation(currentActor(c), uint(categoryID), c.Param("locale")); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Failed to delete translation", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Category and all its associated events deleted successfully", nil)
+	utils.SuccessResponse(c, http.StatusOK, "Translation deleted successfully", nil)
 }
@@ -11,13 +11,18 @@ import (
 	"github.com/robaa12/mawid/pkg/models"
 )
 
-func AuthMidddleware(cfg *config.Config) gin.HandlerFunc {
+// RevocationChecker reports whether an access token was revoked, by its jti
+type RevocationChecker interface {
+	IsTokenRevoked(tokenID string) (bool, error)
+}
+
+func AuthMidddleware(cfg *config.Config, revocations RevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		// if there is no authHeader
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header required"})
+				"error": utils.T(c, "Authorization header required")})
 			c.Abort()
 			return
 		}
@@ -26,7 +31,7 @@ func AuthMidddleware(cfg *config.Config) gin.HandlerFunc {
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid authorization format"})
+				"error": utils.T(c, "Invalid authorization format")})
 			c.Abort()
 			return
 		}
@@ -35,14 +40,56 @@ func AuthMidddleware(cfg *config.Config) gin.HandlerFunc {
 		claims, err := utils.ValidateToken(tokenString, cfg)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid token"})
+				"error": utils.T(c, "Invalid token")})
 			c.Abort()
 			return
 		}
 
-		c.Set("user_id", claims.UserID)
-		c.Set("email", claims.Email)
-		c.Set("role", claims.Role)
+		revoked, err := revocations.IsTokenRevoked(claims.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": utils.T(c, "Internal server error")})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": utils.T(c, "Token has been revoked")})
+			c.Abort()
+			return
+		}
+
+		setClaims(c, claims)
+		c.Next()
+	}
+}
+
+// setClaims makes the caller's identity and token available to the handlers
+func setClaims(c *gin.Context, claims *utils.JWTClaim) {
+	c.Set("user_id", claims.UserID)
+	c.Set("email", claims.Email)
+	c.Set("role", claims.Role)
+	c.Set("organization_id", claims.OrganizationID)
+	c.Set("token_id", claims.ID)
+	c.Set("session_id", claims.SessionID)
+	if claims.ExpiresAt != nil {
+		c.Set("token_expires_at", claims.ExpiresAt.Time)
+	}
+}
+
+// OptionalAuthMiddleware identifies the caller when a valid bearer token is
+// sent, but lets anonymous requests through. Invalid and revoked tokens are
+// ignored.
+func OptionalAuthMiddleware(cfg *config.Config, revocations RevocationChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenParts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(tokenParts) == 2 && tokenParts[0] == "Bearer" {
+			if claims, err := utils.ValidateToken(tokenParts[1], cfg); err == nil {
+				if revoked, err := revocations.IsTokenRevoked(claims.ID); err == nil && !revoked {
+					setClaims(c, claims)
+				}
+			}
+		}
 		c.Next()
 	}
 }
@@ -52,7 +99,7 @@ func AdminMiddleware() gin.HandlerFunc {
 		role, exists := c.Get("role")
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized"})
+				"error": utils.T(c, "Unauthorized")})
 			c.Abort()
 			return
 		}
@@ -61,7 +108,30 @@ func AdminMiddleware() gin.HandlerFunc {
 		roleStr := fmt.Sprintf("%v", role)
 		if roleStr != string(models.RoleAdmin) {
 			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Admin access required"})
+				"error": utils.T(c, "Admin access required")})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// OrganizerMiddleware admits platform admins and organizers. Which records an
+// organizer may touch is decided by the services, per organization.
+func OrganizerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, exists := c.Get("role")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": utils.T(c, "Unauthorized")})
+			c.Abort()
+			return
+		}
+
+		roleStr := fmt.Sprintf("%v", role)
+		if roleStr != string(models.RoleAdmin) && roleStr != string(models.RoleOrganizer) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": utils.T(c, "Organizer access required")})
 			c.Abort()
 			return
 		}
@@ -0,0 +1,19 @@
+package middlewars
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/robaa12/mawid/internal/utils"
+)
+
+// LocaleMiddleware resolves the language a request is answered in from the
+// lang query parameter or the Accept-Language header
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := utils.ResolveLocale(c.Query("lang"), c.GetHeader("Accept-Language"))
+		utils.SetLocale(c, locale)
+
+		c.Header("Content-Language", locale)
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
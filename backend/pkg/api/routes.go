@@ -7,6 +7,7 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/robaa12/mawid/config"
+	"github.com/robaa12/mawid/internal/utils"
 	"github.com/robaa12/mawid/pkg/api/handlers"
 	"github.com/robaa12/mawid/pkg/api/middlewars"
 )
@@ -31,7 +32,7 @@ func RateLimiterMiddleware() gin.HandlerFunc {
 
 		if len(newTimestamps) > 100 { // 100 requests per minute limit
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded. Try again later.",
+				"error": utils.T(c, "Rate limit exceeded. Try again later."),
 			})
 			return
 		}
@@ -55,6 +56,7 @@ func SecurityHeadersMiddleware() gin.HandlerFunc {
 func SetupRoutes(router *gin.Engine, authHandler *handlers.AuthHandler, eventHandler *handlers.EventHandler, bookingHandler *handlers.BookingHandler, cfg *config.Config) {
 	// Global middlewares
 	router.Use(gin.Recovery())
+	router.Use(middlewars.LocaleMiddleware())
 	router.Use(RateLimiterMiddleware())
 	router.Use(SecurityHeadersMiddleware())
 
@@ -74,7 +76,16 @@ func SetupRoutes(router *gin.Engine, authHandler *handlers.AuthHandler, eventHan
 	{
 		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
-		auth.GET("/profile", middlewars.AuthMidddleware(cfg), authHandler.GetProfile)
+		auth.POST("/refresh", authHandler.Refresh)
+		auth.POST("/logout", middlewars.AuthMidddleware(cfg, authHandler.AuthService), authHandler.Logout)
+		auth.GET("/verify-email", authHandler.VerifyEmail)
+		auth.POST("/verify-email/resend", middlewars.AuthMidddleware(cfg, authHandler.AuthService), authHandler.ResendVerification)
+		auth.POST("/password/forgot", authHandler.ForgotPassword)
+		auth.POST("/password/reset", authHandler.ResetPassword)
+		auth.POST("/password/change", middlewars.AuthMidddleware(cfg, authHandler.AuthService), authHandler.ChangePassword)
+		auth.GET("/profile", middlewars.AuthMidddleware(cfg, authHandler.AuthService), authHandler.GetProfile)
+		auth.PUT("/profile", middlewars.AuthMidddleware(cfg, authHandler.AuthService), authHandler.UpdateProfile)
+		auth.DELETE("/profile", middlewars.AuthMidddleware(cfg, authHandler.AuthService), authHandler.DeleteProfile)
 	}
 
 	// Event routes
@@ -83,33 +94,96 @@ func SetupRoutes(router *gin.Engine, authHandler *handlers.AuthHandler, eventHan
 		// Public routes
 		events.GET("", eventHandler.GetEvents)
 		events.GET("/recent", eventHandler.GetRecentEvents)
-		events.GET("/:id", eventHandler.GetEventByID)
+		events.GET("/:id", middlewars.OptionalAuthMiddleware(cfg, authHandler.AuthService), eventHandler.GetEventByID)
 		events.GET("/search", eventHandler.SearchEvents)
+		events.GET("/suggest", eventHandler.GetSuggestions)
 		events.GET("/categories", eventHandler.GetCategories)
-
-		// Protected routes
+		events.GET("/categories/:slug", eventHandler.GetCategoryBySlug)
+		events.GET("/:id/ticket-types", eventHandler.GetTicketTypes)
+		events.GET("/series/:id", eventHandler.GetSeries)
+		events.GET("/nearby", eventHandler.GetNearbyEvents)
+		events.GET("/venues", eventHandler.GetVenues)
+		events.GET("/venues/:id", eventHandler.GetVenueByID)
+		events.GET("/tags", eventHandler.GetTags)
+
+		// Protected routes, open to admins and to organizers for their own
+		// organization's events
 		adminEvents := events.Group("")
-		adminEvents.Use(middlewars.AuthMidddleware(cfg), middlewars.AdminMiddleware())
+		adminEvents.Use(middlewars.AuthMidddleware(cfg, authHandler.AuthService), middlewars.OrganizerMiddleware())
 		{
+			adminEvents.GET("/admin", eventHandler.GetManagedEvents)
 			adminEvents.POST("", eventHandler.CreateEvent)
 			adminEvents.PUT("/:id", eventHandler.UpdateEvent)
+			adminEvents.PUT("/:id/status", eventHandler.UpdateEventStatus)
 			adminEvents.DELETE("/:id", eventHandler.DeleteEvent)
 
+			// Trash endpoints
+			adminEvents.GET("/trash", eventHandler.GetDeletedEvents)
+			adminEvents.POST("/:id/restore", eventHandler.RestoreEvent)
+			adminEvents.GET("/categories/trash", eventHandler.GetDeletedCategories)
+			adminEvents.POST("/categories/:id/restore", eventHandler.RestoreCategory)
+
+			// Ticket type endpoints
+			adminEvents.POST("/:id/ticket-types", eventHandler.CreateTicketType)
+			adminEvents.PUT("/:id/ticket-types/:ticketTypeId", eventHandler.UpdateTicketType)
+			adminEvents.DELETE("/:id/ticket-types/:ticketTypeId", eventHandler.DeleteTicketType)
+
+			// Translation endpoints. Like the category routes themselves,
+			// categories are read by slug and written by ID.
+			adminEvents.GET("/:id/translations", eventHandler.GetEventTranslations)
+			adminEvents.PUT("/:id/translations/:locale", eventHandler.SetEventTranslation)
+			adminEvents.DELETE("/:id/translations/:locale", eventHandler.DeleteEventTranslation)
+			adminEvents.GET("/categories/:slug/translations", eventHandler.GetCategoryTranslations)
+			adminEvents.PUT("/categories/:id/translations/:locale", eventHandler.SetCategoryTranslation)
+			adminEvents.DELETE("/categories/:id/translations/:locale", eventHandler.DeleteCategoryTranslation)
+
+			// Recurring series endpoints
+			adminEvents.POST("/series", eventHandler.CreateSeries)
+			adminEvents.PUT("/series/:id", eventHandler.UpdateSeries)
+			adminEvents.DELETE("/series/:id", eventHandler.DeleteSeries)
+
 			// Category endpoints
 			adminEvents.POST("/categories", eventHandler.CreateCategory)
 			adminEvents.PUT("/categories/:id", eventHandler.UpdateCategory)
 			adminEvents.DELETE("/categories/:id", eventHandler.DeleteCategory)
 		}
+
+		// Venues are shared by every organization and managed by admins
+		adminVenues := events.Group("/venues")
+		adminVenues.Use(middlewars.AuthMidddleware(cfg, authHandler.AuthService), middlewars.AdminMiddleware())
+		{
+			adminVenues.POST("", eventHandler.CreateVenue)
+			adminVenues.PUT("/:id", eventHandler.UpdateVenue)
+			adminVenues.DELETE("/:id", eventHandler.DeleteVenue)
+		}
+
+		// Tags are shared as well, so renaming, merging and deleting them is
+		// left to admins
+		adminTags := events.Group("/tags")
+		adminTags.Use(middlewars.AuthMidddleware(cfg, authHandler.AuthService), middlewars.AdminMiddleware())
+		{
+			adminTags.POST("/merge", eventHandler.MergeTags)
+			adminTags.PUT("/:id", eventHandler.RenameTag)
+			adminTags.DELETE("/:id", eventHandler.DeleteTag)
+		}
 	}
 
 	// Booking routes
 	bookings := api.Group("/bookings")
-	bookings.Use(middlewars.AuthMidddleware(cfg)) // All routes require authentication
+	bookings.Use(middlewars.AuthMidddleware(cfg, authHandler.AuthService)) // All routes require authentication
 	{
 		bookings.POST("", bookingHandler.CreateBooking)
 		bookings.GET("", bookingHandler.GetUserBookings)
 		bookings.GET("/event/:eventId", bookingHandler.CheckEventBookings)
 		bookings.PUT("/:id/status", bookingHandler.UpdateBookingStatus)
+		bookings.POST("/:id/confirm", bookingHandler.ConfirmBooking)
+		bookings.GET("/:id/history", bookingHandler.GetBookingHistory)
+
+		// Waitlist routes
+		bookings.POST("/waitlist", bookingHandler.JoinWaitlist)
+		bookings.GET("/waitlist", bookingHandler.GetUserWaitlist)
+		bookings.DELETE("/waitlist/:id", bookingHandler.LeaveWaitlist)
+		bookings.POST("/waitlist/:id/claim", bookingHandler.ClaimWaitlistOffer)
 
 		bookings.OPTIONS("", func(c *gin.Context) {
 			c.Status(http.StatusOK)
@@ -121,15 +195,33 @@ func SetupRoutes(router *gin.Engine, authHandler *handlers.AuthHandler, eventHan
 
 	// Admin booking routes
 	adminBookings := bookings.Group("")
-	adminBookings.Use(middlewars.AuthMidddleware(cfg), middlewars.AdminMiddleware())
+	adminBookings.Use(middlewars.AuthMidddleware(cfg, authHandler.AuthService), middlewars.OrganizerMiddleware())
 	{
 		adminBookings.GET("/admin", bookingHandler.GetAllBookings)
+		adminBookings.GET("/admin/events/:eventId", bookingHandler.GetEventAttendees)
+		adminBookings.GET("/admin/events/:eventId/waitlist", bookingHandler.GetEventWaitlist)
+		adminBookings.GET("/admin/trash", bookingHandler.GetDeletedBookings)
+		adminBookings.POST("/admin/:id/restore", bookingHandler.RestoreBooking)
 	}
 
 	// Admin user routes
 	adminUsers := api.Group("/users")
-	adminUsers.Use(middlewars.AuthMidddleware(cfg), middlewars.AdminMiddleware())
+	adminUsers.Use(middlewars.AuthMidddleware(cfg, authHandler.AuthService), middlewars.AdminMiddleware())
 	{
 		adminUsers.GET("", authHandler.GetAllUsers)
+		adminUsers.POST("/:id/unlock", authHandler.UnlockUser)
+	}
+
+	// Admin organization routes
+	organizations := api.Group("/organizations")
+	organizations.Use(middlewars.AuthMidddleware(cfg, authHandler.AuthService), middlewars.AdminMiddleware())
+	{
+		organizations.GET("", authHandler.GetOrganizations)
+		organizations.POST("", authHandler.CreateOrganization)
+		organizations.PUT("/:id", authHandler.UpdateOrganization)
+		organizations.DELETE("/:id", authHandler.DeleteOrganization)
+		organizations.GET("/:id/organizers", authHandler.GetOrganizers)
+		organizations.POST("/:id/organizers", authHandler.AssignOrganizer)
+		organizations.DELETE("/:id/organizers/:userId", authHandler.RemoveOrganizer)
 	}
 }
@@ -9,26 +9,104 @@ import (
 type BookingStatus string
 
 const (
+	BookingStatusPending   BookingStatus = "pending"
 	BookingStatusConfirmed BookingStatus = "confirmed"
 	BookingStatusCancelled BookingStatus = "cancelled"
+	BookingStatusAttended  BookingStatus = "attended"
+	BookingStatusNoShow    BookingStatus = "no_show"
+	BookingStatusRefunded  BookingStatus = "refunded"
 )
 
+// bookingTransitions is the booking lifecycle: each status maps to the
+// statuses it may move to. Attended, no-show and refunded are final.
+var bookingTransitions = map[BookingStatus][]BookingStatus{
+	BookingStatusPending:   {BookingStatusConfirmed, BookingStatusCancelled},
+	BookingStatusConfirmed: {BookingStatusCancelled, BookingStatusAttended, BookingStatusNoShow, BookingStatusRefunded},
+	BookingStatusCancelled: {BookingStatusRefunded},
+}
+
+// ParseBookingStatus converts user input to a known booking status
+func ParseBookingStatus(value string) (BookingStatus, bool) {
+	status := BookingStatus(value)
+	switch status {
+	case BookingStatusPending, BookingStatusConfirmed, BookingStatusCancelled,
+		BookingStatusAttended, BookingStatusNoShow, BookingStatusRefunded:
+		return status, true
+	}
+	return "", false
+}
+
+// CanTransitionTo reports whether the lifecycle allows moving to next
+func (s BookingStatus) CanTransitionTo(next BookingStatus) bool {
+	for _, allowed := range bookingTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// HoldsSeat reports whether a booking in this status occupies seats. Pending
+// bookings only do while their hold is valid, which callers check separately.
+func (s BookingStatus) HoldsSeat() bool {
+	switch s {
+	case BookingStatusPending, BookingStatusConfirmed, BookingStatusAttended, BookingStatusNoShow:
+		return true
+	}
+	return false
+}
+
 type Booking struct {
+	ID            uint           `gorm:"primarykey" json:"id"`
+	UserID        uint           `json:"user_id"`
+	User          User           `gorm:"foreignKey:UserID" json:"user"`
+	EventID       uint           `json:"event_id"`
+	Event         Event          `gorm:"foreignKey:EventID" json:"event"`
+	TicketTypeID  *uint          `gorm:"index" json:"ticket_type_id"`
+	TicketType    *TicketType    `gorm:"foreignKey:TicketTypeID" json:"ticket_type,omitempty"`
+	Quantity      int            `gorm:"not null;default:1" json:"quantity"`
+	PricePaid     float64        `gorm:"not null;default:0" json:"price_paid"`
+	Attendees     []Attendee     `gorm:"foreignKey:BookingID" json:"attendees,omitempty"`
+	BookingDate   time.Time      `json:"booking_date"`
+	HoldExpiresAt *time.Time     `gorm:"index" json:"hold_expires_at"`
+	Status        BookingStatus  `gorm:"size:20;default:pending" json:"status"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Attendee is the person holding one seat of a booking. UserID is set on the
+// attendee who is the booking user, whose details are erased with the
+// account.
+type Attendee struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	BookingID uint      `gorm:"not null;index:idx_attendees_booking_id" json:"booking_id"`
+	UserID    *uint     `gorm:"index:idx_attendees_user_id" json:"-"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	Email     string    `gorm:"size:100" json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BookingStatusHistory records one status change of a booking. ChangedByID is
+// nil when the system made the change, e.g. when a seat hold expired.
+type BookingStatusHistory struct {
 	ID          uint          `gorm:"primarykey" json:"id"`
-	UserID      uint          `json:"user_id"`
-	User        User          `gorm:"foreignKey:UserID" json:"user"`
-	EventID     uint          `json:"event_id"`
-	Event       Event         `gorm:"foreignKey:EventID" json:"event"`
-	BookingDate time.Time     `json:"booking_date"`
-	Status      BookingStatus `gorm:"size:20;default:pending" json:"status"`
+	BookingID   uint          `gorm:"not null;index:idx_booking_status_history_booking_id" json:"booking_id"`
+	FromStatus  BookingStatus `gorm:"size:20" json:"from_status"`
+	ToStatus    BookingStatus `gorm:"size:20;not null" json:"to_status"`
+	ChangedByID *uint         `json:"changed_by_id"`
+	ChangedBy   *User         `gorm:"foreignKey:ChangedByID" json:"changed_by,omitempty"`
+	Reason      string        `gorm:"size:500" json:"reason"`
 	CreatedAt   time.Time     `json:"created_at"`
-	UpdatedAt   time.Time     `json:"updated_at"`
-	DeletedAt   time.Time     `json:"_" gorm:"index"`
 }
 
 func (b *Booking) BeforeCreate(tx *gorm.DB) (err error) {
 	if b.BookingDate.IsZero() {
 		b.BookingDate = time.Now()
 	}
+	if b.Quantity < 1 {
+		b.Quantity = 1
+	}
 	return nil
 }
@@ -0,0 +1,33 @@
+package models
+
+import "testing"
+
+func TestBookingStatusCanTransitionTo(t *testing.T) {
+	statuses := []BookingStatus{
+		BookingStatusPending, BookingStatusConfirmed, BookingStatusCancelled,
+		BookingStatusAttended, BookingStatusNoShow, BookingStatusRefunded,
+	}
+
+	// Every pair not listed here must be refused
+	legal := map[BookingStatus][]BookingStatus{
+		BookingStatusPending:   {BookingStatusConfirmed, BookingStatusCancelled},
+		BookingStatusConfirmed: {BookingStatusCancelled, BookingStatusAttended, BookingStatusNoShow, BookingStatusRefunded},
+		BookingStatusCancelled: {BookingStatusRefunded},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := false
+			for _, allowed := range legal[from] {
+				want = want || allowed == to
+			}
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s -> %s: CanTransitionTo = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+
+	if BookingStatusPending.CanTransitionTo("unknown") || BookingStatus("unknown").CanTransitionTo(BookingStatusConfirmed) {
+		t.Error("transitions to or from an unknown status are allowed")
+	}
+}
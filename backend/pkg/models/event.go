@@ -1,29 +1,252 @@
 package models
 
-import "time"
+import (
+	"time"
 
+	"gorm.io/gorm"
+)
+
+type EventStatus string
+
+const (
+	EventStatusDraft     EventStatus = "draft"
+	EventStatusScheduled EventStatus = "scheduled"
+	EventStatusPublished EventStatus = "published"
+	EventStatusPostponed EventStatus = "postponed"
+	EventStatusCancelled EventStatus = "cancelled"
+)
+
+// eventTransitions is the publication lifecycle: each status maps to the
+// statuses it may move to. Cancelled is final.
+var eventTransitions = map[EventStatus][]EventStatus{
+	EventStatusDraft:     {EventStatusScheduled, EventStatusPublished, EventStatusCancelled},
+	EventStatusScheduled: {EventStatusDraft, EventStatusScheduled, EventStatusPublished, EventStatusCancelled},
+	EventStatusPublished: {EventStatusPostponed, EventStatusCancelled},
+	EventStatusPostponed: {EventStatusPublished, EventStatusCancelled},
+}
+
+// ParseEventStatus converts user input to a known event status
+func ParseEventStatus(value string) (EventStatus, bool) {
+	status := EventStatus(value)
+	switch status {
+	case EventStatusDraft, EventStatusScheduled, EventStatusPublished,
+		EventStatusPostponed, EventStatusCancelled:
+		return status, true
+	}
+	return "", false
+}
+
+// CanTransitionTo reports whether the lifecycle allows moving to next
+func (s EventStatus) CanTransitionTo(next EventStatus) bool {
+	for _, allowed := range eventTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is a bookable event running from EventDate to EndDate, both stored
+// in UTC. Deleted events stay in the trash, with their bookings, until they
+// are restored or purged.
 type Event struct {
-	ID          uint       `gorm:"primarykey" json:"id"`
-	Name        string     `gorm:"size:255;not null;index:idx_events_name" json:"name"`
-	Description string     `gorm:"type:text" json:"description"`
-	CategoryID  uint       `gorm:"index:idx_events_category_id" json:"category_id"`
-	Category    Category   `gorm:"foreignKey:CategoryID" json:"category"`
-	EventDate   time.Time  `gorm:"index:idx_events_event_date" json:"event_date"`
-	Venue       string     `gorm:"size:255" json:"venue"`
-	Price       float64    `json:"price"`
-	ImageURL    string     `gorm:"size:255" json:"image_url"`
-	Tags        []Tag      `gorm:"many2many:event_tags;" json:"tags"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	DeletedAt   *time.Time `gorm:"index" json:"-"`
+	ID          uint     `gorm:"primarykey" json:"id"`
+	Name        string   `gorm:"size:255;not null;index:idx_events_name" json:"name"`
+	Description string   `gorm:"type:text" json:"description"`
+	CategoryID  uint     `gorm:"index:idx_events_category_id" json:"category_id"`
+	Category    Category `gorm:"foreignKey:CategoryID" json:"category"`
+	// OrganizationID is the organization that runs the event; events
+	// without one belong to the platform
+	OrganizationID *uint     `gorm:"index:idx_events_organization_id" json:"organization_id"`
+	EventDate      time.Time `gorm:"index:idx_events_event_date" json:"event_date"`
+	EndDate        time.Time `gorm:"index:idx_events_end_date" json:"end_date"`
+	// TimeZone is the IANA zone the event is held in, used to present
+	// local times
+	TimeZone string `gorm:"size:64;not null;default:UTC" json:"time_zone"`
+	// Status drives publication: scheduled events go live at PublishAt
+	Status    EventStatus `gorm:"size:20;not null;default:published;index:idx_events_status" json:"status"`
+	PublishAt *time.Time  `gorm:"index" json:"publish_at"`
+	// StatusNote tells attendees why an event was postponed or cancelled
+	StatusNote string `gorm:"size:500" json:"status_note"`
+	// Venue holds the venue name for display; VenueID links the event to a
+	// Venue record when it is held at a known place
+	Venue        string  `gorm:"size:255" json:"venue"`
+	VenueID      *uint   `gorm:"index:idx_events_venue_id" json:"venue_id"`
+	VenueDetails *Venue  `gorm:"foreignKey:VenueID" json:"venue_details,omitempty"`
+	Price        float64 `json:"price"`
+	// A Capacity or MaxTicketsPerUser of zero means the limit is not
+	// enforced
+	Capacity          int          `gorm:"not null;default:0" json:"capacity"`
+	MaxTicketsPerUser int          `gorm:"not null;default:0" json:"max_tickets_per_user"`
+	ImageURL          string       `gorm:"size:255" json:"image_url"`
+	Tags              []Tag        `gorm:"many2many:event_tags;" json:"tags"`
+	TicketTypes       []TicketType `gorm:"foreignKey:EventID" json:"ticket_types,omitempty"`
+	// Occurrences of an EventSeries carry the series ID and the
+	// OccurrenceDate the rule generated them for
+	SeriesID       *uint      `gorm:"index:idx_events_series_id" json:"series_id"`
+	OccurrenceDate *time.Time `json:"occurrence_date"`
+	// SeriesOverride marks an occurrence edited on its own, which
+	// series-wide edits then leave alone
+	SeriesOverride bool           `gorm:"not null;default:false" json:"series_override"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+	// Translations hold the name and description in other languages
+	Translations []EventTranslation `gorm:"foreignKey:EventID" json:"translations,omitempty"`
+}
+
+// EventTranslation is an event's name and description in one locale.
+// Fields left empty fall back to the event's own.
+type EventTranslation struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	EventID     uint      `gorm:"not null;uniqueIndex:idx_event_translations_event_locale" json:"event_id"`
+	Locale      string    `gorm:"size:10;not null;uniqueIndex:idx_event_translations_event_locale" json:"locale"`
+	Name        string    `gorm:"size:255" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Translation returns the event's translation into locale, if it has one
+func (e *Event) Translation(locale string) *EventTranslation {
+	for i := range e.Translations {
+		if e.Translations[i].Locale == locale {
+			return &e.Translations[i]
+		}
+	}
+	return nil
+}
+
+// IsLive reports whether the event has been published at the given time,
+// counting scheduled events whose publish time has passed
+func (e *Event) IsLive(at time.Time) bool {
+	switch e.Status {
+	case EventStatusPublished, EventStatusPostponed:
+		return true
+	case EventStatusScheduled:
+		return e.PublishAt != nil && !e.PublishAt.After(at)
+	}
+	return false
+}
+
+// IsBookable reports whether new bookings can be taken for the event
+func (e *Event) IsBookable(at time.Time) bool {
+	return e.IsLive(at) && e.Status != EventStatusPostponed
+}
+
+// Location returns the event's time zone, falling back to UTC when it is
+// unset or unknown
+func (e *Event) Location() *time.Location {
+	return LoadLocation(e.TimeZone)
 }
 
+// Duration returns how long the event runs
+func (e *Event) Duration() time.Duration {
+	if e.EndDate.Before(e.EventDate) {
+		return 0
+	}
+	return e.EndDate.Sub(e.EventDate)
+}
+
+// LoadLocation resolves an IANA time zone name, falling back to UTC
+func LoadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// TicketType is a priced tier of an event (General, VIP, Early Bird...). A
+// Quota of zero means the tier is only limited by the event's capacity, and
+// the optional sale window restricts when the tier can be bought.
+type TicketType struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	EventID      uint       `gorm:"not null;index:idx_ticket_types_event_id" json:"event_id"`
+	Name         string     `gorm:"size:100;not null" json:"name"`
+	Description  string     `gorm:"type:text" json:"description"`
+	Price        float64    `gorm:"not null;default:0" json:"price"`
+	Quota        int        `gorm:"not null;default:0" json:"quota"`
+	SaleStartsAt *time.Time `json:"sale_starts_at"`
+	SaleEndsAt   *time.Time `json:"sale_ends_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// OnSale reports whether the tier can be bought at the given time
+func (t *TicketType) OnSale(at time.Time) bool {
+	if t.SaleStartsAt != nil && at.Before(*t.SaleStartsAt) {
+		return false
+	}
+	if t.SaleEndsAt != nil && !at.Before(*t.SaleEndsAt) {
+		return false
+	}
+	return true
+}
+
+// Category groups events. Categories without an OrganizationID are shared by
+// every organization; the others are private to their organization.
 type Category struct {
-	ID        uint       `gorm:"primarykey" json:"id"`
-	Name      string     `gorm:"size:100;not null;unique" json:"name"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `gorm:"index" json:"-"`
+	ID   uint   `gorm:"primarykey" json:"id"`
+	Name string `gorm:"size:100;not null" json:"name"`
+	// Slug identifies the category in URLs. Its unique index is created by
+	// the migrations once existing categories have been given one.
+	Slug        string `gorm:"size:120" json:"slug"`
+	Description string `gorm:"type:text" json:"description"`
+	// ParentID nests the category under another one; top-level categories
+	// have none
+	ParentID       *uint          `gorm:"index:idx_categories_parent_id" json:"parent_id"`
+	SortOrder      int            `gorm:"default:0" json:"sort_order"`
+	IconURL        string         `gorm:"size:255" json:"icon_url"`
+	OrganizationID *uint          `gorm:"index:idx_categories_organization_id" json:"organization_id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+	// Translations hold the name and description in other languages
+	Translations []CategoryTranslation `gorm:"foreignKey:CategoryID" json:"translations,omitempty"`
+}
+
+// CategoryTranslation is a category's name and description in one locale.
+// Fields left empty fall back to the category's own.
+type CategoryTranslation struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CategoryID  uint      `gorm:"not null;uniqueIndex:idx_category_translations_category_locale" json:"category_id"`
+	Locale      string    `gorm:"size:10;not null;uniqueIndex:idx_category_translations_category_locale" json:"locale"`
+	Name        string    `gorm:"size:100" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Localized returns a copy of the category with its name and description in
+// locale where translated. The copy carries no translations of its own.
+func (c Category) Localized(locale string) Category {
+	for _, translation := range c.Translations {
+		if translation.Locale != locale {
+			continue
+		}
+		if translation.Name != "" {
+			c.Name = translation.Name
+		}
+		if translation.Description != "" {
+			c.Description = translation.Description
+		}
+		break
+	}
+	c.Translations = nil
+	return c
+}
+
+// Localize lets a category be answered in the locale of a request
+func (c *Category) Localize(locale string) any {
+	if c == nil {
+		return c
+	}
+	localized := c.Localized(locale)
+	return &localized
 }
 
 type Tag struct {
@@ -0,0 +1,35 @@
+package models
+
+import "testing"
+
+func TestEventStatusCanTransitionTo(t *testing.T) {
+	statuses := []EventStatus{
+		EventStatusDraft, EventStatusScheduled, EventStatusPublished,
+		EventStatusPostponed, EventStatusCancelled,
+	}
+
+	// Every pair not listed here must be refused. A scheduled event may be
+	// scheduled again to move its publish time.
+	legal := map[EventStatus][]EventStatus{
+		EventStatusDraft:     {EventStatusScheduled, EventStatusPublished, EventStatusCancelled},
+		EventStatusScheduled: {EventStatusDraft, EventStatusScheduled, EventStatusPublished, EventStatusCancelled},
+		EventStatusPublished: {EventStatusPostponed, EventStatusCancelled},
+		EventStatusPostponed: {EventStatusPublished, EventStatusCancelled},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := false
+			for _, allowed := range legal[from] {
+				want = want || allowed == to
+			}
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s -> %s: CanTransitionTo = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+
+	if EventStatusDraft.CanTransitionTo("unknown") || EventStatus("unknown").CanTransitionTo(EventStatusPublished) {
+		t.Error("transitions to or from an unknown status are allowed")
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Login throttles are kept per account and per client address. Password
+// reset requests are counted per email in the same table.
+const (
+	LoginThrottleEmail = "email"
+	LoginThrottleIP    = "ip"
+	LoginThrottleReset = "reset"
+)
+
+// LoginThrottle counts recent failed logins for an email address or a client
+// IP. Accounts are tracked by the email that was typed in, whether or not it
+// is registered, so throttling behaves the same for unknown emails and does
+// not give away who has an account.
+type LoginThrottle struct {
+	Scope         string     `gorm:"primaryKey;size:10" json:"scope"`
+	Subject       string     `gorm:"primaryKey;size:100" json:"subject"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `gorm:"not null;index:idx_login_throttles_last_failure_at" json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Organization is a tenant of the platform. Its organizers manage the
+// organization's own events, categories and attendee lists.
+type Organization struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	Name        string     `gorm:"size:150;not null;unique" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `gorm:"index" json:"-"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// EventSeries is a recurring event described by an RFC 5545 RRULE starting at
+// StartDate. Every occurrence is stored as a concrete Event row pointing back
+// to the series, and the series fields act as the template for those rows.
+// The rule is expanded in TimeZone, so occurrences keep their wall-clock
+// time across daylight saving changes. ExDates lists occurrence start times
+// that were removed from the series. Occurrences belong to the series'
+// organization.
+type EventSeries struct {
+	ID                uint        `gorm:"primarykey" json:"id"`
+	Name              string      `gorm:"size:255;not null" json:"name"`
+	Description       string      `gorm:"type:text" json:"description"`
+	CategoryID        uint        `gorm:"index" json:"category_id"`
+	Category          Category    `gorm:"foreignKey:CategoryID" json:"category"`
+	OrganizationID    *uint       `gorm:"index" json:"organization_id"`
+	StartDate         time.Time   `gorm:"not null" json:"start_date"`
+	DurationMinutes   int         `gorm:"not null;default:0" json:"duration_minutes"`
+	TimeZone          string      `gorm:"size:64;not null;default:UTC" json:"time_zone"`
+	RRule             string      `gorm:"size:500;not null" json:"rrule"`
+	ExDates           []time.Time `gorm:"type:text;serializer:json" json:"exdates"`
+	Venue             string      `gorm:"size:255" json:"venue"`
+	VenueID           *uint       `json:"venue_id"`
+	Price             float64     `json:"price"`
+	Capacity          int         `gorm:"not null;default:0" json:"capacity"`
+	MaxTicketsPerUser int         `gorm:"not null;default:0" json:"max_tickets_per_user"`
+	Tags              []string    `gorm:"type:text;serializer:json" json:"tags"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+	DeletedAt         *time.Time  `gorm:"index" json:"-"`
+}
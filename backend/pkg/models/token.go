@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// RefreshToken lets a client obtain a new access token without logging in
+// again. Only a hash of the token is stored. Each use replaces the token
+// with a new one from the same family, the chain of tokens descending from
+// one login, so a token that turns up again after being replaced has been
+// stolen and the whole family is revoked.
+type RefreshToken struct {
+	ID        uint   `gorm:"primarykey" json:"id"`
+	UserID    uint   `gorm:"not null;index:idx_refresh_tokens_user_id" json:"user_id"`
+	FamilyID  string `gorm:"size:64;not null;index:idx_refresh_tokens_family_id" json:"family_id"`
+	TokenHash string `gorm:"size:64;not null;uniqueIndex:idx_refresh_tokens_token_hash" json:"-"`
+	// AccessTokenID is the jti of the access token issued alongside, which
+	// is revoked with the family
+	AccessTokenID   string     `gorm:"size:64;not null" json:"-"`
+	AccessExpiresAt time.Time  `json:"-"`
+	ExpiresAt       time.Time  `gorm:"not null;index:idx_refresh_tokens_expires_at" json:"expires_at"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty"`
+	ReplacedByID    *uint      `json:"replaced_by_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// PasswordResetToken lets a user who forgot their password set a new one.
+// Like refresh tokens only a hash is stored, and each token works once.
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"not null;index:idx_password_reset_tokens_user_id" json:"user_id"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex:idx_password_reset_tokens_token_hash" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null;index:idx_password_reset_tokens_expires_at" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RevokedToken is an access token that may no longer be used even though it
+// has not expired. Entries can be dropped once the token expires.
+type RevokedToken struct {
+	TokenID   string    `gorm:"primarykey;size:64" json:"token_id"`
+	ExpiresAt time.Time `gorm:"not null;index:idx_revoked_tokens_expires_at" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -10,18 +10,47 @@ import (
 type Role string
 
 const (
-	RoleAdmin Role = "admin"
-	RoleUser  Role = "user"
+	RoleAdmin     Role = "admin"
+	RoleOrganizer Role = "organizer"
+	RoleUser      Role = "user"
 )
 
+// ParseRole converts user input to a known role
+func ParseRole(value string) (Role, bool) {
+	role := Role(value)
+	switch role {
+	case RoleAdmin, RoleOrganizer, RoleUser:
+		return role, true
+	}
+	return "", false
+}
+
+// User is an account on the platform. Organizers belong to the Organization
+// whose events they manage; admins and regular users have none.
 type User struct {
-	ID        uint      `gorm:"primarykey" json:"id"`
-	Name      string    `gorm:"size:100;not null" json:"name"`
-	Email     string    `gorm:"size:100;not null;unique;index:idx_users_email" json:"email"`
-	Password  string    `gorm:"size:100;not null" json:"-"`
-	Role      Role      `gorm:"size:10;not null;default:user;index:idx_users_role" json:"role"`
-	CreateAt  time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             uint          `gorm:"primarykey" json:"id"`
+	Name           string        `gorm:"size:100;not null" json:"name"`
+	Email          string        `gorm:"size:100;not null;unique;index:idx_users_email" json:"email"`
+	Password       string        `gorm:"size:100;not null" json:"-"`
+	Role           Role          `gorm:"size:10;not null;default:user;index:idx_users_role" json:"role"`
+	OrganizationID *uint         `gorm:"index:idx_users_organization_id" json:"organization_id"`
+	Organization   *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+	CreateAt       time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	// EmailVerifiedAt is set once the user follows the link sent to their
+	// email. Unverified users cannot book.
+	EmailVerifiedAt    *time.Time `json:"email_verified_at"`
+	VerificationSentAt *time.Time `json:"-"`
+	Phone              string     `gorm:"size:20" json:"phone"`
+	AvatarURL          string     `gorm:"size:255" json:"avatar_url"`
+	// AnonymizedAt is set when the user deleted their account. The row stays
+	// so their bookings remain on record, stripped of personal data.
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+}
+
+// IsEmailVerified reports whether the user confirmed their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 // HashPassword Method to hash users passwords
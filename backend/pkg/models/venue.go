@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Venue is a reusable place where events are held. DefaultCapacity seeds the
+// capacity of events created at the venue when none is given.
+type Venue struct {
+	ID                 uint       `gorm:"primarykey" json:"id"`
+	Name               string     `gorm:"size:255;not null" json:"name"`
+	Address            string     `gorm:"size:500;not null" json:"address"`
+	City               string     `gorm:"size:100;index" json:"city"`
+	Country            string     `gorm:"size:100" json:"country"`
+	Latitude           float64    `gorm:"not null;index:idx_venues_location" json:"latitude"`
+	Longitude          float64    `gorm:"not null;index:idx_venues_location" json:"longitude"`
+	DefaultCapacity    int        `gorm:"not null;default:0" json:"default_capacity"`
+	AccessibilityNotes string     `gorm:"type:text" json:"accessibility_notes"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	DeletedAt          *time.Time `gorm:"index" json:"-"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type WaitlistStatus string
+
+const (
+	WaitlistStatusWaiting WaitlistStatus = "waiting"
+	WaitlistStatusOffered WaitlistStatus = "offered"
+	WaitlistStatusClaimed WaitlistStatus = "claimed"
+	WaitlistStatusExpired WaitlistStatus = "expired"
+	WaitlistStatusLeft    WaitlistStatus = "left"
+)
+
+// WaitlistEntry queues a user for a sold-out event. Entries are served in ID
+// order; an offered entry holds a seat until OfferExpiresAt.
+type WaitlistEntry struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	UserID         uint           `gorm:"index:idx_waitlist_user" json:"user_id"`
+	User           User           `gorm:"foreignKey:UserID" json:"user"`
+	EventID        uint           `gorm:"index:idx_waitlist_event_status" json:"event_id"`
+	Event          Event          `gorm:"foreignKey:EventID" json:"event"`
+	Status         WaitlistStatus `gorm:"size:20;not null;default:waiting;index:idx_waitlist_event_status" json:"status"`
+	OfferedAt      *time.Time     `json:"offered_at"`
+	OfferExpiresAt *time.Time     `gorm:"index" json:"offer_expires_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
@@ -2,34 +2,55 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/robaa12/mawid/pkg/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookingRepository struct {
 	DB *gorm.DB
 }
 
+// holdsSeat restricts a query to bookings that occupy seats: sold bookings
+// and pending holds that have not expired yet
+func holdsSeat(db *gorm.DB) *gorm.DB {
+	return db.Where("(status IN ? OR (status = ? AND hold_expires_at > ?))",
+		[]models.BookingStatus{models.BookingStatusConfirmed, models.BookingStatusAttended, models.BookingStatusNoShow},
+		models.BookingStatusPending, time.Now())
+}
+
 func NewBookingRepository(db *gorm.DB) *BookingRepository {
 	return &BookingRepository{
 		DB: db,
 	}
 }
 
+// WithTx returns a repository that runs its queries inside the given transaction
+func (r *BookingRepository) WithTx(tx *gorm.DB) *BookingRepository {
+	return &BookingRepository{DB: tx}
+}
+
 func (r *BookingRepository) Create(booking *models.Booking) error {
 	return r.DB.Create(booking).Error
 }
 
 func (r *BookingRepository) GetByID(id uint) (*models.Booking, error) {
 	var booking models.Booking
-	if err := r.DB.Preload("Event").Preload("Event.Category").Preload("User").First(&booking, id).Error; err != nil {
+	if err := r.DB.Preload("Event").Preload("Event.Category").Preload("User").Preload("TicketType").Preload("Attendees").First(&booking, id).Error; err != nil {
 		return nil, err
 	}
 
 	return &booking, nil
 }
 
+// bookingKeyset lists the most recent bookings first
+var bookingKeyset = keyset{name: "recent", keys: []sortKey{
+	{expr: "bookings.created_at", desc: true, kind: keyTime},
+	{expr: "bookings.id", desc: true, kind: keyInt},
+}}
+
 func (r *BookingRepository) GetUserBookings(userID uint, page, pageSize int) ([]models.Booking, int64, error) {
 	var booking []models.Booking
 	var total int64
@@ -42,9 +63,11 @@ func (r *BookingRepository) GetUserBookings(userID uint, page, pageSize int) ([]
 	query := r.DB.Where("user_id = ?", userID).
 		Preload("Event").
 		Preload("Event.Category").
+		Preload("TicketType").
+		Preload("Attendees").
 		Offset(offset).
 		Limit(pageSize).
-		Order("created_at DESC")
+		Order("created_at DESC, id DESC")
 
 	if err := query.Find(&booking).Error; err != nil {
 		return nil, 0, err
@@ -53,6 +76,42 @@ func (r *BookingRepository) GetUserBookings(userID uint, page, pageSize int) ([]
 	return booking, total, nil
 }
 
+// GetUserBookingsByCursor is GetUserBookings with keyset pagination
+func (r *BookingRepository) GetUserBookingsByCursor(userID uint, cursor *Cursor, limit int) ([]models.Booking, CursorPage, error) {
+	query := r.DB.Model(&models.Booking{}).Where("bookings.user_id = ?", userID)
+	ids, page, err := bookingKeyset.fetch(query, cursor, limit, Cursor{Order: bookingKeyset.name})
+	if err != nil {
+		return nil, CursorPage{}, err
+	}
+
+	bookings, err := r.getBookingsInOrder(ids, r.DB.Preload("Event").Preload("Event.Category").Preload("TicketType").Preload("Attendees"))
+	if err != nil {
+		return nil, CursorPage{}, err
+	}
+	return bookings, page, nil
+}
+
+// getBookingsInOrder loads bookings through query, which sets up their
+// associations, keeping the order of ids
+func (r *BookingRepository) getBookingsInOrder(ids []uint, query *gorm.DB) ([]models.Booking, error) {
+	var found []models.Booking
+	if err := query.Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Booking, len(found))
+	for _, booking := range found {
+		byID[booking.ID] = booking
+	}
+	bookings := make([]models.Booking, 0, len(found))
+	for _, id := range ids {
+		if booking, ok := byID[id]; ok {
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings, nil
+}
+
 func (r *BookingRepository) GetEventBookings(eventID uint, page, pageSize int) ([]models.Booking, int64, error) {
 	var bookings []models.Booking
 	var total int64
@@ -64,6 +123,8 @@ func (r *BookingRepository) GetEventBookings(eventID uint, page, pageSize int) (
 	offset := (page - 1) * pageSize
 	query := r.DB.Where("event_id = ?", eventID).
 		Preload("User").
+		Preload("TicketType").
+		Preload("Attendees").
 		Offset(offset).
 		Limit(pageSize).
 		Order("created_at DESC")
@@ -78,7 +139,8 @@ func (r *BookingRepository) GetEventBookings(eventID uint, page, pageSize int) (
 func (r *BookingRepository) CheckUserBooking(userID, eventID uint) (bool, *models.Booking, error) {
 	var booking models.Booking
 
-	result := r.DB.Where("user_id = ? AND event_id = ? AND status != ?", userID, eventID, models.BookingStatusCancelled).First(&booking)
+	result := r.DB.Where("user_id = ? AND event_id = ? AND status NOT IN ?", userID, eventID,
+		[]models.BookingStatus{models.BookingStatusCancelled, models.BookingStatusRefunded}).First(&booking)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -89,36 +151,424 @@ func (r *BookingRepository) CheckUserBooking(userID, eventID uint) (bool, *model
 	return true, &booking, nil // Booking found
 }
 
-func (r *BookingRepository) UpdateStatus(id uint, status models.BookingStatus) error {
-	return r.DB.Model(&models.Booking{}).Where("id = ?", id).Update("status", status).Error
+// CountActiveSeats returns the number of seats currently held for an event
+func (r *BookingRepository) CountActiveSeats(eventID uint) (int64, error) {
+	var seats int64
+	err := r.DB.Model(&models.Booking{}).
+		Scopes(holdsSeat).
+		Where("event_id = ?", eventID).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&seats).Error
+	return seats, err
+}
+
+// CountUserActiveSeats returns the number of seats a user currently holds for an event
+func (r *BookingRepository) CountUserActiveSeats(eventID, userID uint) (int64, error) {
+	var seats int64
+	err := r.DB.Model(&models.Booking{}).
+		Scopes(holdsSeat).
+		Where("event_id = ? AND user_id = ?", eventID, userID).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&seats).Error
+	return seats, err
+}
+
+// CountActiveSeatsByEvents returns the seats held per event for the given events
+func (r *BookingRepository) CountActiveSeatsByEvents(eventIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(eventIDs))
+	if len(eventIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		EventID uint
+		Seats   int64
+	}
+	err := r.DB.Model(&models.Booking{}).
+		Select("event_id, COALESCE(SUM(quantity), 0) AS seats").
+		Scopes(holdsSeat).
+		Where("event_id IN ?", eventIDs).
+		Group("event_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.EventID] = row.Seats
+	}
+	return counts, nil
+}
+
+// CountActiveSeatsByTicketType returns the number of seats currently held in a ticket tier
+func (r *BookingRepository) CountActiveSeatsByTicketType(ticketTypeID uint) (int64, error) {
+	var seats int64
+	err := r.DB.Model(&models.Booking{}).
+		Scopes(holdsSeat).
+		Where("ticket_type_id = ?", ticketTypeID).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&seats).Error
+	return seats, err
+}
+
+// CountActiveSeatsByTicketTypes returns the seats held per tier for the given ticket types
+func (r *BookingRepository) CountActiveSeatsByTicketTypes(ticketTypeIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(ticketTypeIDs))
+	if len(ticketTypeIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		TicketTypeID uint
+		Seats        int64
+	}
+	err := r.DB.Model(&models.Booking{}).
+		Select("ticket_type_id, COALESCE(SUM(quantity), 0) AS seats").
+		Scopes(holdsSeat).
+		Where("ticket_type_id IN ?", ticketTypeIDs).
+		Group("ticket_type_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.TicketTypeID] = row.Seats
+	}
+	return counts, nil
+}
+
+// HasTicketTypeBookings reports whether any booking, active or not, references a ticket type
+func (r *BookingRepository) HasTicketTypeBookings(ticketTypeID uint) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.Booking{}).Where("ticket_type_id = ?", ticketTypeID).Count(&count).Error
+	return count > 0, err
+}
+
+// TransitionStatus moves a booking from one status to another. It reports
+// false if the booking was no longer in the expected status.
+func (r *BookingRepository) TransitionStatus(id uint, from, to models.BookingStatus) (bool, error) {
+	result := r.DB.Model(&models.Booking{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *BookingRepository) AddStatusHistory(entry *models.BookingStatusHistory) error {
+	return r.DB.Create(entry).Error
+}
+
+func (r *BookingRepository) GetStatusHistory(bookingID uint) ([]models.BookingStatusHistory, error) {
+	var history []models.BookingStatusHistory
+	err := r.DB.Where("booking_id = ?", bookingID).
+		Preload("ChangedBy").
+		Order("created_at ASC, id ASC").
+		Find(&history).Error
+	return history, err
+}
+
+// ConfirmHold finalizes a pending booking whose hold has not expired. It
+// reports false when the booking is no longer holding its seats.
+func (r *BookingRepository) ConfirmHold(id uint) (bool, error) {
+	result := r.DB.Model(&models.Booking{}).
+		Where("id = ? AND status = ? AND hold_expires_at > ?", id, models.BookingStatusPending, time.Now()).
+		Updates(map[string]any{
+			"status":          models.BookingStatusConfirmed,
+			"hold_expires_at": nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReleaseExpiredHolds cancels lapsed pending bookings, records the change in
+// their status history and returns the IDs of the events that got seats back.
+// Pass a zero eventID to sweep every event.
+func (r *BookingRepository) ReleaseExpiredHolds(eventID uint) ([]uint, error) {
+	var released []models.Booking
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&released).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "event_id"}}}).
+			Where("status = ? AND hold_expires_at <= ?", models.BookingStatusPending, time.Now())
+		if eventID != 0 {
+			query = query.Where("event_id = ?", eventID)
+		}
+
+		if err := query.Update("status", models.BookingStatusCancelled).Error; err != nil {
+			return err
+		}
+		if len(released) == 0 {
+			return nil
+		}
+
+		history := make([]models.BookingStatusHistory, 0, len(released))
+		for _, b := range released {
+			history = append(history, models.BookingStatusHistory{
+				BookingID:  b.ID,
+				FromStatus: models.BookingStatusPending,
+				ToStatus:   models.BookingStatusCancelled,
+				Reason:     "seat hold expired",
+			})
+		}
+		return tx.Create(&history).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uint]bool, len(released))
+	eventIDs := make([]uint, 0, len(released))
+	for _, b := range released {
+		if !seen[b.EventID] {
+			seen[b.EventID] = true
+			eventIDs = append(eventIDs, b.EventID)
+		}
+	}
+	return eventIDs, nil
+}
+
+// CancelEventBookings cancels every pending or confirmed booking of an event
+// and records why in each booking's history. It returns how many were
+// cancelled.
+func (r *BookingRepository) CancelEventBookings(eventID uint, changedByID *uint, reason string) (int, error) {
+	cancelled := 0
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		for _, from := range []models.BookingStatus{models.BookingStatusPending, models.BookingStatusConfirmed} {
+			var bookings []models.Booking
+			err := tx.Model(&bookings).
+				Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+				Where("event_id = ? AND status = ?", eventID, from).
+				Update("status", models.BookingStatusCancelled).Error
+			if err != nil {
+				return err
+			}
+			if len(bookings) == 0 {
+				continue
+			}
+
+			history := make([]models.BookingStatusHistory, 0, len(bookings))
+			for _, b := range bookings {
+				history = append(history, models.BookingStatusHistory{
+					BookingID:   b.ID,
+					FromStatus:  from,
+					ToStatus:    models.BookingStatusCancelled,
+					ChangedByID: changedByID,
+					Reason:      reason,
+				})
+			}
+			if err := tx.Create(&history).Error; err != nil {
+				return err
+			}
+			cancelled += len(bookings)
+		}
+		return nil
+	})
+
+	return cancelled, err
+}
+
+// CancelUserBookings cancels a user's pending and confirmed bookings for
+// events starting after the given time and records why in each booking's
+// history. It returns how many were cancelled.
+func (r *BookingRepository) CancelUserBookings(userID uint, after time.Time, reason string) (int, error) {
+	cancelled := 0
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		for _, from := range []models.BookingStatus{models.BookingStatusPending, models.BookingStatusConfirmed} {
+			var bookings []models.Booking
+			err := tx.Model(&bookings).
+				Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+				Where("user_id = ? AND status = ?", userID, from).
+				Where("event_id IN (SELECT id FROM events WHERE event_date > ?)", after).
+				Update("status", models.BookingStatusCancelled).Error
+			if err != nil {
+				return err
+			}
+			if len(bookings) == 0 {
+				continue
+			}
+
+			history := make([]models.BookingStatusHistory, 0, len(bookings))
+			for _, b := range bookings {
+				history = append(history, models.BookingStatusHistory{
+					BookingID:   b.ID,
+					FromStatus:  from,
+					ToStatus:    models.BookingStatusCancelled,
+					ChangedByID: &userID,
+					Reason:      reason,
+				})
+			}
+			if err := tx.Create(&history).Error; err != nil {
+				return err
+			}
+			cancelled += len(bookings)
+		}
+		return nil
+	})
+
+	return cancelled, err
+}
+
+// AnonymizeAttendees replaces the name and email of the attendees that are
+// the user, on bookings deleted or not
+func (r *BookingRepository) AnonymizeAttendees(userID uint, name string) error {
+	return r.DB.Model(&models.Attendee{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{"name": name, "email": ""}).Error
 }
 
 func (r *BookingRepository) Delete(id uint) error {
+	if err := r.DB.Where("booking_id = ?", id).Delete(&models.Attendee{}).Error; err != nil {
+		return err
+	}
+	if err := r.DB.Where("booking_id = ?", id).Delete(&models.BookingStatusHistory{}).Error; err != nil {
+		return err
+	}
 	return r.DB.Delete(&models.Booking{}, id).Error
 }
 
-func (r *BookingRepository) DeleteBookingsByEvent(id uint) error {
-	result := r.DB.Where("event_id = ?", id).Delete(&models.Booking{})
+// SoftDeleteByEvent moves an event's bookings to the trash, stamped with the
+// same time as the event so they can be restored with it
+func (r *BookingRepository) SoftDeleteByEvent(eventID uint, at time.Time) (int64, error) {
+	result := r.DB.Model(&models.Booking{}).Where("event_id = ?", eventID).Update("deleted_at", at)
+	return result.RowsAffected, result.Error
+}
 
-	if result.Error != nil {
-		return result.Error
+// RestoreByEvent brings back the bookings that were moved to the trash
+// together with their event. It must run before the event is restored.
+func (r *BookingRepository) RestoreByEvent(eventID uint) (int64, error) {
+	result := r.DB.Unscoped().Model(&models.Booking{}).
+		Where("event_id = ? AND deleted_at = (SELECT deleted_at FROM events WHERE id = ?)", eventID, eventID).
+		Update("deleted_at", nil)
+	return result.RowsAffected, result.Error
+}
+
+// GetDeletedBookings lists bookings in the trash, most recently deleted
+// first. When organizationID is set only bookings for that organization's
+// events are listed.
+func (r *BookingRepository) GetDeletedBookings(page, pageSize int, organizationID *uint) ([]models.Booking, int64, error) {
+	var bookings []models.Booking
+	var total int64
+
+	query := r.DB.Unscoped().Model(&models.Booking{}).Where("deleted_at IS NOT NULL")
+	if organizationID != nil {
+		query = query.Where("event_id IN (?)",
+			r.DB.Unscoped().Model(&models.Event{}).Select("id").Where("organization_id = ?", *organizationID))
 	}
 
-	return nil
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("User").
+		Preload("Event", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("TicketType").
+		Preload("Attendees").
+		Order("deleted_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&bookings).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return bookings, total, nil
 }
 
-func (r *BookingRepository) GetAllBookings(page, pageSize int) ([]models.Booking, error) {
+// GetDeletedBooking loads a booking from the trash
+func (r *BookingRepository) GetDeletedBooking(id uint) (*models.Booking, error) {
+	var booking models.Booking
+	err := r.DB.Unscoped().Where("deleted_at IS NOT NULL").
+		Preload("Event", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		First(&booking, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// Restore takes a booking out of the trash
+func (r *BookingRepository) Restore(id uint) error {
+	return r.DB.Unscoped().Model(&models.Booking{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// PurgeByEvent removes every booking of an event for good, with its
+// attendees and status history
+func (r *BookingRepository) PurgeByEvent(eventID uint) (int64, error) {
+	return r.purge(r.DB.Unscoped().Model(&models.Booking{}).Select("id").Where("event_id = ?", eventID))
+}
+
+// PurgeDeleted removes bookings that have been in the trash since before the
+// cutoff for good
+func (r *BookingRepository) PurgeDeleted(before time.Time) (int64, error) {
+	return r.purge(r.DB.Unscoped().Model(&models.Booking{}).Select("id").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before))
+}
+
+func (r *BookingRepository) purge(bookingIDs *gorm.DB) (int64, error) {
+	if err := r.DB.Where("booking_id IN (?)", bookingIDs).Delete(&models.Attendee{}).Error; err != nil {
+		return 0, err
+	}
+	if err := r.DB.Where("booking_id IN (?)", bookingIDs).Delete(&models.BookingStatusHistory{}).Error; err != nil {
+		return 0, err
+	}
+
+	result := r.DB.Unscoped().Where("id IN (?)", bookingIDs).Delete(&models.Booking{})
+	return result.RowsAffected, result.Error
+}
+
+// GetAllBookings lists bookings newest first. When organizationID is set only
+// bookings for that organization's events are returned.
+func (r *BookingRepository) GetAllBookings(page, pageSize int, organizationID *uint) ([]models.Booking, int64, error) {
 	var bookings []models.Booking
+	var total int64
+
+	scope := r.ofOrganization(organizationID)
+
+	if err := r.DB.Model(&models.Booking{}).Scopes(scope).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
 
 	offset := (page - 1) * pageSize
-	query := r.DB.
+	query := r.DB.Scopes(scope).
+		Preload("User").
+		Preload("Event").
+		Preload("TicketType").
+		Preload("Attendees").
 		Offset(offset).
 		Limit(pageSize).
-		Order("created_at DESC")
+		Order("created_at DESC, id DESC")
 
 	if err := query.Find(&bookings).Error; err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return bookings, nil
+	return bookings, total, nil
+}
+
+// GetAllBookingsByCursor is GetAllBookings with keyset pagination
+func (r *BookingRepository) GetAllBookingsByCursor(cursor *Cursor, limit int, organizationID *uint) ([]models.Booking, CursorPage, error) {
+	query := r.DB.Model(&models.Booking{}).Scopes(r.ofOrganization(organizationID))
+	ids, page, err := bookingKeyset.fetch(query, cursor, limit, Cursor{Order: bookingKeyset.name})
+	if err != nil {
+		return nil, CursorPage{}, err
+	}
+
+	bookings, err := r.getBookingsInOrder(ids, r.DB.Preload("User").Preload("Event").Preload("TicketType").Preload("Attendees"))
+	if err != nil {
+		return nil, CursorPage{}, err
+	}
+	return bookings, page, nil
+}
+
+// ofOrganization limits a booking query to the events of an organization. A
+// nil organization leaves the query unscoped.
+func (r *BookingRepository) ofOrganization(organizationID *uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if organizationID != nil {
+			db = db.Where("bookings.event_id IN (?)",
+				r.DB.Model(&models.Event{}).Select("id").Where("organization_id = ?", *organizationID))
+		}
+		return db
+	}
 }
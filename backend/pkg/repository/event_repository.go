@@ -1,60 +1,118 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/robaa12/mawid/pkg/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type EventRepository struct {
 	DB *gorm.DB
 }
 
+// EventFilter narrows event listings. PublicOnly keeps to events the public
+// may browse, Status selects a single lifecycle state and OrganizationID
+// limits the listing to one organization's events. Tags keeps events with
+// any of the named tags, or with all of them when MatchAllTags is set.
+// From and To keep events taking place during that window, and the price
+// bounds apply to an event's starting price. Sort orders the listing.
+type EventFilter struct {
+	CategoryID     uint
+	Status         models.EventStatus
+	PublicOnly     bool
+	OrganizationID *uint
+	Tags           []string
+	MatchAllTags   bool
+	From           *time.Time
+	To             *time.Time
+	MinPrice       *float64
+	MaxPrice       *float64
+	FreeOnly       bool
+	VenueID        uint
+	Sort           EventSort
+}
+
+// EventSort names an order for event listings. The zero value lists events
+// that have not ended yet first, soonest first.
+type EventSort string
+
+const (
+	SortUpcoming   EventSort = ""
+	SortDate       EventSort = "date"
+	SortPrice      EventSort = "price"
+	SortPriceDesc  EventSort = "price_desc"
+	SortNewest     EventSort = "newest"
+	SortPopularity EventSort = "popularity"
+	// SortRelevance orders search results by rank. Listings without a
+	// search fall back to SortUpcoming.
+	SortRelevance EventSort = "relevance"
+)
+
+// ParseEventSort validates a sort order
+func ParseEventSort(value string) (EventSort, bool) {
+	switch sort := EventSort(value); sort {
+	case SortUpcoming, SortDate, SortPrice, SortPriceDesc, SortNewest, SortPopularity, SortRelevance:
+		return sort, true
+	}
+	return "", false
+}
+
+// startingPrice is the cheapest way into an event: its lowest ticket type
+// price, or the event price when it has no ticket types
+const startingPrice = "COALESCE((SELECT MIN(ticket_types.price) FROM ticket_types WHERE ticket_types.event_id = events.id), events.price)"
+
+// soldSeats counts the seats sold for an event, used to rank popularity
+const soldSeats = "(SELECT COALESCE(SUM(bookings.quantity), 0) FROM bookings WHERE bookings.event_id = events.id " +
+	"AND bookings.deleted_at IS NULL AND bookings.status IN ('confirmed', 'attended', 'no_show'))"
+
+// TagUsage is a tag with the number of listed events carrying it
+type TagUsage struct {
+	models.Tag
+	EventCount int64 `json:"event_count"`
+}
+
 func NewEventRepository(db *gorm.DB) *EventRepository {
 	return &EventRepository{DB: db}
 }
 
+// WithTx returns a repository that runs its queries inside the given transaction
+func (r *EventRepository) WithTx(tx *gorm.DB) *EventRepository {
+	return &EventRepository{DB: tx}
+}
+
 func (r *EventRepository) Create(event *models.Event) error {
 	return r.DB.Create(event).Error
 }
 
-func (r *EventRepository) GetAll(page, pageSize int, categoryID uint) ([]models.Event, int64, error) {
+func (r *EventRepository) GetAll(page, pageSize int, filter EventFilter) ([]models.Event, int64, error) {
 	var events []models.Event
 	var total int64
-	
+
 	// Create base query
-	query := r.DB.Model(&models.Event{})
-	
-	// Apply category filter if provided
-	if categoryID > 0 {
-		query = query.Where("category_id = ?", categoryID)
-	}
-	
+	query := r.DB.Model(&models.Event{}).Scopes(filter.apply)
+
 	// Count total matching records
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
-	
+
 	// Apply pagination and fetch records
 	offset := (page - 1) * pageSize
-	query = r.DB.Preload("Category").Preload("Tags")
-	
-	// Apply category filter again for the actual data query
-	if categoryID > 0 {
-		query = query.Where("category_id = ?", categoryID)
-	}
-	
-	// Get current time for sorting logic
-	currentTime := time.Now()
-	
-	// Sort by upcoming events first, then by date
-	// First separate upcoming from past events, then sort by date
-	now := currentTime.Format("2006-01-02 15:04:05")
-	query = query.Order("CASE WHEN event_date >= '" + now + "' THEN 0 ELSE 1 END")
-	query = query.Order("CASE WHEN event_date >= '" + now + "' THEN event_date END ASC, " + 
-		"CASE WHEN event_date < '" + now + "' THEN event_date END DESC")
-	
+	query = r.DB.Preload("Category.Translations").Preload("VenueDetails").Preload("Tags").Preload("TicketTypes", orderTicketTypes).Preload("Translations")
+
+	// Apply filters again for the actual data query
+	query = query.Scopes(filter.apply)
+
+	// Events count as past only once they have ended, so running events stay
+	// on top of the default order
+	query = orderEvents(query, filter.Sort, time.Now())
+
 	query = query.Offset(offset).Limit(pageSize)
 	if err := query.Find(&events).Error; err != nil {
 		return nil, 0, err
@@ -63,14 +121,149 @@ func (r *EventRepository) GetAll(page, pageSize int, categoryID uint) ([]models.
 	return events, total, nil
 }
 
+// FacetCount is how many listed events share one category or tag
+type FacetCount struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Slug  string `json:"slug,omitempty"`
+	Count int64  `json:"count"`
+}
+
+// PriceBucket counts listed events by starting price. Max is exclusive and
+// missing on the last, open-ended bucket; the free bucket has Min and Max 0.
+type PriceBucket struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max"`
+	Count int64    `json:"count"`
+}
+
+// EventFacets summarizes a listing for building filters. Each dimension is
+// counted with every filter applied except its own, so the counts show what
+// picking another value would return.
+type EventFacets struct {
+	Categories []FacetCount  `json:"categories"`
+	Tags       []FacetCount  `json:"tags"`
+	Prices     []PriceBucket `json:"prices"`
+}
+
+// priceBucketBounds splits paid events into price buckets
+var priceBucketBounds = []float64{100, 250, 500, 1000}
+
+// maxTagFacets caps the tag facet at the most used tags
+const maxTagFacets = 30
+
+func (r *EventRepository) GetFacets(filter EventFilter) (*EventFacets, error) {
+	facets := &EventFacets{Categories: []FacetCount{}, Tags: []FacetCount{}}
+
+	categoryFilter := filter
+	categoryFilter.CategoryID = 0
+	err := r.DB.Model(&models.Event{}).Scopes(categoryFilter.apply).
+		Joins("JOIN categories ON categories.id = events.category_id").
+		Select("categories.id AS id, categories.name AS name, categories.slug AS slug, COUNT(*) AS count").
+		Group("categories.id, categories.name, categories.slug").
+		Order("count DESC, categories.name ASC").
+		Scan(&facets.Categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tagFilter := filter
+	tagFilter.Tags = nil
+	err = r.DB.Model(&models.Event{}).Scopes(tagFilter.apply).
+		Joins("JOIN event_tags ON event_tags.event_id = events.id").
+		Joins("JOIN tags ON tags.id = event_tags.tag_id").
+		Select("tags.id AS id, tags.name AS name, COUNT(*) AS count").
+		Group("tags.id, tags.name").
+		Order("count DESC, tags.name ASC").
+		Limit(maxTagFacets).
+		Scan(&facets.Tags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	priceFilter := filter
+	priceFilter.MinPrice, priceFilter.MaxPrice, priceFilter.FreeOnly = nil, nil, false
+	facets.Prices, err = r.countPriceBuckets(priceFilter)
+	if err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+func (r *EventRepository) countPriceBuckets(filter EventFilter) ([]PriceBucket, error) {
+	// Bucket 0 holds free events, bucket i paid events below bound i and the
+	// last bucket everything from the highest bound up
+	bucket := "CASE WHEN price = 0 THEN 0"
+	for i, bound := range priceBucketBounds {
+		bucket += fmt.Sprintf(" WHEN price < %g THEN %d", bound, i+1)
+	}
+	bucket += fmt.Sprintf(" ELSE %d END", len(priceBucketBounds)+1)
+
+	var rows []struct {
+		Bucket int
+		Count  int64
+	}
+	prices := r.DB.Model(&models.Event{}).Scopes(filter.apply).Select(startingPrice + " AS price")
+	err := r.DB.Table("(?) AS prices", prices).
+		Select(bucket + " AS bucket, COUNT(*) AS count").
+		Group("bucket").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]PriceBucket, len(priceBucketBounds)+2)
+	buckets[0].Max = new(float64)
+	for i := range priceBucketBounds {
+		if i > 0 {
+			buckets[i+1].Min = priceBucketBounds[i-1]
+		}
+		bound := priceBucketBounds[i]
+		buckets[i+1].Max = &bound
+	}
+	buckets[len(buckets)-1].Min = priceBucketBounds[len(priceBucketBounds)-1]
+
+	for _, row := range rows {
+		if row.Bucket >= 0 && row.Bucket < len(buckets) {
+			buckets[row.Bucket].Count = row.Count
+		}
+	}
+	return buckets, nil
+}
+
 func (r *EventRepository) GetEventByID(id uint) (*models.Event, error) {
 	var event models.Event
-	if err := r.DB.Preload("Category").Preload("Tags").First(&event, id).Error; err != nil {
+	if err := r.DB.Preload("Category.Translations").Preload("VenueDetails").Preload("Tags").Preload("TicketTypes", orderTicketTypes).Preload("Translations").First(&event, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+
+}
+
+// GetOrganizationEvent loads an event only if it belongs to the given
+// organization. A nil organization matches every event.
+func (r *EventRepository) GetOrganizationEvent(id uint, organizationID *uint) (*models.Event, error) {
+	var event models.Event
+	err := r.DB.Scopes(inOrganization(organizationID)).
+		Preload("Category.Translations").Preload("VenueDetails").Preload("Tags").Preload("TicketTypes", orderTicketTypes).Preload("Translations").
+		First(&event, id).Error
+	if err != nil {
 		return nil, err
 	}
 
 	return &event, nil
+}
 
+// GetEventForUpdate loads an event and locks its row until the surrounding
+// transaction ends, serializing concurrent bookings for the same event.
+func (r *EventRepository) GetEventForUpdate(id uint) (*models.Event, error) {
+	var event models.Event
+	if err := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &event, nil
 }
 
 func (r *EventRepository) Update(event *models.Event) error {
@@ -81,34 +274,126 @@ func (r *EventRepository) Delete(id uint) error {
 	return r.DB.Delete(&models.Event{}, id).Error
 }
 
-func (r *EventRepository) SearchByName(name string, page, pageSize int) ([]models.Event, int64, error) {
+// SoftDelete moves an event to the trash, stamped with the given time
+func (r *EventRepository) SoftDelete(id uint, at time.Time) error {
+	return r.DB.Model(&models.Event{}).Where("id = ?", id).Update("deleted_at", at).Error
+}
+
+// GetDeletedEvents lists events in the trash, most recently deleted first.
+// When organizationID is set only that organization's events are listed.
+func (r *EventRepository) GetDeletedEvents(page, pageSize int, organizationID *uint) ([]models.Event, int64, error) {
 	var events []models.Event
 	var total int64
 
-	query := r.DB.Model(&models.Event{}).Where("name ILIKE ?", "%"+name+"%")
-
+	query := r.DB.Unscoped().Model(&models.Event{}).Where("deleted_at IS NOT NULL").Scopes(inOrganization(organizationID))
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// Get current time for sorting logic
-	currentTime := time.Now()
-	
-	// Get paginated results with date-based sorting
-	offset := (page - 1) * pageSize
-	queryBuilder := query.Preload("Category").Preload("Tags")
-	now := currentTime.Format("2006-01-02 15:04:05")
-	queryBuilder = queryBuilder.Order("CASE WHEN event_date >= '" + now + "' THEN 0 ELSE 1 END")
-	queryBuilder = queryBuilder.Order("CASE WHEN event_date >= '" + now + "' THEN event_date END ASC, " + 
-		"CASE WHEN event_date < '" + now + "' THEN event_date END DESC")
-	
-	if err := queryBuilder.Offset(offset).Limit(pageSize).Find(&events).Error; err != nil {
+	err := query.Scopes(preloadDeletedEvent).
+		Order("deleted_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&events).Error
+	if err != nil {
 		return nil, 0, err
 	}
 
 	return events, total, nil
 }
 
+// GetDeletedEvent loads an event from the trash, provided it belongs to the
+// given organization. A nil organization matches every event.
+func (r *EventRepository) GetDeletedEvent(id uint, organizationID *uint) (*models.Event, error) {
+	var event models.Event
+	err := r.DB.Unscoped().Where("deleted_at IS NOT NULL").Scopes(inOrganization(organizationID), preloadDeletedEvent).
+		First(&event, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// GetEventsDeletedWith lists the events of a category that were moved to the
+// trash together with it
+func (r *EventRepository) GetEventsDeletedWith(categoryID uint) ([]models.Event, error) {
+	var events []models.Event
+	err := r.DB.Unscoped().
+		Where("category_id = ? AND deleted_at = (SELECT deleted_at FROM categories WHERE id = ?)", categoryID, categoryID).
+		Find(&events).Error
+	return events, err
+}
+
+// Restore takes an event out of the trash
+func (r *EventRepository) Restore(id uint) error {
+	return r.DB.Unscoped().Model(&models.Event{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// GetPurgeableEvents lists events that have been in the trash since before the cutoff
+func (r *EventRepository) GetPurgeableEvents(before time.Time) ([]models.Event, error) {
+	var events []models.Event
+	err := r.DB.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Find(&events).Error
+	return events, err
+}
+
+// Purge removes an event and its tag links, ticket types and translations
+// for good
+func (r *EventRepository) Purge(id uint) error {
+	if err := r.DB.Where("event_id = ?", id).Delete(&models.EventTag{}).Error; err != nil {
+		return err
+	}
+	if err := r.DB.Where("event_id = ?", id).Delete(&models.EventTranslation{}).Error; err != nil {
+		return err
+	}
+	if err := r.DB.Where("event_id = ?", id).Delete(&models.TicketType{}).Error; err != nil {
+		return err
+	}
+	return r.DB.Unscoped().Delete(&models.Event{}, id).Error
+}
+
+func (r *EventRepository) GetEventTranslations(eventID uint) ([]models.EventTranslation, error) {
+	var translations []models.EventTranslation
+	err := r.DB.Where("event_id = ?", eventID).Order("locale ASC").Find(&translations).Error
+	return translations, err
+}
+
+// SaveEventTranslation creates or replaces an event's translation into the
+// translation's locale
+func (r *EventRepository) SaveEventTranslation(translation *models.EventTranslation) error {
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "updated_at"}),
+	}).Create(translation).Error
+}
+
+// DeleteEventTranslation removes an event's translation and reports whether
+// there was one
+func (r *EventRepository) DeleteEventTranslation(eventID uint, locale string) (bool, error) {
+	result := r.DB.Where("event_id = ? AND locale = ?", eventID, locale).Delete(&models.EventTranslation{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *EventRepository) GetCategoryTranslations(categoryID uint) ([]models.CategoryTranslation, error) {
+	var translations []models.CategoryTranslation
+	err := r.DB.Where("category_id = ?", categoryID).Order("locale ASC").Find(&translations).Error
+	return translations, err
+}
+
+// SaveCategoryTranslation creates or replaces a category's translation into
+// the translation's locale
+func (r *EventRepository) SaveCategoryTranslation(translation *models.CategoryTranslation) error {
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "updated_at"}),
+	}).Create(translation).Error
+}
+
+// DeleteCategoryTranslation removes a category's translation and reports
+// whether there was one
+func (r *EventRepository) DeleteCategoryTranslation(categoryID uint, locale string) (bool, error) {
+	result := r.DB.Where("category_id = ? AND locale = ?", categoryID, locale).Delete(&models.CategoryTranslation{})
+	return result.RowsAffected > 0, result.Error
+}
+
 func (r *EventRepository) GetCategoryByID(id uint) (*models.Category, error) {
 	var category models.Category
 	err := r.DB.First(&category, id).Error
@@ -119,31 +404,805 @@ func (r *EventRepository) GetCategoryByID(id uint) (*models.Category, error) {
 
 }
 
+// GetOrganizationCategory loads a category only if it belongs to the given
+// organization. A nil organization matches every category.
+func (r *EventRepository) GetOrganizationCategory(id uint, organizationID *uint) (*models.Category, error) {
+	var category models.Category
+	if err := r.DB.Scopes(inOrganization(organizationID)).First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
 func (r *EventRepository) GetAllCategories() ([]models.Category, error) {
 	var categories []models.Category
-	err := r.DB.Find(&categories).Error
+	err := r.DB.Preload("Translations").Order("sort_order ASC, name ASC").Find(&categories).Error
 	return categories, err
 
 }
 
+func (r *EventRepository) GetCategoryBySlug(slug string) (*models.Category, error) {
+	var category models.Category
+	if err := r.DB.Preload("Translations").Where("slug = ?", slug).First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// CategorySlugExists reports whether another category, trashed ones
+// included, already uses a slug
+func (r *EventRepository) CategorySlugExists(slug string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.DB.Unscoped().Model(&models.Category{}).
+		Where("slug = ? AND id <> ?", slug, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// CountEventsByCategory counts the events filed directly under each category
+func (r *EventRepository) CountEventsByCategory(publicOnly bool) (map[uint]int64, error) {
+	var rows []struct {
+		CategoryID uint
+		Count      int64
+	}
+
+	query := r.DB.Model(&models.Event{}).Select("category_id, COUNT(*) AS count").Group("category_id")
+	if publicOnly {
+		query = publiclyListed(query)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return counts, nil
+}
+
+// SetCategoryParent moves a category under another one, or to the top level
+// when parentID is nil
+func (r *EventRepository) SetCategoryParent(id uint, parentID *uint) error {
+	return r.DB.Unscoped().Model(&models.Category{}).Where("id = ?", id).Update("parent_id", parentID).Error
+}
+
+// ReparentCategories moves the direct subcategories of a category under
+// another parent and returns how many were moved
+func (r *EventRepository) ReparentCategories(fromParentID uint, toParentID *uint) (int64, error) {
+	result := r.DB.Model(&models.Category{}).Where("parent_id = ?", fromParentID).Update("parent_id", toParentID)
+	return result.RowsAffected, result.Error
+}
+
+// ErrCategoryExists is returned when a live category with the same name
+// already sits under the same parent in the same organization
+var ErrCategoryExists = errors.New("a category with this name already exists")
+
 func (r *EventRepository) CreateCategory(category *models.Category) error {
-	return r.DB.Create(category).Error
+	return r.categoryError(r.DB.Create(category).Error)
 }
 
 func (r *EventRepository) UpdateCategory(category *models.Category) error {
-	return r.DB.Save(category).Error
+	return r.categoryError(r.DB.Save(category).Error)
+}
+
+// categoryError reports a violation of the category name index as
+// ErrCategoryExists
+func (r *EventRepository) categoryError(err error) error {
+	if translator, ok := r.DB.Dialector.(gorm.ErrorTranslator); ok && err != nil {
+		if errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey) {
+			return ErrCategoryExists
+		}
+	}
+	return err
 }
 
 func (r *EventRepository) DeleteCategory(id uint) error {
 	return r.DB.Delete(&models.Category{}, id).Error
 }
 
+// CountCategoryUsage counts the events and recurring series filed under a category
+func (r *EventRepository) CountCategoryUsage(categoryID uint) (int64, int64, error) {
+	var events, series int64
+	if err := r.DB.Model(&models.Event{}).Where("category_id = ?", categoryID).Count(&events).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := r.DB.Model(&models.EventSeries{}).Where("category_id = ?", categoryID).Count(&series).Error; err != nil {
+		return 0, 0, err
+	}
+	return events, series, nil
+}
+
+func (r *EventRepository) CountSubcategories(categoryID uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&models.Category{}).Where("parent_id = ?", categoryID).Count(&count).Error
+	return count, err
+}
+
+// GetCategoryEventsAfter returns the next batch of a category's events with
+// an ID above afterID, for paging through all of them
+func (r *EventRepository) GetCategoryEventsAfter(categoryID, afterID uint, limit int) ([]models.Event, error) {
+	var events []models.Event
+	err := r.DB.Where("category_id = ? AND id > ?", categoryID, afterID).
+		Order("id ASC").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// MoveCategoryEvents refiles every event and series of a category, including
+// trashed events, under another category. It returns the IDs of the live
+// events that were moved.
+func (r *EventRepository) MoveCategoryEvents(fromID, toID uint) ([]uint, error) {
+	var ids []uint
+	if err := r.DB.Model(&models.Event{}).Where("category_id = ?", fromID).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	err := r.DB.Unscoped().Model(&models.Event{}).Where("category_id = ?", fromID).
+		Update("category_id", toID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.DB.Model(&models.EventSeries{}).Where("category_id = ?", fromID).
+		Update("category_id", toID).Error
+	return ids, err
+}
+
+func (r *EventRepository) GetCategorySeries(categoryID uint) ([]models.EventSeries, error) {
+	var series []models.EventSeries
+	err := r.DB.Where("category_id = ?", categoryID).Find(&series).Error
+	return series, err
+}
+
+// SoftDeleteCategory moves a category to the trash, stamped with the given time
+func (r *EventRepository) SoftDeleteCategory(id uint, at time.Time) error {
+	return r.DB.Model(&models.Category{}).Where("id = ?", id).Update("deleted_at", at).Error
+}
+
+// GetDeletedCategories lists categories in the trash, most recently deleted
+// first. When organizationID is set only that organization's are listed.
+func (r *EventRepository) GetDeletedCategories(organizationID *uint) ([]models.Category, error) {
+	var categories []models.Category
+	err := r.DB.Unscoped().Where("deleted_at IS NOT NULL").Scopes(inOrganization(organizationID)).
+		Order("deleted_at DESC").Find(&categories).Error
+	return categories, err
+}
+
+// GetDeletedCategory loads a category from the trash, provided it belongs to
+// the given organization. A nil organization matches every category.
+func (r *EventRepository) GetDeletedCategory(id uint, organizationID *uint) (*models.Category, error) {
+	var category models.Category
+	err := r.DB.Unscoped().Where("deleted_at IS NOT NULL").Scopes(inOrganization(organizationID)).
+		First(&category, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// RestoreCategory takes a category out of the trash
+func (r *EventRepository) RestoreCategory(id uint) error {
+	return r.categoryError(r.DB.Unscoped().Model(&models.Category{}).Where("id = ?", id).Update("deleted_at", nil).Error)
+}
+
+// PurgeCategories removes categories that have been in the trash since before
+// the cutoff and no longer have any events, deleted or not
+func (r *EventRepository) PurgeCategories(before time.Time) (int64, error) {
+	purgeable := r.DB.Unscoped().Model(&models.Category{}).Select("id").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM events WHERE events.category_id = categories.id)")
+
+	if err := r.DB.Where("category_id IN (?)", purgeable).Delete(&models.CategoryTranslation{}).Error; err != nil {
+		return 0, err
+	}
+	result := r.DB.Unscoped().Where("id IN (?)", purgeable).Delete(&models.Category{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *EventRepository) GetAllVenues(city string) ([]models.Venue, error) {
+	var venues []models.Venue
+	query := r.DB.Order("name ASC")
+	if city != "" {
+		query = query.Where("city ILIKE ?", city)
+	}
+	err := query.Find(&venues).Error
+	return venues, err
+}
+
+func (r *EventRepository) GetVenueByID(id uint) (*models.Venue, error) {
+	var venue models.Venue
+	if err := r.DB.First(&venue, id).Error; err != nil {
+		return nil, err
+	}
+	return &venue, nil
+}
+
+func (r *EventRepository) CreateVenue(venue *models.Venue) error {
+	return r.DB.Create(venue).Error
+}
+
+func (r *EventRepository) UpdateVenue(venue *models.Venue) error {
+	return r.DB.Save(venue).Error
+}
+
+func (r *EventRepository) DeleteVenue(id uint) error {
+	return r.DB.Delete(&models.Venue{}, id).Error
+}
+
+// GetVenueEventIDs lists the events held at a venue
+func (r *EventRepository) GetVenueEventIDs(venueID uint) ([]uint, error) {
+	var ids []uint
+	err := r.DB.Model(&models.Event{}).Where("venue_id = ?", venueID).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetCategoryEventIDs lists the events filed under a category
+func (r *EventRepository) GetCategoryEventIDs(categoryID uint) ([]uint, error) {
+	var ids []uint
+	err := r.DB.Model(&models.Event{}).Where("category_id = ?", categoryID).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// SyncVenueName copies a renamed venue's name onto its events
+func (r *EventRepository) SyncVenueName(venueID uint, name string) error {
+	return r.DB.Model(&models.Event{}).Where("venue_id = ?", venueID).Update("venue", name).Error
+}
+
+// haversineKm is the great-circle distance in kilometres between a venue and
+// a point. It takes the point's latitude twice, then its longitude.
+const haversineKm = "6371 * 2 * ASIN(SQRT(POWER(SIN(RADIANS(venues.latitude - ?) / 2), 2) + " +
+	"COS(RADIANS(?)) * COS(RADIANS(venues.latitude)) * POWER(SIN(RADIANS(venues.longitude - ?) / 2), 2)))"
+
+// GetNearby finds publicly listed events that have not ended yet at venues
+// within radiusKm of the given point, nearest first. It also returns each
+// event's distance in kilometres.
+func (r *EventRepository) GetNearby(lat, lng, radiusKm float64, page, pageSize int) ([]models.Event, map[uint]float64, int64, error) {
+	// A latitude band narrows the scan before distances are computed
+	latDelta := radiusKm / 111.0
+
+	base := func() *gorm.DB {
+		return r.DB.Table("events").
+			Joins("JOIN venues ON venues.id = events.venue_id AND venues.deleted_at IS NULL").
+			Where("venues.latitude BETWEEN ? AND ?", lat-latDelta, lat+latDelta).
+			Where(haversineKm+" <= ?", lat, lat, lng, radiusKm).
+			Where("events.end_date >= ?", time.Now()).
+			Where("events.deleted_at IS NULL").
+			Scopes(publiclyListed)
+	}
+
+	var total int64
+	if err := base().Count(&total).Error; err != nil {
+		return nil, nil, 0, err
+	}
+
+	var rows []struct {
+		ID         uint
+		DistanceKm float64
+	}
+	err := base().
+		Select("events.id AS id, "+haversineKm+" AS distance_km", lat, lat, lng).
+		Order("distance_km ASC, events.event_date ASC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(rows) == 0 {
+		return []models.Event{}, map[uint]float64{}, total, nil
+	}
+
+	ids := make([]uint, 0, len(rows))
+	distances := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+		distances[row.ID] = row.DistanceKm
+	}
+
+	events, err := r.getEventsInOrder(ids)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return events, distances, total, nil
+}
+
+// getEventsInOrder loads events with their associations, keeping the order
+// of ids that an IN lookup loses
+func (r *EventRepository) getEventsInOrder(ids []uint) ([]models.Event, error) {
+	var found []models.Event
+	err := r.DB.Preload("Category.Translations").Preload("VenueDetails").Preload("Tags").Preload("TicketTypes", orderTicketTypes).Preload("Translations").
+		Where("id IN ?", ids).Find(&found).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Event, len(found))
+	for _, evt := range found {
+		byID[evt.ID] = evt
+	}
+	events := make([]models.Event, 0, len(found))
+	for _, id := range ids {
+		if evt, ok := byID[id]; ok {
+			events = append(events, evt)
+		}
+	}
+	return events, nil
+}
+
+// SearchMatch describes how an event matched a search. Headline is the
+// event name and Snippet an excerpt of its description, with matched words
+// wrapped in <mark> tags. The text around them is HTML-escaped, so both can
+// be rendered as HTML. Fuzzy is set when the event was only found by
+// similarity because nothing matched the search words exactly.
+type SearchMatch struct {
+	Rank     float64 `json:"rank"`
+	Headline string  `json:"headline"`
+	Snippet  string  `json:"snippet,omitempty"`
+	Fuzzy    bool    `json:"fuzzy"`
+}
+
+const (
+	headlineOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	snippetOptions  = "StartSel=<mark>, StopSel=</mark>, MinWords=15, MaxWords=35, MaxFragments=2, FragmentDelimiter=\" … \""
+
+	// minWordSimilarity is how closely a search must resemble part of an
+	// event's name or venue to count as a typo of it
+	minWordSimilarity = 0.4
+)
+
+// eventSearch is the condition a search puts on events and the rank used to
+// order its results. Similarity searches without pg_trgm have no rank.
+type eventSearch struct {
+	condition string
+	args      []interface{}
+	rank      *sortKey
+	tsquery   string
+	fuzzy     bool
+}
+
+// fullTextSearch matches the full-text document built from an event's name,
+// tags, category, venue and description. Each word may be the start of a
+// longer one. It reports false when the query has no words to search for.
+func fullTextSearch(query string) (eventSearch, bool) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return eventSearch{}, false
+	}
+
+	tsquery := strings.Join(terms, ":* & ") + ":*"
+	return eventSearch{
+		condition: "events.search_vector @@ to_tsquery('simple', ?)",
+		args:      []interface{}{tsquery},
+		rank: &sortKey{
+			expr: "ts_rank_cd(events.search_vector, to_tsquery('simple', ?))",
+			args: []interface{}{tsquery},
+			desc: true,
+			kind: keyFloat,
+		},
+		tsquery: tsquery,
+	}, true
+}
+
+// similaritySearch matches events whose name or venue resembles the query,
+// to tolerate typos. Without the pg_trgm extension it settles for a
+// substring match on the name, venue and description.
+func (r *EventRepository) similaritySearch(query string) (eventSearch, error) {
+	trigram, err := r.hasTrigram()
+	if err != nil {
+		return eventSearch{}, err
+	}
+
+	if !trigram {
+		pattern := "%" + escapeLike(query) + "%"
+		return eventSearch{
+			condition: "(events.name ILIKE ? OR events.venue ILIKE ? OR events.description ILIKE ?)",
+			args:      []interface{}{pattern, pattern, pattern},
+			fuzzy:     true,
+		}, nil
+	}
+
+	return eventSearch{
+		condition: "(word_similarity(?, events.name) >= ? OR word_similarity(?, events.venue) >= ?)",
+		args:      []interface{}{query, minWordSimilarity, query, minWordSimilarity},
+		rank: &sortKey{
+			expr: "GREATEST(word_similarity(?, events.name), word_similarity(?, events.venue))",
+			args: []interface{}{query, query},
+			desc: true,
+			kind: keyFloat,
+		},
+		fuzzy: true,
+	}, nil
+}
+
+// keyset orders search results by rank, best first, unless another sort
+// order was asked for
+func (s eventSearch) keyset(sort EventSort, now time.Time) keyset {
+	if sort != SortUpcoming && sort != SortRelevance {
+		return eventKeyset(sort, now)
+	}
+
+	keys := []sortKey{{expr: "events.event_date", kind: keyTime}, {expr: "events.id", kind: keyInt}}
+	if s.rank != nil {
+		keys = append([]sortKey{*s.rank}, keys...)
+	}
+	return keyset{name: string(SortRelevance), keys: keys}
+}
+
+func (r *EventRepository) searchQuery(search eventSearch, filter EventFilter) *gorm.DB {
+	return r.DB.Model(&models.Event{}).Where(search.condition, search.args...).Scopes(filter.apply)
+}
+
+// Search finds events by full-text search, best matches first, and falls
+// back to a similarity search when nothing matches exactly
+func (r *EventRepository) Search(query string, page, pageSize int, filter EventFilter) ([]models.Event, map[uint]SearchMatch, int64, error) {
+	var total int64
+	search, ok := fullTextSearch(query)
+	if ok {
+		if err := r.searchQuery(search, filter).Count(&total).Error; err != nil {
+			return nil, nil, 0, err
+		}
+	}
+
+	if total == 0 {
+		var err error
+		if search, err = r.similaritySearch(query); err != nil {
+			return nil, nil, 0, err
+		}
+		if err := r.searchQuery(search, filter).Count(&total).Error; err != nil {
+			return nil, nil, 0, err
+		}
+		if total == 0 {
+			return []models.Event{}, map[uint]SearchMatch{}, 0, nil
+		}
+	}
+
+	var ids []uint
+	err := search.keyset(filter.Sort, time.Now()).order(r.searchQuery(search, filter), false).
+		Offset((page-1)*pageSize).Limit(pageSize).
+		Pluck("events.id", &ids).Error
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	events, matches, err := r.searchResults(search, ids)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return events, matches, total, nil
+}
+
+// SearchByCursor is Search with keyset pagination. The first page decides
+// whether the search falls back to similarity, and its cursors keep later
+// pages on the same kind of search.
+func (r *EventRepository) SearchByCursor(query string, cursor *Cursor, limit int, filter EventFilter) ([]models.Event, map[uint]SearchMatch, CursorPage, error) {
+	now := cursorTime(cursor)
+
+	search, ok := fullTextSearch(query)
+	fuzzy := !ok || (cursor != nil && cursor.Fuzzy)
+	if !fuzzy && cursor == nil {
+		var found []uint
+		if err := r.searchQuery(search, filter).Limit(1).Pluck("events.id", &found).Error; err != nil {
+			return nil, nil, CursorPage{}, err
+		}
+		fuzzy = len(found) == 0
+	}
+
+	if fuzzy {
+		var err error
+		if search, err = r.similaritySearch(query); err != nil {
+			return nil, nil, CursorPage{}, err
+		}
+	}
+
+	ks := search.keyset(filter.Sort, now)
+	ids, page, err := ks.fetch(r.searchQuery(search, filter), cursor, limit, Cursor{Order: ks.name, Now: &now, Fuzzy: fuzzy})
+	if err != nil {
+		return nil, nil, CursorPage{}, err
+	}
+
+	events, matches, err := r.searchResults(search, ids)
+	if err != nil {
+		return nil, nil, CursorPage{}, err
+	}
+	return events, matches, page, nil
+}
+
+// escapeHTML escapes an SQL text expression for HTML, so the <mark> tags
+// added by ts_headline are the only markup in a highlight
+func escapeHTML(expr string) string {
+	return "replace(replace(replace(" + expr + ", '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+}
+
+// searchResults loads the events found by a search, in order, with their
+// rank and highlights
+func (r *EventRepository) searchResults(search eventSearch, ids []uint) ([]models.Event, map[uint]SearchMatch, error) {
+	if len(ids) == 0 {
+		return []models.Event{}, map[uint]SearchMatch{}, nil
+	}
+
+	columns := "events.id AS id, " + escapeHTML("events.name") + " AS headline, '' AS snippet"
+	var args []interface{}
+	if search.tsquery != "" {
+		columns = "events.id AS id, " +
+			"ts_headline('simple', " + escapeHTML("events.name") + ", to_tsquery('simple', ?), ?) AS headline, " +
+			"ts_headline('simple', " + escapeHTML("COALESCE(events.description, '')") + ", to_tsquery('simple', ?), ?) AS snippet"
+		args = []interface{}{search.tsquery, headlineOptions, search.tsquery, snippetOptions}
+	}
+	if search.rank != nil {
+		columns += ", " + search.rank.expr + " AS rank"
+		args = append(args, search.rank.args...)
+	} else {
+		columns += ", 0 AS rank"
+	}
+
+	var rows []struct {
+		ID       uint
+		Headline string
+		Snippet  string
+		Rank     float64
+	}
+	if err := r.DB.Model(&models.Event{}).Select(columns, args...).Where("events.id IN ?", ids).Scan(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	matches := make(map[uint]SearchMatch, len(rows))
+	for _, row := range rows {
+		matches[row.ID] = SearchMatch{Rank: row.Rank, Headline: row.Headline, Snippet: row.Snippet, Fuzzy: search.fuzzy}
+	}
+
+	events, err := r.getEventsInOrder(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	return events, matches, nil
+}
+
+// GetAllByCursor is GetAll with keyset pagination
+func (r *EventRepository) GetAllByCursor(cursor *Cursor, limit int, filter EventFilter) ([]models.Event, CursorPage, error) {
+	now := cursorTime(cursor)
+	ks := eventKeyset(filter.Sort, now)
+
+	query := r.DB.Model(&models.Event{}).Scopes(filter.apply)
+	ids, page, err := ks.fetch(query, cursor, limit, Cursor{Order: ks.name, Now: &now})
+	if err != nil {
+		return nil, CursorPage{}, err
+	}
+
+	events, err := r.getEventsInOrder(ids)
+	if err != nil {
+		return nil, CursorPage{}, err
+	}
+	return events, page, nil
+}
+
+// cursorTime is the reference time of a listing: the one pinned by its
+// cursor, or now for the first page
+func cursorTime(cursor *Cursor) time.Time {
+	if cursor != nil && cursor.Now != nil {
+		return *cursor.Now
+	}
+	return time.Now().UTC()
+}
+
+// hasTrigram reports whether the pg_trgm extension is installed, without
+// which similarity matching falls back to plain pattern matching
+func (r *EventRepository) hasTrigram() (bool, error) {
+	var trigram bool
+	err := r.DB.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm')").Scan(&trigram).Error
+	return trigram, err
+}
+
+// Kinds of search suggestion
+const (
+	SuggestionEvent    = "event"
+	SuggestionVenue    = "venue"
+	SuggestionTag      = "tag"
+	SuggestionCategory = "category"
+)
+
+// Suggestion is a completion for a search being typed. ID is set for events,
+// tags and categories, Slug for categories only.
+type Suggestion struct {
+	Kind  string  `json:"kind"`
+	Text  string  `json:"text"`
+	ID    uint    `json:"id,omitempty"`
+	Slug  string  `json:"slug,omitempty"`
+	Score float64 `json:"-"`
+}
+
+// suggestMatch scores how well a piece of text completes what was typed.
+// Text starting with it ranks highest, then text with a word starting with
+// it, and with pg_trgm anything resembling it closely enough is let in too.
+type suggestMatch struct {
+	query   string
+	trigram bool
+}
+
+func (m suggestMatch) condition(column string) (string, []interface{}) {
+	prefix, wordPrefix := m.patterns()
+	if !m.trigram {
+		return fmt.Sprintf("(%s ILIKE ? OR %s ILIKE ?)", column, column), []interface{}{prefix, wordPrefix}
+	}
+	return fmt.Sprintf("(%s ILIKE ? OR %s ILIKE ? OR word_similarity(?, %s) >= ?)", column, column, column),
+		[]interface{}{prefix, wordPrefix, m.query, minWordSimilarity}
+}
+
+func (m suggestMatch) score(column string) (string, []interface{}) {
+	prefix, wordPrefix := m.patterns()
+	score := fmt.Sprintf("(CASE WHEN %s ILIKE ? THEN 1 WHEN %s ILIKE ? THEN 0.5 ELSE 0 END)", column, column)
+	if !m.trigram {
+		return score, []interface{}{prefix, wordPrefix}
+	}
+	return score + fmt.Sprintf(" + word_similarity(?, %s)", column), []interface{}{prefix, wordPrefix, m.query}
+}
+
+func (m suggestMatch) patterns() (string, string) {
+	escaped := escapeLike(m.query)
+	return escaped + "%", "% " + escaped + "%"
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern so text matches itself
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+}
+
+// GetSuggestions completes a search from the names and venues of listed
+// events, the tags they carry and the categories, best matches first and at
+// most limit of them in all
+func (r *EventRepository) GetSuggestions(query string, limit int) ([]Suggestion, error) {
+	trigram, err := r.hasTrigram()
+	if err != nil {
+		return nil, err
+	}
+	match := suggestMatch{query: query, trigram: trigram}
+
+	nameCondition, nameArgs := match.condition("events.name")
+	nameScore, nameScoreArgs := match.score("events.name")
+	events := r.DB.Model(&models.Event{}).
+		Select("CAST(? AS text) AS kind, events.name AS text, events.id AS id, '' AS slug, "+nameScore+" AS score",
+			append([]interface{}{SuggestionEvent}, nameScoreArgs...)...).
+		Scopes(publiclyListed).
+		Where(nameCondition, nameArgs...).
+		Order("score DESC").
+		Limit(limit)
+
+	venueCondition, venueArgs := match.condition("events.venue")
+	venueScore, venueScoreArgs := match.score("events.venue")
+	venues := r.DB.Model(&models.Event{}).
+		Select("CAST(? AS text) AS kind, MIN(events.venue) AS text, 0 AS id, '' AS slug, MAX("+venueScore+") AS score",
+			append([]interface{}{SuggestionVenue}, venueScoreArgs...)...).
+		Scopes(publiclyListed).
+		Where("events.venue <> ''").
+		Where(venueCondition, venueArgs...).
+		Group("LOWER(events.venue)").
+		Order("score DESC").
+		Limit(limit)
+
+	listed := r.DB.Model(&models.EventTag{}).
+		Select("1").
+		Joins("JOIN events ON events.id = event_tags.event_id AND events.deleted_at IS NULL").
+		Scopes(publiclyListed).
+		Where("event_tags.tag_id = tags.id")
+	tagCondition, tagArgs := match.condition("tags.name")
+	tagScore, tagScoreArgs := match.score("tags.name")
+	tags := r.DB.Model(&models.Tag{}).
+		Select("CAST(? AS text) AS kind, tags.name AS text, tags.id AS id, '' AS slug, "+tagScore+" AS score",
+			append([]interface{}{SuggestionTag}, tagScoreArgs...)...).
+		Where(tagCondition, tagArgs...).
+		Where("EXISTS (?)", listed).
+		Order("score DESC").
+		Limit(limit)
+
+	categoryCondition, categoryArgs := match.condition("categories.name")
+	categoryScore, categoryScoreArgs := match.score("categories.name")
+	categories := r.DB.Model(&models.Category{}).
+		Select("CAST(? AS text) AS kind, categories.name AS text, categories.id AS id, categories.slug AS slug, "+categoryScore+" AS score",
+			append([]interface{}{SuggestionCategory}, categoryScoreArgs...)...).
+		Where(categoryCondition, categoryArgs...).
+		Order("score DESC").
+		Limit(limit)
+
+	var suggestions []Suggestion
+	err = r.DB.Table("((?) UNION ALL (?) UNION ALL (?) UNION ALL (?)) AS suggestions", events, venues, tags, categories).
+		Order("score DESC, LENGTH(text) ASC, text ASC").
+		Limit(limit).
+		Scan(&suggestions).Error
+	return suggestions, err
+}
+
+// searchTerms splits a search into lowercase words of letters and digits,
+// which keeps tsquery operators typed by users out of the query
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
 func (r *EventRepository) GetAllTags() ([]models.Tag, error) {
 	var tags []models.Tag
 	err := r.DB.Find(&tags).Error
 	return tags, err
 }
 
+// GetTagUsage lists every tag with how many publicly listed events carry
+// it, most used first
+func (r *EventRepository) GetTagUsage() ([]TagUsage, error) {
+	counts := r.DB.Model(&models.EventTag{}).
+		Select("event_tags.tag_id, COUNT(*) AS event_count").
+		Joins("JOIN events ON events.id = event_tags.event_id AND events.deleted_at IS NULL").
+		Scopes(publiclyListed).
+		Group("event_tags.tag_id")
+
+	var tags []TagUsage
+	err := r.DB.Model(&models.Tag{}).
+		Select("tags.*, COALESCE(counts.event_count, 0) AS event_count").
+		Joins("LEFT JOIN (?) AS counts ON counts.tag_id = tags.id", counts).
+		Order("event_count DESC, tags.name ASC").
+		Scan(&tags).Error
+	return tags, err
+}
+
+// GetTagByName looks a tag up regardless of letter case
+func (r *EventRepository) GetTagByName(name string) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.DB.Where("LOWER(name) = LOWER(?)", name).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *EventRepository) GetTagsByIDs(ids []uint) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.DB.Where("id IN ?", ids).Find(&tags).Error
+	return tags, err
+}
+
+func (r *EventRepository) UpdateTag(tag *models.Tag) error {
+	return r.DB.Save(tag).Error
+}
+
+// GetTaggedEventIDs returns the IDs of the events carrying any of the tags
+func (r *EventRepository) GetTaggedEventIDs(tagIDs []uint) ([]uint, error) {
+	var ids []uint
+	err := r.DB.Model(&models.EventTag{}).Where("tag_id IN ?", tagIDs).Distinct().Pluck("event_id", &ids).Error
+	return ids, err
+}
+
+// MergeTags moves the events of the source tags over to the target tag and
+// deletes the source tags. Events already carrying the target keep a single
+// link to it.
+func (r *EventRepository) MergeTags(sourceIDs []uint, targetID uint) error {
+	err := r.DB.Exec(`INSERT INTO event_tags (event_id, tag_id, created_at)
+		SELECT event_id, ?, MIN(created_at) FROM event_tags WHERE tag_id IN ? GROUP BY event_id
+		ON CONFLICT DO NOTHING`, targetID, sourceIDs).Error
+	if err != nil {
+		return err
+	}
+
+	return r.DeleteTags(sourceIDs)
+}
+
+// DeleteTags removes tags from every event and then deletes them
+func (r *EventRepository) DeleteTags(ids []uint) error {
+	if err := r.DB.Where("tag_id IN ?", ids).Delete(&models.EventTag{}).Error; err != nil {
+		return err
+	}
+	return r.DB.Where("id IN ?", ids).Delete(&models.Tag{}).Error
+}
+
+// GetSeriesWithTags returns the series whose tag list may mention any of the
+// given names. Matching is loose, so callers compare the names themselves.
+func (r *EventRepository) GetSeriesWithTags(names []string) ([]models.EventSeries, error) {
+	query := r.DB.Model(&models.EventSeries{})
+	conditions := r.DB.Session(&gorm.Session{NewDB: true})
+	for _, name := range names {
+		conditions = conditions.Or("tags ILIKE ?", "%"+name+"%")
+	}
+
+	var series []models.EventSeries
+	err := query.Where(conditions).Find(&series).Error
+	return series, err
+}
+
 func (r *EventRepository) GetTagByID(id uint) (*models.Tag, error) {
 	var tag models.Tag
 	err := r.DB.First(&tag, id).Error
@@ -157,11 +1216,193 @@ func (r *EventRepository) CreateTag(tag *models.Tag) error {
 	return r.DB.Create(tag).Error
 }
 
+// FindOrCreateTag returns the tag with the given name, ignoring letter case,
+// creating it with the name as written when there is none
 func (r *EventRepository) FindOrCreateTag(name string) (*models.Tag, error) {
-	var tag models.Tag
+	tag, err := r.GetTagByName(name)
+	if err == nil {
+		return tag, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	tag = &models.Tag{Name: name}
+	if err := r.DB.Create(tag).Error; err != nil {
+		// Another request may have created it in the meantime
+		if existing, findErr := r.GetTagByName(name); findErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (f EventFilter) apply(db *gorm.DB) *gorm.DB {
+	if f.CategoryID > 0 {
+		db = db.Where("events.category_id IN (?)", categoryTree(db.Session(&gorm.Session{NewDB: true}), f.CategoryID))
+	}
+	if f.Status != "" {
+		db = db.Where("events.status = ?", f.Status)
+	}
+	if f.From != nil {
+		db = db.Where("events.end_date >= ?", *f.From)
+	}
+	if f.To != nil {
+		db = db.Where("events.event_date <= ?", *f.To)
+	}
+	if f.MinPrice != nil {
+		db = db.Where(startingPrice+" >= ?", *f.MinPrice)
+	}
+	if f.MaxPrice != nil {
+		db = db.Where(startingPrice+" <= ?", *f.MaxPrice)
+	}
+	if f.FreeOnly {
+		db = db.Where(startingPrice + " = 0")
+	}
+	if f.VenueID > 0 {
+		db = db.Where("events.venue_id = ?", f.VenueID)
+	}
+	if len(f.Tags) > 0 {
+		db = db.Where("events.id IN (?)", taggedEvents(db.Session(&gorm.Session{NewDB: true}), f.Tags, f.MatchAllTags))
+	}
+	if f.PublicOnly {
+		db = publiclyListed(db)
+	}
+	return inOrganization(f.OrganizationID)(db)
+}
+
+// categoryTree selects the IDs of a category and all of its live
+// subcategories, at any depth
+func categoryTree(db *gorm.DB, categoryID uint) *gorm.DB {
+	return db.Raw(`WITH RECURSIVE tree AS (
+		SELECT id FROM categories WHERE id = ?
+		UNION
+		SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id
+		WHERE categories.deleted_at IS NULL
+	) SELECT id FROM tree`, categoryID)
+}
+
+// taggedEvents selects the IDs of the events carrying any of the tags, or all
+// of them when matchAll is set. Tag names are compared ignoring case.
+func taggedEvents(db *gorm.DB, names []string, matchAll bool) *gorm.DB {
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
+	}
+
+	query := db.Table("event_tags").Select("event_tags.event_id").
+		Joins("JOIN tags ON tags.id = event_tags.tag_id").
+		Where("LOWER(tags.name) IN ?", lowered)
+	if matchAll {
+		query = query.Group("event_tags.event_id").Having("COUNT(DISTINCT tags.id) = ?", len(lowered))
+	}
+	return query
+}
+
+// inOrganization limits a query to records owned by an organization. A nil
+// organization leaves the query unscoped.
+func inOrganization(organizationID *uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if organizationID == nil {
+			return db
+		}
+		return db.Where("organization_id = ?", *organizationID)
+	}
+}
+
+// preloadDeletedEvent loads an event's relations even when its category has
+// been moved to the trash as well
+func preloadDeletedEvent(db *gorm.DB) *gorm.DB {
+	return db.Preload("Category", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("VenueDetails").Preload("Tags").Preload("TicketTypes", orderTicketTypes)
+}
+
+// publiclyListed keeps to published and postponed events, plus scheduled
+// events whose publish time has passed. Drafts and cancelled events are
+// left out of public listings.
+func publiclyListed(db *gorm.DB) *gorm.DB {
+	return db.Where("(events.status IN ? OR (events.status = ? AND events.publish_at <= ?))",
+		[]models.EventStatus{models.EventStatusPublished, models.EventStatusPostponed},
+		models.EventStatusScheduled, time.Now())
+}
+
+// eventKeyset is the sort order of an event listing. Ties are broken by
+// start time and then ID so pages never overlap. The default order lists
+// events that have not ended by now first, soonest start first, followed by
+// past events with the most recently ended first.
+func eventKeyset(sort EventSort, now time.Time) keyset {
+	id := sortKey{expr: "events.id", kind: keyInt}
+	startsAt := sortKey{expr: "events.event_date", kind: keyTime}
+	price := sortKey{expr: "(" + startingPrice + ")::double precision", kind: keyFloat}
+
+	switch sort {
+	case SortDate:
+		return keyset{name: string(sort), keys: []sortKey{startsAt, id}}
+	case SortPrice:
+		return keyset{name: string(sort), keys: []sortKey{price, startsAt, id}}
+	case SortPriceDesc:
+		price.desc = true
+		return keyset{name: string(sort), keys: []sortKey{price, startsAt, id}}
+	case SortNewest:
+		return keyset{name: string(sort), keys: []sortKey{{expr: "events.created_at", desc: true, kind: keyTime}, id}}
+	case SortPopularity:
+		return keyset{name: string(sort), keys: []sortKey{{expr: soldSeats, desc: true, kind: keyInt}, startsAt, id}}
+	}
 
-	err := r.DB.Where("name = ?", name).FirstOrCreate(&tag, models.Tag{Name: name}).Error
-	return &tag, err
+	return keyset{name: "upcoming", keys: []sortKey{
+		{expr: "CASE WHEN events.end_date >= ? THEN 0 ELSE 1 END", args: []interface{}{now}, kind: keyInt},
+		{expr: "CASE WHEN events.end_date >= ? THEN events.event_date END", args: []interface{}{now}, kind: keyTime},
+		{expr: "CASE WHEN events.end_date < ? THEN events.end_date END", args: []interface{}{now}, desc: true, kind: keyTime},
+		id,
+	}}
+}
+
+// orderEvents applies a listing's sort order
+func orderEvents(query *gorm.DB, sort EventSort, now time.Time) *gorm.DB {
+	return eventKeyset(sort, now).order(query, false)
+}
+
+// orderTicketTypes lists an event's ticket tiers from cheapest to most expensive
+func orderTicketTypes(db *gorm.DB) *gorm.DB {
+	return db.Order("price ASC, id ASC")
+}
+
+func (r *EventRepository) GetTicketTypes(eventID uint) ([]models.TicketType, error) {
+	var ticketTypes []models.TicketType
+	err := orderTicketTypes(r.DB.Where("event_id = ?", eventID)).Find(&ticketTypes).Error
+	return ticketTypes, err
+}
+
+// GetTicketType returns a ticket type only if it belongs to the given event
+func (r *EventRepository) GetTicketType(eventID, id uint) (*models.TicketType, error) {
+	var ticketType models.TicketType
+	if err := r.DB.Where("event_id = ?", eventID).First(&ticketType, id).Error; err != nil {
+		return nil, err
+	}
+	return &ticketType, nil
+}
+
+func (r *EventRepository) CountTicketTypes(eventID uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&models.TicketType{}).Where("event_id = ?", eventID).Count(&count).Error
+	return count, err
+}
+
+func (r *EventRepository) CreateTicketType(ticketType *models.TicketType) error {
+	return r.DB.Create(ticketType).Error
+}
+
+func (r *EventRepository) UpdateTicketType(ticketType *models.TicketType) error {
+	return r.DB.Save(ticketType).Error
+}
+
+func (r *EventRepository) DeleteTicketType(id uint) error {
+	return r.DB.Delete(&models.TicketType{}, id).Error
+}
+
+func (r *EventRepository) DeleteTicketTypesByEvent(eventID uint) error {
+	return r.DB.Where("event_id = ?", eventID).Delete(&models.TicketType{}).Error
 }
 
 func (r *EventRepository) HasBookings(eventID uint) (bool, error) {
@@ -169,3 +1410,99 @@ func (r *EventRepository) HasBookings(eventID uint) (bool, error) {
 	err := r.DB.Model(&models.Booking{}).Where("event_id = ?", eventID).Count(&count).Error
 	return count > 0, err
 }
+
+// UpdateOccurrence saves an event's own columns without touching its
+// category, tags or ticket types
+func (r *EventRepository) UpdateOccurrence(event *models.Event) error {
+	return r.DB.Omit(clause.Associations).Save(event).Error
+}
+
+func (r *EventRepository) CreateSeries(series *models.EventSeries) error {
+	return r.DB.Omit(clause.Associations).Create(series).Error
+}
+
+func (r *EventRepository) GetSeriesByID(id uint) (*models.EventSeries, error) {
+	return r.GetOrganizationSeries(id, nil)
+}
+
+// GetOrganizationSeries loads a series only if it belongs to the given
+// organization. A nil organization matches every series.
+func (r *EventRepository) GetOrganizationSeries(id uint, organizationID *uint) (*models.EventSeries, error) {
+	var series models.EventSeries
+	if err := r.DB.Scopes(inOrganization(organizationID)).Preload("Category.Translations").First(&series, id).Error; err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (r *EventRepository) GetAllSeries() ([]models.EventSeries, error) {
+	var series []models.EventSeries
+	err := r.DB.Find(&series).Error
+	return series, err
+}
+
+func (r *EventRepository) UpdateSeries(series *models.EventSeries) error {
+	return r.DB.Omit(clause.Associations).Save(series).Error
+}
+
+func (r *EventRepository) DeleteSeries(id uint) error {
+	return r.DB.Delete(&models.EventSeries{}, id).Error
+}
+
+// GetSeriesEvents lists a series' occurrences in order. When from is set only
+// occurrences generated for that time or later are returned.
+func (r *EventRepository) GetSeriesEvents(seriesID uint, from *time.Time) ([]models.Event, error) {
+	var events []models.Event
+	query := r.DB.Preload("Category.Translations").Preload("VenueDetails").Preload("Tags").Preload("TicketTypes", orderTicketTypes).Preload("Translations").
+		Where("series_id = ?", seriesID)
+	if from != nil {
+		query = query.Where("occurrence_date >= ?", *from)
+	}
+	err := query.Order("occurrence_date ASC, id ASC").Find(&events).Error
+	return events, err
+}
+
+// MoveSeriesEvents hands the occurrences from the given time onwards over to
+// another series, used when a series is split
+func (r *EventRepository) MoveSeriesEvents(fromSeriesID, toSeriesID uint, from time.Time) error {
+	return r.DB.Model(&models.Event{}).
+		Where("series_id = ? AND occurrence_date >= ?", fromSeriesID, from).
+		Update("series_id", toSeriesID).Error
+}
+
+// DetachSeriesEvents turns every occurrence of a series into a standalone
+// event, trashed ones included
+func (r *EventRepository) DetachSeriesEvents(seriesID uint) error {
+	return r.DB.Unscoped().Model(&models.Event{}).Where("series_id = ?", seriesID).
+		Updates(map[string]interface{}{"series_id": nil, "occurrence_date": nil, "series_override": false}).Error
+}
+
+// DetachFromSeries turns an occurrence into a standalone event
+func (r *EventRepository) DetachFromSeries(eventID uint) error {
+	return r.DB.Model(&models.Event{}).Where("id = ?", eventID).
+		Updates(map[string]interface{}{"series_id": nil, "occurrence_date": nil, "series_override": false}).Error
+}
+
+// SetStatus saves an event's publication state
+func (r *EventRepository) SetStatus(event *models.Event) error {
+	return r.DB.Model(event).Select("status", "publish_at", "status_note").Updates(event).Error
+}
+
+// PublishDue publishes scheduled events whose publish time has passed and
+// returns their IDs
+func (r *EventRepository) PublishDue(now time.Time) ([]uint, error) {
+	var published []models.Event
+	err := r.DB.Model(&published).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("status = ? AND publish_at <= ?", models.EventStatusScheduled, now).
+		Update("status", models.EventStatusPublished).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(published))
+	for _, evt := range published {
+		ids = append(ids, evt.ID)
+	}
+	return ids, nil
+}
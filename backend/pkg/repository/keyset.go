@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidCursor is returned for cursors that cannot be decoded or that
+// belong to a listing with another sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position in a keyset paginated listing: the sort key values of
+// the row at a page boundary. Clients get it as an opaque string. Before
+// asks for the page that ends at that row rather than the one after it. Now
+// pins the reference time of listings sorted around the current time, and
+// Fuzzy keeps a search on its similarity fallback.
+type Cursor struct {
+	Order  string        `json:"o"`
+	Values []interface{} `json:"v"`
+	Before bool          `json:"b,omitempty"`
+	Now    *time.Time    `json:"n,omitempty"`
+	Fuzzy  bool          `json:"f,omitempty"`
+}
+
+// CursorPage holds the cursors of the pages around the one just read. Either
+// is empty when there is no such page.
+type CursorPage struct {
+	Next string
+	Prev string
+}
+
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor handed out by EncodeCursor. An empty string
+// is the start of the listing and gives a nil cursor.
+func DecodeCursor(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	// Numbers stay json.Number so large IDs survive the round trip
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var cursor Cursor
+	if err := decoder.Decode(&cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+type keyKind int
+
+const (
+	keyInt keyKind = iota
+	keyFloat
+	keyTime
+)
+
+// sortKey is one column of a keyset's sort order. The expression may take
+// arguments, which are repeated wherever it is used.
+type sortKey struct {
+	expr string
+	args []interface{}
+	desc bool
+	kind keyKind
+}
+
+// keyset is a sort order that can be paged through by position instead of
+// by offset. The last key must be the row's unique ID so the order is total.
+type keyset struct {
+	name string
+	keys []sortKey
+}
+
+// order sorts a query by the keyset, or in the opposite direction when
+// reading the page before a cursor. Being a single expression, it replaces
+// any order set before and must not be followed by another.
+func (k keyset) order(db *gorm.DB, reverse bool) *gorm.DB {
+	terms := make([]string, 0, len(k.keys))
+	var vars []interface{}
+	for _, key := range k.keys {
+		direction := " ASC"
+		if key.desc != reverse {
+			direction = " DESC"
+		}
+		terms = append(terms, key.expr+direction)
+		vars = append(vars, key.args...)
+	}
+	return db.Order(clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(terms, ", "), Vars: vars, WithoutParentheses: true}})
+}
+
+// beyond builds the condition for rows that come after the given key values,
+// or before them when reverse is set. Equality uses IS NOT DISTINCT FROM as
+// some keys are NULL for part of the rows.
+func (k keyset) beyond(values []interface{}, reverse bool) (string, []interface{}) {
+	var alternatives []string
+	var vars []interface{}
+
+	for i, key := range k.keys {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, fmt.Sprintf("(%s) IS NOT DISTINCT FROM ?", k.keys[j].expr))
+			vars = append(vars, k.keys[j].args...)
+			vars = append(vars, values[j])
+		}
+
+		operator := ">"
+		if key.desc != reverse {
+			operator = "<"
+		}
+		terms = append(terms, fmt.Sprintf("(%s) %s ?", key.expr, operator))
+		vars = append(vars, key.args...)
+		vars = append(vars, values[i])
+
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", vars
+}
+
+// decode checks that a cursor belongs to this keyset and converts its
+// values back to the types of the keys
+func (k keyset) decode(cursor *Cursor) ([]interface{}, error) {
+	if cursor.Order != k.name || len(cursor.Values) != len(k.keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	values := make([]interface{}, len(k.keys))
+	for i, key := range k.keys {
+		raw := cursor.Values[i]
+		if raw == nil {
+			continue
+		}
+
+		var err error
+		switch key.kind {
+		case keyInt:
+			number, ok := raw.(json.Number)
+			if !ok {
+				return nil, ErrInvalidCursor
+			}
+			values[i], err = number.Int64()
+		case keyFloat:
+			number, ok := raw.(json.Number)
+			if !ok {
+				return nil, ErrInvalidCursor
+			}
+			values[i], err = number.Float64()
+		case keyTime:
+			text, ok := raw.(string)
+			if !ok {
+				return nil, ErrInvalidCursor
+			}
+			values[i], err = time.Parse(time.RFC3339Nano, text)
+		}
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+	return values, nil
+}
+
+// fetch reads one page of a query in keyset order, starting after the
+// cursor or at the beginning when it is nil. It returns the IDs of the rows
+// in order and the cursors of the neighbouring pages, which copy template
+// apart from their position.
+func (k keyset) fetch(query *gorm.DB, cursor *Cursor, limit int, template Cursor) ([]uint, CursorPage, error) {
+	var page CursorPage
+	backward := cursor != nil && cursor.Before
+
+	if cursor != nil {
+		values, err := k.decode(cursor)
+		if err != nil {
+			return nil, page, err
+		}
+		condition, vars := k.beyond(values, backward)
+		query = query.Where(condition, vars...)
+	}
+
+	selects := make([]string, 0, len(k.keys))
+	var args []interface{}
+	for i, key := range k.keys {
+		selects = append(selects, fmt.Sprintf("%s AS k%d", key.expr, i))
+		args = append(args, key.args...)
+	}
+
+	// One extra row tells whether the listing goes on past this page
+	var rows []map[string]interface{}
+	err := k.order(query.Select(strings.Join(selects, ", "), args...), backward).
+		Limit(limit + 1).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, page, err
+	}
+
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	ids := make([]uint, 0, len(rows))
+	positions := make([][]interface{}, 0, len(rows))
+	for _, row := range rows {
+		position := make([]interface{}, len(k.keys))
+		for i, key := range k.keys {
+			position[i] = key.encode(row[fmt.Sprintf("k%d", i)])
+		}
+		id, _ := position[len(position)-1].(int64)
+		ids = append(ids, uint(id))
+		positions = append(positions, position)
+	}
+
+	if len(rows) == 0 {
+		return ids, page, nil
+	}
+	if (!backward && more) || backward {
+		next := template
+		next.Values = positions[len(positions)-1]
+		page.Next = EncodeCursor(next)
+	}
+	if (backward && more) || (!backward && cursor != nil) {
+		prev := template
+		prev.Values = positions[0]
+		prev.Before = true
+		page.Prev = EncodeCursor(prev)
+	}
+	return ids, page, nil
+}
+
+// encode turns a key value read from the database into its cursor form
+func (key sortKey) encode(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	case int:
+		return int64(v)
+	case uint:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		if key.kind == keyInt {
+			return int64(v)
+		}
+		return v
+	case []byte:
+		return string(v)
+	}
+	return value
+}
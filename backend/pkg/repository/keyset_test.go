@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var testKeyset = keyset{name: "test", keys: []sortKey{
+	{expr: "rank", desc: true, kind: keyFloat},
+	{expr: "starts_at", kind: keyTime},
+	{expr: "id", kind: keyInt},
+}}
+
+// roundTrip passes database values through a cursor the way a client would
+// see them and decodes them again
+func roundTrip(t *testing.T, k keyset, row []interface{}) []interface{} {
+	t.Helper()
+
+	position := make([]interface{}, len(row))
+	for i, key := range k.keys {
+		position[i] = key.encode(row[i])
+	}
+
+	cursor, err := DecodeCursor(EncodeCursor(Cursor{Order: k.name, Values: position}))
+	if err != nil {
+		t.Fatalf("DecodeCursor failed: %v", err)
+	}
+	values, err := k.decode(cursor)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	return values
+}
+
+func TestKeysetRoundTrip(t *testing.T) {
+	startsAt := time.Date(2025, time.March, 9, 2, 30, 15, 123456789, time.FixedZone("UTC+3", 3*60*60))
+
+	tests := []struct {
+		name string
+		row  []interface{}
+		want []interface{}
+	}{
+		{
+			name: "float rank and time",
+			row:  []interface{}{0.1 + 0.2, startsAt, int64(42)},
+			want: []interface{}{0.1 + 0.2, startsAt.UTC(), int64(42)},
+		},
+		{
+			name: "float32 rank and large ID",
+			row:  []interface{}{float32(0.75), startsAt, int64(1<<53 + 1)},
+			want: []interface{}{0.75, startsAt.UTC(), int64(1<<53 + 1)},
+		},
+		{
+			name: "NULL keys",
+			row:  []interface{}{nil, nil, uint(7)},
+			want: []interface{}{nil, nil, int64(7)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := roundTrip(t, testKeyset, tt.row)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeysetDecodeRejectsForeignCursors(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{name: "wrong order", cursor: Cursor{Order: "other", Values: []interface{}{0.5, "2025-03-09T00:00:00Z", 1}}},
+		{name: "too few values", cursor: Cursor{Order: "test", Values: []interface{}{0.5, 1}}},
+		{name: "too many values", cursor: Cursor{Order: "test", Values: []interface{}{0.5, "2025-03-09T00:00:00Z", 1, 2}}},
+		{name: "text for an int key", cursor: Cursor{Order: "test", Values: []interface{}{0.5, "2025-03-09T00:00:00Z", "1"}}},
+		{name: "fraction for an int key", cursor: Cursor{Order: "test", Values: []interface{}{0.5, "2025-03-09T00:00:00Z", 1.5}}},
+		{name: "text for a float key", cursor: Cursor{Order: "test", Values: []interface{}{"high", "2025-03-09T00:00:00Z", 1}}},
+		{name: "number for a time key", cursor: Cursor{Order: "test", Values: []interface{}{0.5, 1741478400, 1}}},
+		{name: "malformed time", cursor: Cursor{Order: "test", Values: []interface{}{0.5, "yesterday", 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := DecodeCursor(EncodeCursor(tt.cursor))
+			if err != nil {
+				t.Fatalf("DecodeCursor failed: %v", err)
+			}
+			if _, err := testKeyset.decode(cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decode error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	if cursor, err := DecodeCursor(""); cursor != nil || err != nil {
+		t.Errorf("DecodeCursor(\"\") = %v, %v, want nil, nil", cursor, err)
+	}
+	for _, value := range []string{"not base64!", "bm90IGpzb24"} {
+		if _, err := DecodeCursor(value); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q) error = %v, want ErrInvalidCursor", value, err)
+		}
+	}
+}
+
+// fakeRows is what the fake database driver answers every query with
+var fakeRows [][]driver.Value
+
+type fakeDriver struct{}
+
+type fakeConn struct{}
+
+type fakeStmt struct{}
+
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func init() {
+	sql.Register("keyset-test", fakeDriver{})
+}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("transactions are not supported") }
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec is not supported")
+}
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeResult{columns: []string{"k0", "k1", "k2"}, rows: fakeRows}, nil
+}
+
+func (r *fakeResult) Columns() []string { return r.columns }
+func (r *fakeResult) Close() error      { return nil }
+func (r *fakeResult) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func mustDecode(t *testing.T, value string) *Cursor {
+	t.Helper()
+
+	cursor, err := DecodeCursor(value)
+	if err != nil || cursor == nil {
+		t.Fatalf("invalid cursor %q: %v", value, err)
+	}
+	return cursor
+}
+
+func TestKeysetFetch(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DriverName: "keyset-test"}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	day := func(d int) time.Time { return time.Date(2025, time.May, d, 18, 0, 0, 0, time.UTC) }
+	rows := [][]driver.Value{
+		{0.9, day(1), int64(3)},
+		{0.9, day(2), int64(1)},
+		{0.4, day(1), int64(2)},
+	}
+
+	// The first page has no previous page, and the extra row means there
+	// is a next one
+	fakeRows = rows
+	ids, page, err := testKeyset.fetch(db.Table("events"), nil, 2, Cursor{Order: "test"})
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []uint{3, 1}) {
+		t.Errorf("ids = %v, want [3 1]", ids)
+	}
+	if page.Prev != "" {
+		t.Errorf("first page has a previous cursor %q", page.Prev)
+	}
+
+	next, err := DecodeCursor(page.Next)
+	if err != nil || next == nil {
+		t.Fatalf("invalid next cursor %q: %v", page.Next, err)
+	}
+	values, err := testKeyset.decode(next)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if want := []interface{}{0.9, day(2), int64(1)}; !reflect.DeepEqual(values, want) {
+		t.Errorf("next cursor values = %#v, want %#v", values, want)
+	}
+
+	// Reading back from the third row, rows come in reverse and are put
+	// back in order
+	fakeRows = [][]driver.Value{rows[1], rows[0]}
+	before := Cursor{Order: "test", Values: []interface{}{0.4, day(1).Format(time.RFC3339Nano), int64(2)}, Before: true}
+	before = *mustDecode(t, EncodeCursor(before))
+	ids, page, err = testKeyset.fetch(db.Table("events"), &before, 2, Cursor{Order: "test"})
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []uint{3, 1}) {
+		t.Errorf("ids = %v, want [3 1]", ids)
+	}
+	if page.Next == "" {
+		t.Error("page before a cursor has no next cursor")
+	}
+	if page.Prev != "" {
+		t.Errorf("page at the start has a previous cursor %q", page.Prev)
+	}
+
+	// A cursor of another listing is refused before anything is queried
+	other := Cursor{Order: "other", Values: next.Values}
+	if _, _, err := testKeyset.fetch(db.Table("events"), &other, 2, Cursor{Order: "test"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("fetch error = %v, want ErrInvalidCursor", err)
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/robaa12/mawid/pkg/models"
+	"gorm.io/gorm"
+)
+
+type LoginThrottleRepository struct {
+	DB *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{DB: db}
+}
+
+func (r *LoginThrottleRepository) Get(scope, subject string) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	if err := r.DB.Where("scope = ? AND subject = ?", scope, subject).First(&throttle).Error; err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// RecordFailure counts a failed login and returns the updated counter.
+// Failures from before windowStart are forgotten and the count starts over.
+func (r *LoginThrottleRepository) RecordFailure(scope, subject string, at, windowStart time.Time) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := r.DB.Raw(`INSERT INTO login_throttles (scope, subject, failures, last_failure_at, updated_at)
+		VALUES (?, ?, 1, ?, ?)
+		ON CONFLICT (scope, subject) DO UPDATE SET
+			failures = CASE WHEN login_throttles.last_failure_at < ? THEN 1 ELSE login_throttles.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING *`, scope, subject, at, at, windowStart).Scan(&throttle).Error
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+func (r *LoginThrottleRepository) Lock(scope, subject string, until time.Time) error {
+	return r.DB.Model(&models.LoginThrottle{}).
+		Where("scope = ? AND subject = ?", scope, subject).
+		Update("locked_until", until).Error
+}
+
+// Clear forgets the failed logins of an email or IP, lifting any lockout
+func (r *LoginThrottleRepository) Clear(scope, subject string) error {
+	return r.DB.Where("scope = ? AND subject = ?", scope, subject).Delete(&models.LoginThrottle{}).Error
+}
+
+// PurgeExpired drops counters whose last failure is older than the cutoff
+// and that no longer lock anyone out
+func (r *LoginThrottleRepository) PurgeExpired(before time.Time) (int64, error) {
+	result := r.DB.Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, before).
+		Delete(&models.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
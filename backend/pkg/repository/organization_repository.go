@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"github.com/robaa12/mawid/pkg/models"
+	"gorm.io/gorm"
+)
+
+type OrganizationRepository struct {
+	DB *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) *OrganizationRepository {
+	return &OrganizationRepository{DB: db}
+}
+
+func (r *OrganizationRepository) WithTx(tx *gorm.DB) *OrganizationRepository {
+	return &OrganizationRepository{DB: tx}
+}
+
+func (r *OrganizationRepository) Create(org *models.Organization) error {
+	return r.DB.Create(org).Error
+}
+
+func (r *OrganizationRepository) GetByID(id uint) (*models.Organization, error) {
+	var org models.Organization
+	if err := r.DB.First(&org, id).Error; err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *OrganizationRepository) GetAll() ([]models.Organization, error) {
+	var orgs []models.Organization
+	err := r.DB.Order("name ASC").Find(&orgs).Error
+	return orgs, err
+}
+
+func (r *OrganizationRepository) Update(org *models.Organization) error {
+	return r.DB.Save(org).Error
+}
+
+func (r *OrganizationRepository) Delete(id uint) error {
+	return r.DB.Delete(&models.Organization{}, id).Error
+}
+
+// GetOrganizers lists the users who manage an organization
+func (r *OrganizationRepository) GetOrganizers(orgID uint) ([]models.User, error) {
+	var users []models.User
+	err := r.DB.Where("organization_id = ?", orgID).Order("name ASC").Find(&users).Error
+	return users, err
+}
+
+// CountOwned counts the events and categories an organization still owns
+func (r *OrganizationRepository) CountOwned(orgID uint) (int64, error) {
+	var events, categories int64
+	if err := r.DB.Model(&models.Event{}).Where("organization_id = ?", orgID).Count(&events).Error; err != nil {
+		return 0, err
+	}
+	if err := r.DB.Model(&models.Category{}).Where("organization_id = ?", orgID).Count(&categories).Error; err != nil {
+		return 0, err
+	}
+	return events + categories, nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/robaa12/mawid/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{DB: db}
+}
+
+// WithTx returns a repository that runs its queries inside the given transaction
+func (r *TokenRepository) WithTx(tx *gorm.DB) *TokenRepository {
+	return &TokenRepository{DB: tx}
+}
+
+func (r *TokenRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	return r.DB.Create(token).Error
+}
+
+// GetRefreshTokenForUpdate looks a refresh token up by its hash and locks it
+// until the transaction ends, so it cannot be rotated twice at once
+func (r *TokenRepository) GetRefreshTokenForUpdate(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *TokenRepository) GetRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.DB.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkRefreshTokenReplaced retires a refresh token that was rotated into
+// another one
+func (r *TokenRepository) MarkRefreshTokenReplaced(id, replacedByID uint, at time.Time) error {
+	return r.DB.Model(&models.RefreshToken{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"revoked_at": at, "replaced_by_id": replacedByID}).Error
+}
+
+// RevokeFamily revokes every refresh token descending from the same login
+// along with the access tokens issued with them
+func (r *TokenRepository) RevokeFamily(familyID string, at time.Time) error {
+	return r.revokeRefreshTokens("family_id", familyID, at)
+}
+
+// RevokeUserTokens revokes every session of a user
+func (r *TokenRepository) RevokeUserTokens(userID uint, at time.Time) error {
+	return r.revokeRefreshTokens("user_id", userID, at)
+}
+
+func (r *TokenRepository) revokeRefreshTokens(column string, value interface{}, at time.Time) error {
+	var tokens []models.RefreshToken
+	err := r.DB.Model(&models.RefreshToken{}).
+		Where(column+" = ?", value).
+		Where("access_expires_at > ?", at).
+		Find(&tokens).Error
+	if err != nil {
+		return err
+	}
+
+	revoked := make([]models.RevokedToken, 0, len(tokens))
+	for _, token := range tokens {
+		revoked = append(revoked, models.RevokedToken{TokenID: token.AccessTokenID, ExpiresAt: token.AccessExpiresAt})
+	}
+	if err := r.RevokeAccessTokens(revoked); err != nil {
+		return err
+	}
+
+	return r.DB.Model(&models.RefreshToken{}).
+		Where(column+" = ?", value).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", at).Error
+}
+
+// RevokeAccessTokens puts access tokens on the deny list. Tokens already on
+// it are left as they are.
+func (r *TokenRepository) RevokeAccessTokens(tokens []models.RevokedToken) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&tokens).Error
+}
+
+func (r *TokenRepository) IsAccessTokenRevoked(tokenID string) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.RevokedToken{}).Where("token_id = ?", tokenID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *TokenRepository) CreatePasswordResetToken(token *models.PasswordResetToken) error {
+	return r.DB.Create(token).Error
+}
+
+// GetPasswordResetTokenForUpdate looks a password reset token up by its hash
+// and locks it, so it cannot be redeemed twice at once
+func (r *TokenRepository) GetPasswordResetTokenForUpdate(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// UsePasswordResetTokens spends every outstanding password reset token of
+// the user, so links mailed before a password change stop working
+func (r *TokenRepository) UsePasswordResetTokens(userID uint, at time.Time) error {
+	return r.DB.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", at).Error
+}
+
+// PurgeExpired drops refresh tokens, password reset tokens and deny list
+// entries that expired before the cutoff, as they can no longer be used
+// either way
+func (r *TokenRepository) PurgeExpired(before time.Time) (int64, error) {
+	revoked := r.DB.Where("expires_at < ?", before).Delete(&models.RevokedToken{})
+	if revoked.Error != nil {
+		return 0, revoked.Error
+	}
+
+	resets := r.DB.Where("expires_at < ?", before).Delete(&models.PasswordResetToken{})
+	if resets.Error != nil {
+		return 0, resets.Error
+	}
+
+	refresh := r.DB.Where("expires_at < ?", before).Delete(&models.RefreshToken{})
+	return revoked.RowsAffected + resets.RowsAffected + refresh.RowsAffected, refresh.Error
+}
@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/robaa12/mawid/pkg/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -13,6 +16,11 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{DB: db}
 }
 
+// WithTx returns a repository that runs its queries inside the given transaction
+func (r *UserRepository) WithTx(tx *gorm.DB) *UserRepository {
+	return &UserRepository{DB: tx}
+}
+
 func (r *UserRepository) Create(user *models.User) error {
 	return r.DB.Create(user).Error
 }
@@ -28,15 +36,17 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 
 func (r *UserRepository) GetByID(id uint) (*models.User, error) {
 	var user models.User
-	err := r.DB.First(&user, id).Error
+	err := r.DB.Preload("Organization").First(&user, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+// Update saves the user's own columns. The organization is managed through
+// its own repository.
 func (r *UserRepository) Update(user *models.User) error {
-	return r.DB.Save(user).Error
+	return r.DB.Omit(clause.Associations).Save(user).Error
 }
 
 func (r *UserRepository) GetAllUsers() ([]models.User, error) {
@@ -48,15 +58,41 @@ func (r *UserRepository) GetAllUsers() ([]models.User, error) {
 	return users, nil
 }
 
+// SetRole changes a user's role and the organization they manage
+func (r *UserRepository) SetRole(userID uint, role models.Role, organizationID *uint) error {
+	return r.DB.Model(&models.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"role": role, "organization_id": organizationID}).Error
+}
+
+// MarkEmailVerified records that the user confirmed email, unless the
+// address changed since the link was sent
+func (r *UserRepository) MarkEmailVerified(userID uint, email string, at time.Time) (bool, error) {
+	result := r.DB.Model(&models.User{}).
+		Where("id = ? AND email = ?", userID, email).
+		Update("email_verified_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// SetPassword stores an already hashed password
+func (r *UserRepository) SetPassword(userID uint, hashedPassword string) error {
+	return r.DB.Model(&models.User{}).Where("id = ?", userID).Update("password", hashedPassword).Error
+}
+
+func (r *UserRepository) SetVerificationSentAt(userID uint, at time.Time) error {
+	return r.DB.Model(&models.User{}).Where("id = ?", userID).Update("verification_sent_at", at).Error
+}
+
 func (r *UserRepository) CreateAdminIfNotExists(adminEmail string) error {
 	var count int64
 	r.DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&count)
 	if count == 0 {
+		verifiedAt := time.Now()
 		admin := models.User{
-			Name:     "Admin",
-			Email:    adminEmail,
-			Password: "admin123", // This will be hashed by the BeforeCreate hook
-			Role:     models.RoleAdmin,
+			Name:            "Admin",
+			Email:           adminEmail,
+			Password:        "admin123", // This will be hashed by the BeforeCreate hook
+			Role:            models.RoleAdmin,
+			EmailVerifiedAt: &verifiedAt,
 		}
 		return r.Create(&admin)
 	}
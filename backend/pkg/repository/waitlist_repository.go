@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/robaa12/mawid/pkg/models"
+	"gorm.io/gorm"
+)
+
+type WaitlistRepository struct {
+	DB *gorm.DB
+}
+
+func NewWaitlistRepository(db *gorm.DB) *WaitlistRepository {
+	return &WaitlistRepository{DB: db}
+}
+
+// WithTx returns a repository that runs its queries inside the given transaction
+func (r *WaitlistRepository) WithTx(tx *gorm.DB) *WaitlistRepository {
+	return &WaitlistRepository{DB: tx}
+}
+
+func (r *WaitlistRepository) Create(entry *models.WaitlistEntry) error {
+	return r.DB.Create(entry).Error
+}
+
+func (r *WaitlistRepository) GetByID(id uint) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	if err := r.DB.Preload("Event").Preload("User").First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// GetActiveEntry returns the user's waiting or offered entry for an event, if any
+func (r *WaitlistRepository) GetActiveEntry(userID, eventID uint) (bool, *models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+
+	result := r.DB.Where("user_id = ? AND event_id = ? AND status IN ?", userID, eventID,
+		[]models.WaitlistStatus{models.WaitlistStatusWaiting, models.WaitlistStatusOffered}).First(&entry)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return false, nil, nil
+		}
+		return false, nil, result.Error
+	}
+	return true, &entry, nil
+}
+
+func (r *WaitlistRepository) GetUserEntries(userID uint) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	err := r.DB.Where("user_id = ?", userID).
+		Preload("Event").
+		Order("created_at DESC").
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *WaitlistRepository) GetEventEntries(eventID uint) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	err := r.DB.Where("event_id = ?", eventID).
+		Preload("User").
+		Order("id ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// CountWaitingBefore returns how many users are queued ahead of the given entry
+func (r *WaitlistRepository) CountWaitingBefore(eventID, entryID uint) (int64, error) {
+	var count int64
+	err := r.DB.Model(&models.WaitlistEntry{}).
+		Where("event_id = ? AND status = ? AND id < ?", eventID, models.WaitlistStatusWaiting, entryID).
+		Count(&count).Error
+	return count, err
+}
+
+// CountActiveOffers returns the unexpired offers for an event, ignoring the
+// given user's own offer. Pass zero to count every offer.
+func (r *WaitlistRepository) CountActiveOffers(eventID, excludeUserID uint) (int64, error) {
+	var count int64
+	query := r.DB.Model(&models.WaitlistEntry{}).
+		Where("event_id = ? AND status = ? AND offer_expires_at > ?", eventID, models.WaitlistStatusOffered, time.Now())
+	if excludeUserID != 0 {
+		query = query.Where("user_id <> ?", excludeUserID)
+	}
+
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// NextWaiting returns up to limit entries at the head of an event's queue
+func (r *WaitlistRepository) NextWaiting(eventID uint, limit int) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	err := r.DB.Where("event_id = ? AND status = ?", eventID, models.WaitlistStatusWaiting).
+		Order("id ASC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *WaitlistRepository) Offer(id uint, expiresAt time.Time) error {
+	now := time.Now()
+	return r.DB.Model(&models.WaitlistEntry{}).Where("id = ?", id).Updates(map[string]any{
+		"status":           models.WaitlistStatusOffered,
+		"offered_at":       now,
+		"offer_expires_at": expiresAt,
+	}).Error
+}
+
+func (r *WaitlistRepository) UpdateStatus(id uint, status models.WaitlistStatus) error {
+	return r.DB.Model(&models.WaitlistEntry{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// MarkClaimed closes any open entry the user has for an event once they hold a booking
+func (r *WaitlistRepository) MarkClaimed(userID, eventID uint) error {
+	return r.DB.Model(&models.WaitlistEntry{}).
+		Where("user_id = ? AND event_id = ? AND status IN ?", userID, eventID,
+			[]models.WaitlistStatus{models.WaitlistStatusWaiting, models.WaitlistStatusOffered}).
+		Update("status", models.WaitlistStatusClaimed).Error
+}
+
+// ExpireOffers marks lapsed offers as expired and returns the affected event IDs
+func (r *WaitlistRepository) ExpireOffers(now time.Time) ([]uint, error) {
+	var eventIDs []uint
+	err := r.DB.Model(&models.WaitlistEntry{}).
+		Where("status = ? AND offer_expires_at <= ?", models.WaitlistStatusOffered, now).
+		Distinct("event_id").
+		Pluck("event_id", &eventIDs).Error
+	if err != nil || len(eventIDs) == 0 {
+		return nil, err
+	}
+
+	err = r.DB.Model(&models.WaitlistEntry{}).
+		Where("status = ? AND offer_expires_at <= ?", models.WaitlistStatusOffered, now).
+		Update("status", models.WaitlistStatusExpired).Error
+	return eventIDs, err
+}
+
+// EventsWithWaiting returns the IDs of events that still have users queued
+func (r *WaitlistRepository) EventsWithWaiting() ([]uint, error) {
+	var eventIDs []uint
+	err := r.DB.Model(&models.WaitlistEntry{}).
+		Where("status = ?", models.WaitlistStatusWaiting).
+		Distinct("event_id").
+		Pluck("event_id", &eventIDs).Error
+	return eventIDs, err
+}
+
+// CloseByEvent expires every waiting or offered entry of an event
+func (r *WaitlistRepository) CloseByEvent(eventID uint) error {
+	return r.DB.Model(&models.WaitlistEntry{}).
+		Where("event_id = ? AND status IN ?", eventID, []models.WaitlistStatus{models.WaitlistStatusWaiting, models.WaitlistStatusOffered}).
+		Update("status", models.WaitlistStatusExpired).Error
+}
+
+// CloseByUser expires every waiting or offered entry of a user
+func (r *WaitlistRepository) CloseByUser(userID uint) error {
+	return r.DB.Model(&models.WaitlistEntry{}).
+		Where("user_id = ? AND status IN ?", userID, []models.WaitlistStatus{models.WaitlistStatusWaiting, models.WaitlistStatusOffered}).
+		Update("status", models.WaitlistStatusExpired).Error
+}
+
+func (r *WaitlistRepository) DeleteByEvent(eventID uint) error {
+	return r.DB.Where("event_id = ?", eventID).Delete(&models.WaitlistEntry{}).Error
+}
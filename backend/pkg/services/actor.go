@@ -0,0 +1,49 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/robaa12/mawid/pkg/models"
+)
+
+// ErrForbidden is returned when the caller may not manage a record
+var ErrForbidden = errors.New("you do not have permission to manage this resource")
+
+// Actor is the authenticated caller of a management operation. Platform
+// admins manage every organization; organizers only manage their own.
+type Actor struct {
+	UserID         uint
+	Role           models.Role
+	OrganizationID *uint
+}
+
+func (a Actor) IsAdmin() bool {
+	return a.Role == models.RoleAdmin
+}
+
+// IsOrganizer reports whether the actor manages an organization
+func (a Actor) IsOrganizer() bool {
+	return a.Role == models.RoleOrganizer && a.OrganizationID != nil
+}
+
+// CanManage reports whether the actor may manage a record owned by the given
+// organization. Records without an organization belong to the platform and
+// are managed by admins only.
+func (a Actor) CanManage(organizationID *uint) bool {
+	if a.IsAdmin() {
+		return true
+	}
+	return a.IsOrganizer() && organizationID != nil && *organizationID == *a.OrganizationID
+}
+
+// scope returns the organization the actor's queries are limited to, nil for
+// admins. Callers that are neither admins nor organizers are refused.
+func (a Actor) scope() (*uint, error) {
+	if a.IsAdmin() {
+		return nil, nil
+	}
+	if a.IsOrganizer() {
+		return a.OrganizationID, nil
+	}
+	return nil, ErrForbidden
+}
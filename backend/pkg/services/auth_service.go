@@ -4,18 +4,46 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/url"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/robaa12/mawid/config"
 	"github.com/robaa12/mawid/internal/utils"
 	"github.com/robaa12/mawid/pkg/models"
 	"github.com/robaa12/mawid/pkg/repository"
+	"gorm.io/gorm"
 )
 
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when a refresh token that was
+	// already exchanged is presented again. The session it belongs to is
+	// revoked, since either the client or an attacker holds a stolen copy.
+	ErrRefreshTokenReused = errors.New("refresh token was already used, please log in again")
+
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification link")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrVerificationRecentlySent = errors.New("a verification email was sent recently, please try again later")
+)
+
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+// verificationResendInterval is how long a user waits before another
+// verification email is sent to them
+const verificationResendInterval = time.Minute
+
 type AuthService struct {
-	UserRepo *repository.UserRepository
-	Config   *config.Config
+	UserRepo       *repository.UserRepository
+	OrgRepo        *repository.OrganizationRepository
+	TokenRepo      *repository.TokenRepository
+	ThrottleRepo   *repository.LoginThrottleRepository
+	BookingRepo    *repository.BookingRepository
+	WaitlistRepo   *repository.WaitlistRepository
+	StorageService *utils.StorageService
+	Mailer         utils.Mailer
+	Config         *config.Config
 }
 
 type RegisterInput struct {
@@ -29,17 +57,53 @@ type LoginInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type OrganizationInput struct {
+	Name        string `json:"name" binding:"required,max=150"`
+	Description string `json:"description"`
+}
+
+type AssignOrganizerInput struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutInput optionally names the refresh token to revoke. Without one the
+// session the access token was issued with is revoked.
+type LogoutInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Session identifies the access token a request was authenticated with
+type Session struct {
+	UserID    uint
+	TokenID   string
+	SessionID string
+	ExpiresAt time.Time
+}
+
 type AuthResponse struct {
-	Token     string       `json:"token"`
-	User      *models.User `json:"user"`
-	ExpiresAt int64        `json:"expires_at"`
-	TokenType string       `json:"token_type"`
+	Token            string       `json:"token"`
+	RefreshToken     string       `json:"refresh_token"`
+	User             *models.User `json:"user"`
+	ExpiresAt        int64        `json:"expires_at"`
+	RefreshExpiresAt int64        `json:"refresh_expires_at"`
+	TokenType        string       `json:"token_type"`
 }
 
-func NewAuthService(userRepo *repository.UserRepository, cfg *config.Config) *AuthService {
+func NewAuthService(userRepo *repository.UserRepository, orgRepo *repository.OrganizationRepository, tokenRepo *repository.TokenRepository, throttleRepo *repository.LoginThrottleRepository, bookingRepo *repository.BookingRepository, waitlistRepo *repository.WaitlistRepository, storageService *utils.StorageService, mailer utils.Mailer, cfg *config.Config) *AuthService {
 	return &AuthService{
-		UserRepo: userRepo,
-		Config:   cfg,
+		UserRepo:       userRepo,
+		OrgRepo:        orgRepo,
+		TokenRepo:      tokenRepo,
+		ThrottleRepo:   throttleRepo,
+		BookingRepo:    bookingRepo,
+		WaitlistRepo:   waitlistRepo,
+		StorageService: storageService,
+		Mailer:         mailer,
+		Config:         cfg,
 	}
 }
 
@@ -47,7 +111,6 @@ func (s *AuthService) Register(input RegisterInput) (*AuthResponse, error) {
 	input.Email = strings.TrimSpace(strings.ToLower(input.Email))
 	input.Name = strings.TrimSpace(input.Name)
 
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	if !emailRegex.MatchString(input.Email) {
 		return nil, errors.New("invalid email format")
 	}
@@ -61,7 +124,7 @@ func (s *AuthService) Register(input RegisterInput) (*AuthResponse, error) {
 	existingUser, err := s.UserRepo.GetByEmail(input.Email)
 	if err == nil && existingUser != nil {
 		log.Printf("Registration attempt with existing email: %s", input.Email)
-		return nil, errors.New("user with this email already exists")
+		return nil, ErrEmailTaken
 	}
 
 	user := models.User{
@@ -73,61 +136,285 @@ func (s *AuthService) Register(input RegisterInput) (*AuthResponse, error) {
 
 	if err := s.UserRepo.Create(&user); err != nil {
 		log.Printf("Error creating user: %v", err)
-		return nil, fmt.Errorf("failed to create user: %w", err)
+		return nil, utils.Errorf("failed to create user: %w", err)
 	}
 
-	token, expiresAt, err := utils.GenerateJWT(user, s.Config)
+	response, _, err := s.issueSession(s.TokenRepo, user, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, utils.Errorf("failed to generate token: %w", err)
+	}
+
+	// The account works without verification, so a mail failure does not
+	// fail the registration; the user can ask for the link again
+	if err := s.sendVerificationEmail(&user); err != nil {
+		log.Printf("Error sending verification email to %s: %v", user.Email, err)
 	}
 
 	log.Printf("User registered successfully: %s", user.Email)
-	return &AuthResponse{
-		Token:     token,
-		User:      &user,
-		ExpiresAt: expiresAt.Unix(),
-		TokenType: "Bearer",
-	}, nil
+	return response, nil
 }
 
-func (s *AuthService) Login(input LoginInput) (*AuthResponse, error) {
+// Login checks the credentials of a user logging in from clientIP. Failed
+// attempts are throttled per email and per IP, the same way whether or not
+// the email is registered.
+func (s *AuthService) Login(input LoginInput, clientIP string) (*AuthResponse, error) {
 	// Sanitize input
 	input.Email = strings.TrimSpace(strings.ToLower(input.Email))
+	now := time.Now()
+
+	if err := s.checkLoginThrottle(input.Email, clientIP, now); err != nil {
+		log.Printf("Throttled login attempt for %s from IP %s: %v", input.Email, clientIP, err)
+		return nil, err
+	}
 
 	// Find user by email
 	user, err := s.UserRepo.GetByEmail(input.Email)
 	if err != nil {
 		log.Printf("Login attempt for non-existent user: %s", input.Email)
+		_ = dummyUser.CheckPassword(input.Password)
+		s.recordLoginFailure(input.Email, clientIP, now)
 		return nil, errors.New("invalid email or password")
 	}
 
 	// Check password
 	if err := user.CheckPassword(input.Password); err != nil {
 		log.Printf("Failed login attempt for user: %s", user.Email)
+		s.recordLoginFailure(input.Email, clientIP, now)
 		return nil, errors.New("invalid email or password")
 	}
 
-	// Generate JWT token with expiration time
-	token, expiresAt, err := utils.GenerateJWT(*user, s.Config)
+	if err := s.ThrottleRepo.Clear(models.LoginThrottleEmail, throttleSubject(input.Email)); err != nil {
+		log.Printf("Error clearing failed logins for %s: %v", input.Email, err)
+	}
+
+	// Every login starts a new session with its own refresh token family
+	response, _, err := s.issueSession(s.TokenRepo, *user, "")
 	if err != nil {
 		log.Printf("Token generation error for user %s: %v", user.Email, err)
-		return nil, fmt.Errorf("authentication error: %w", err)
+		return nil, utils.Errorf("authentication error: %w", err)
 	}
 
 	log.Printf("Successful login for user: %s", user.Email)
+	return response, nil
+}
+
+// VerifyEmail confirms the email address a verification link was sent to.
+// Links sent before the user changed their email no longer work.
+func (s *AuthService) VerifyEmail(token string) (*models.User, error) {
+	claims, err := utils.ValidateEmailVerificationToken(token, s.Config)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.UserRepo.GetByID(claims.UserID)
+	if err != nil || user.Email != claims.Email {
+		return nil, ErrInvalidVerificationToken
+	}
+	if user.IsEmailVerified() {
+		return user, nil
+	}
+
+	now := time.Now()
+	verified, err := s.UserRepo.MarkEmailVerified(user.ID, claims.Email, now)
+	if err != nil {
+		return nil, utils.Errorf("failed to verify email: %w", err)
+	}
+	if !verified {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	log.Printf("Email verified for user %d: %s", user.ID, user.Email)
+	user.EmailVerifiedAt = &now
+	return user, nil
+}
+
+// ResendVerification sends the user a new verification link, at most once
+// per verificationResendInterval
+func (s *AuthService) ResendVerification(userID uint) error {
+	user, err := s.UserRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < verificationResendInterval {
+		return ErrVerificationRecentlySent
+	}
+
+	return s.sendVerificationEmail(user)
+}
+
+func (s *AuthService) sendVerificationEmail(user *models.User) error {
+	token, err := utils.GenerateEmailVerificationToken(user.ID, user.Email, s.Config)
+	if err != nil {
+		return err
+	}
+
+	link := strings.TrimRight(s.Config.AppBaseURL, "/") + "/api/v1/auth/verify-email?token=" + url.QueryEscape(token)
+	msg := utils.MailMessage{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\n"+
+			"The link expires in %d hours. If you did not sign up for Mawid, you can ignore this email.\n",
+			user.Name, link, int(s.Config.EmailVerificationTTL.Hours())),
+	}
+	if err := s.Mailer.Send(msg); err != nil {
+		return err
+	}
+	return s.UserRepo.SetVerificationSentAt(user.ID, time.Now())
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. The old refresh token is spent; presenting it again revokes the
+// whole session.
+func (s *AuthService) Refresh(input RefreshInput) (*AuthResponse, error) {
+	var response *AuthResponse
+	reused := false
+
+	err := s.TokenRepo.DB.Transaction(func(tx *gorm.DB) error {
+		tokens := s.TokenRepo.WithTx(tx)
+		current, err := tokens.GetRefreshTokenForUpdate(utils.HashToken(input.RefreshToken))
+		if err != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		now := time.Now()
+		if current.RevokedAt != nil {
+			if current.ReplacedByID == nil {
+				return ErrInvalidRefreshToken
+			}
+			reused = true
+			log.Printf("Refresh token reuse detected for user %d, revoking session %s", current.UserID, current.FamilyID)
+			return tokens.RevokeFamily(current.FamilyID, now)
+		}
+		if !current.ExpiresAt.After(now) {
+			return ErrInvalidRefreshToken
+		}
+
+		// The user is read again so role changes reach the new token
+		user, err := s.UserRepo.GetByID(current.UserID)
+		if err != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		var next *models.RefreshToken
+		response, next, err = s.issueSession(tokens, *user, current.FamilyID)
+		if err != nil {
+			return err
+		}
+		return tokens.MarkRefreshTokenReplaced(current.ID, next.ID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, ErrRefreshTokenReused
+	}
+	return response, nil
+}
+
+// Logout revokes the access token of the request and the session it
+// belongs to, or the session of the given refresh token if it is the
+// user's
+func (s *AuthService) Logout(session Session, input LogoutInput) error {
+	now := time.Now()
+	return s.TokenRepo.DB.Transaction(func(tx *gorm.DB) error {
+		tokens := s.TokenRepo.WithTx(tx)
+		if session.TokenID != "" {
+			revoked := []models.RevokedToken{{TokenID: session.TokenID, ExpiresAt: session.ExpiresAt}}
+			if err := tokens.RevokeAccessTokens(revoked); err != nil {
+				return err
+			}
+		}
+
+		familyID := session.SessionID
+		if input.RefreshToken != "" {
+			token, err := tokens.GetRefreshToken(utils.HashToken(input.RefreshToken))
+			if err != nil || token.UserID != session.UserID {
+				return ErrInvalidRefreshToken
+			}
+			familyID = token.FamilyID
+		}
+		if familyID == "" {
+			return nil
+		}
+
+		log.Printf("User %d logged out of session %s", session.UserID, familyID)
+		return tokens.RevokeFamily(familyID, now)
+	})
+}
+
+// IsTokenRevoked reports whether an access token was revoked before its
+// expiry. Tokens without an ID predate revocation and cannot be revoked.
+func (s *AuthService) IsTokenRevoked(tokenID string) (bool, error) {
+	if tokenID == "" {
+		return false, nil
+	}
+	return s.TokenRepo.IsAccessTokenRevoked(tokenID)
+}
+
+// PurgeExpiredTokens drops refresh tokens and revocations of access tokens
+// that have expired anyway
+func (s *AuthService) PurgeExpiredTokens() (int64, error) {
+	return s.TokenRepo.PurgeExpired(time.Now())
+}
+
+// issueSession issues an access token and a refresh token for the user. An
+// empty familyID starts a new session; otherwise the tokens continue the
+// given one.
+func (s *AuthService) issueSession(tokens *repository.TokenRepository, user models.User, familyID string) (*AuthResponse, *models.RefreshToken, error) {
+	tokenID, err := utils.NewTokenID()
+	if err != nil {
+		return nil, nil, err
+	}
+	if familyID == "" {
+		if familyID, err = utils.NewTokenID(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	accessToken, expiresAt, err := utils.GenerateJWT(user, tokenID, familyID, s.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, err := utils.NewOpaqueToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ttl := s.Config.RefreshTokenTTL
+	if ttl <= 0 {
+		ttl = 30 * 24 * time.Hour
+	}
+	record := &models.RefreshToken{
+		UserID:          user.ID,
+		FamilyID:        familyID,
+		TokenHash:       utils.HashToken(refreshToken),
+		AccessTokenID:   tokenID,
+		AccessExpiresAt: expiresAt,
+		ExpiresAt:       time.Now().Add(ttl),
+	}
+	if err := tokens.CreateRefreshToken(record); err != nil {
+		return nil, nil, utils.Errorf("failed to store refresh token: %w", err)
+	}
+
 	return &AuthResponse{
-		Token:     token,
-		User:      user,
-		ExpiresAt: expiresAt.Unix(),
-		TokenType: "Bearer",
-	}, nil
+		Token:            accessToken,
+		RefreshToken:     refreshToken,
+		User:             &user,
+		ExpiresAt:        expiresAt.Unix(),
+		RefreshExpiresAt: record.ExpiresAt.Unix(),
+		TokenType:        "Bearer",
+	}, record, nil
 }
 
 func (s *AuthService) GetUserProfile(userID uint) (*models.User, error) {
 	user, err := s.UserRepo.GetByID(userID)
 	if err != nil {
 		log.Printf("Error fetching profile for user ID %d: %v", userID, err)
-		return nil, fmt.Errorf("failed to retrieve user profile: %w", err)
+		return nil, utils.Errorf("failed to retrieve user profile: %w", err)
 	}
 
 	// Log profile access for audit purposes
@@ -139,13 +426,146 @@ func (s *AuthService) GetAllUsers() ([]models.User, error) {
 	users, err := s.UserRepo.GetAllUsers()
 	if err != nil {
 		log.Printf("Error fetching all users: %v", err)
-		return nil, fmt.Errorf("failed to retrieve users: %w", err)
+		return nil, utils.Errorf("failed to retrieve users: %w", err)
 	}
 
 	log.Printf("Admin requested all users list (%d users retrieved)", len(users))
 	return users, nil
 }
 
+func (s *AuthService) GetAllOrganizations() ([]models.Organization, error) {
+	return s.OrgRepo.GetAll()
+}
+
+func (s *AuthService) CreateOrganization(input OrganizationInput) (*models.Organization, error) {
+	org := models.Organization{
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+	}
+
+	if err := s.OrgRepo.Create(&org); err != nil {
+		log.Printf("Error creating organization %s: %v", org.Name, err)
+		return nil, utils.Errorf("failed to create organization: %w", err)
+	}
+
+	log.Printf("Organization created: %s (ID: %d)", org.Name, org.ID)
+	return &org, nil
+}
+
+func (s *AuthService) UpdateOrganization(id uint, input OrganizationInput) (*models.Organization, error) {
+	org, err := s.OrgRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("organization not found")
+	}
+
+	org.Name = strings.TrimSpace(input.Name)
+	org.Description = input.Description
+
+	if err := s.OrgRepo.Update(org); err != nil {
+		return nil, utils.Errorf("failed to update organization: %w", err)
+	}
+	return org, nil
+}
+
+// DeleteOrganization removes an organization that no longer owns events or
+// categories. Its organizers become regular users and are logged out.
+func (s *AuthService) DeleteOrganization(id uint) error {
+	if _, err := s.OrgRepo.GetByID(id); err != nil {
+		return errors.New("organization not found")
+	}
+
+	owned, err := s.OrgRepo.CountOwned(id)
+	if err != nil {
+		return err
+	}
+	if owned > 0 {
+		return errors.New("organization still owns events or categories")
+	}
+
+	var demoted int
+	err = s.OrgRepo.DB.Transaction(func(tx *gorm.DB) error {
+		organizers, err := s.OrgRepo.WithTx(tx).GetOrganizers(id)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, user := range organizers {
+			if err := s.demoteOrganizer(tx, user.ID, now); err != nil {
+				return utils.Errorf("failed to demote organizer %d: %w", user.ID, err)
+			}
+		}
+		demoted = len(organizers)
+		return s.OrgRepo.WithTx(tx).Delete(id)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Organization %d deleted, %d organizers demoted", id, demoted)
+	return nil
+}
+
+func (s *AuthService) GetOrganizers(orgID uint) ([]models.User, error) {
+	if _, err := s.OrgRepo.GetByID(orgID); err != nil {
+		return nil, errors.New("organization not found")
+	}
+	return s.OrgRepo.GetOrganizers(orgID)
+}
+
+// AssignOrganizer makes a user an organizer of the organization. The new role
+// is carried by the tokens issued from the user's next login or refresh.
+func (s *AuthService) AssignOrganizer(orgID, userID uint) (*models.User, error) {
+	if _, err := s.OrgRepo.GetByID(orgID); err != nil {
+		return nil, errors.New("organization not found")
+	}
+
+	user, err := s.UserRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.Role == models.RoleAdmin {
+		return nil, errors.New("admins cannot be assigned to an organization")
+	}
+
+	if err := s.UserRepo.SetRole(userID, models.RoleOrganizer, &orgID); err != nil {
+		return nil, utils.Errorf("failed to assign organizer: %w", err)
+	}
+
+	log.Printf("User %s assigned as organizer of organization %d", user.Email, orgID)
+	return s.UserRepo.GetByID(userID)
+}
+
+// RemoveOrganizer turns an organizer of the organization back into a regular
+// user and logs them out
+func (s *AuthService) RemoveOrganizer(orgID, userID uint) error {
+	user, err := s.UserRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.Role != models.RoleOrganizer || user.OrganizationID == nil || *user.OrganizationID != orgID {
+		return errors.New("user is not an organizer of this organization")
+	}
+
+	err = s.UserRepo.DB.Transaction(func(tx *gorm.DB) error {
+		return s.demoteOrganizer(tx, userID, time.Now())
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("User %s removed as organizer of organization %d", user.Email, orgID)
+	return nil
+}
+
+// demoteOrganizer makes an organizer a regular user. Their sessions are
+// revoked too, as the tokens issued so far still carry the organizer role.
+func (s *AuthService) demoteOrganizer(tx *gorm.DB, userID uint, at time.Time) error {
+	if err := s.UserRepo.WithTx(tx).SetRole(userID, models.RoleUser, nil); err != nil {
+		return err
+	}
+	return s.TokenRepo.WithTx(tx).RevokeUserTokens(userID, at)
+}
+
 // Helper function to validate password strength
 func validatePasswordStrength(password string) error {
 	if len(password) < 8 {
@@ -2,37 +2,101 @@ package services
 
 import (
 	"errors"
+	"log"
+	"strings"
 	"time"
 
+	"github.com/robaa12/mawid/config"
+	"github.com/robaa12/mawid/internal/utils"
 	"github.com/robaa12/mawid/pkg/models"
 	"github.com/robaa12/mawid/pkg/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEventSoldOut       = errors.New("event is sold out")
+	ErrTicketLimitReached = errors.New("you have reached the ticket limit for this event")
+	ErrOfferExpired       = errors.New("your waitlist offer has expired")
+	ErrTicketTypeSoldOut  = errors.New("this ticket type is sold out")
+	ErrHoldExpired        = errors.New("your seat hold has expired")
+	ErrInvalidTransition  = errors.New("invalid booking status transition")
+	ErrStatusChanged      = errors.New("booking status was changed by another request")
+	ErrEventNotBookable   = errors.New("event is not open for booking")
+	ErrEmailNotVerified   = errors.New("please verify your email address before booking")
 )
 
 type BookingService struct {
-	BookingRepo *repository.BookingRepository
-	EventRepo   *repository.EventRepository
-	UserRepo    *repository.UserRepository
+	BookingRepo  *repository.BookingRepository
+	EventRepo    *repository.EventRepository
+	UserRepo     *repository.UserRepository
+	WaitlistRepo *repository.WaitlistRepository
+	Config       *config.Config
 }
 
 type (
 	CreateBookingInput struct {
+		EventID      uint            `json:"event_id" binding:"required"`
+		TicketTypeID uint            `json:"ticket_type_id"`
+		Quantity     int             `json:"quantity" binding:"omitempty,min=1"`
+		Attendees    []AttendeeInput `json:"attendees" binding:"omitempty,dive"`
+		JoinWaitlist bool            `json:"join_waitlist"`
+	}
+
+	AttendeeInput struct {
+		Name  string `json:"name" binding:"required"`
+		Email string `json:"email" binding:"omitempty,email"`
+	}
+
+	ClaimWaitlistInput struct {
+		TicketTypeID uint `json:"ticket_type_id"`
+	}
+
+	JoinWaitlistInput struct {
 		EventID uint `json:"event_id" binding:"required"`
 	}
 
 	UpdateBookingStatusInput struct {
 		Status string `json:"status" binding:"required"`
+		Reason string `json:"reason" binding:"max=500"`
+	}
+
+	BookingStatusHistoryResponse struct {
+		ID         uint       `json:"id"`
+		FromStatus string     `json:"from_status"`
+		ToStatus   string     `json:"to_status"`
+		ChangedBy  *UserBrief `json:"changed_by"`
+		Reason     string     `json:"reason,omitempty"`
+		CreatedAt  time.Time  `json:"created_at"`
 	}
 
 	BookingResponse struct {
-		ID          uint        `json:"id"`
-		UserID      uint        `json:"user_id"`
-		User        *UserBrief  `json:"user,omitempty"`
-		EventID     uint        `json:"event_id"`
-		Event       *EventBrief `json:"event,omitempty"`
-		BookingDate time.Time   `json:"booking_date"`
-		Status      string      `json:"status"`
-		CreatedAt   time.Time   `json:"created_at"`
-		UpdatedAt   time.Time   `json:"updated_at"`
+		ID            uint             `json:"id"`
+		UserID        uint             `json:"user_id"`
+		User          *UserBrief       `json:"user,omitempty"`
+		EventID       uint             `json:"event_id"`
+		Event         *EventBrief      `json:"event,omitempty"`
+		TicketType    *TicketTypeBrief `json:"ticket_type,omitempty"`
+		Quantity      int              `json:"quantity"`
+		PricePaid     float64          `json:"price_paid"`
+		Attendees     []AttendeeBrief  `json:"attendees"`
+		BookingDate   time.Time        `json:"booking_date"`
+		HoldExpiresAt *time.Time       `json:"hold_expires_at,omitempty"`
+		Status        string           `json:"status"`
+		CreatedAt     time.Time        `json:"created_at"`
+		UpdatedAt     time.Time        `json:"updated_at"`
+		DeletedAt     *time.Time       `json:"deleted_at,omitempty"`
+	}
+
+	AttendeeBrief struct {
+		ID    uint   `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email,omitempty"`
+	}
+
+	TicketTypeBrief struct {
+		ID    uint    `json:"id"`
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
 	}
 
 	UserBrief struct {
@@ -48,54 +112,140 @@ type (
 		ImageURL string `json:"image_url"`
 	}
 
+	WaitlistEntryResponse struct {
+		ID             uint        `json:"id"`
+		UserID         uint        `json:"user_id"`
+		User           *UserBrief  `json:"user,omitempty"`
+		EventID        uint        `json:"event_id"`
+		Event          *EventBrief `json:"event,omitempty"`
+		Status         string      `json:"status"`
+		Position       int         `json:"position,omitempty"`
+		OfferedAt      *time.Time  `json:"offered_at,omitempty"`
+		OfferExpiresAt *time.Time  `json:"offer_expires_at,omitempty"`
+		CreatedAt      time.Time   `json:"created_at"`
+	}
+
 	PaginatedBookings struct {
 		Bookings   []BookingResponse `json:"bookings"`
 		Total      int64             `json:"total"`
 		Page       int               `json:"page"`
 		PageSize   int               `json:"page_size"`
 		TotalPages int               `json:"total_pages"`
+		// NextCursor and PrevCursor are set for cursor pagination, which
+		// leaves Total, Page and TotalPages at zero
+		NextCursor string `json:"next_cursor,omitempty"`
+		PrevCursor string `json:"prev_cursor,omitempty"`
 	}
 )
 
-func NewBookingService(BookingRepo *repository.BookingRepository, eventRepo *repository.EventRepository, userRepo *repository.UserRepository) *BookingService {
-	return &BookingService{
-		BookingRepo: BookingRepo,
-		EventRepo:   eventRepo,
-		UserRepo:    userRepo,
+func NewBookingService(BookingRepo *repository.BookingRepository, eventRepo *repository.EventRepository, userRepo *repository.UserRepository, waitlistRepo *repository.WaitlistRepository, cfg *config.Config) *BookingService {
+	service := &BookingService{
+		BookingRepo:  BookingRepo,
+		EventRepo:    eventRepo,
+		UserRepo:     userRepo,
+		WaitlistRepo: waitlistRepo,
+		Config:       cfg,
 	}
+
+	// Release lapsed seat holds, expire unclaimed waitlist offers and hand
+	// freed seats to the next in line
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		for range ticker.C {
+			service.releaseExpiredHolds()
+			service.processWaitlists()
+		}
+	}()
+
+	return service
 }
 
 func (s *BookingService) CreateBooking(userID uint, input CreateBookingInput) (*BookingResponse, error) {
-	_, err := s.EventRepo.GetEventByID(input.EventID)
-	if err != nil {
-		return nil, errors.New("event not found")
+	var booking models.Booking
+
+	if err := s.requireVerifiedEmail(userID); err != nil {
+		return nil, err
 	}
 
-	hasBooking, existingBooking, err := s.BookingRepo.CheckUserBooking(userID, input.EventID)
+	attendees, err := s.buildAttendees(userID, &input)
 	if err != nil {
 		return nil, err
 	}
 
-	if hasBooking {
-		if existingBooking.Status == models.BookingStatusCancelled {
-			existingBooking.Status = models.BookingStatusConfirmed
-			existingBooking.BookingDate = time.Now()
-			if err := s.BookingRepo.UpdateStatus(existingBooking.ID, models.BookingStatusConfirmed); err != nil {
-				return nil, err
+	// The event row stays locked until commit, so concurrent requests for the
+	// same event are checked against capacity one at a time.
+	err = s.BookingRepo.DB.Transaction(func(tx *gorm.DB) error {
+		bookingRepo := s.BookingRepo.WithTx(tx)
+
+		event, err := s.EventRepo.WithTx(tx).GetEventForUpdate(input.EventID)
+		if err != nil {
+			return errors.New("event not found")
+		}
+
+		if !event.IsBookable(time.Now()) {
+			return ErrEventNotBookable
+		}
+
+		// Clear lapsed holds first so they neither block the user nor take seats
+		if _, err := bookingRepo.ReleaseExpiredHolds(event.ID); err != nil {
+			return err
+		}
+
+		hasBooking, _, err := bookingRepo.CheckUserBooking(userID, input.EventID)
+		if err != nil {
+			return err
+		}
+		if hasBooking {
+			return errors.New("you have already booked this event")
+		}
+
+		if err := s.checkAvailability(tx, event, userID, input.Quantity); err != nil {
+			return err
+		}
+
+		ticketType, err := s.resolveTicketType(tx, event, input.TicketTypeID)
+		if err != nil {
+			return err
+		}
+
+		// The seats are held while the user completes checkout; an unconfirmed
+		// hold is released by the background sweeper once it expires.
+		holdExpiresAt := time.Now().Add(s.Config.SeatHoldDuration)
+		unitPrice := event.Price
+		booking = models.Booking{
+			UserID:        userID,
+			EventID:       input.EventID,
+			BookingDate:   time.Now(),
+			Status:        models.BookingStatusPending,
+			HoldExpiresAt: &holdExpiresAt,
+			Quantity:      input.Quantity,
+			Attendees:     attendees,
+		}
+		if ticketType != nil {
+			if err := s.checkTicketQuota(tx, ticketType, input.Quantity); err != nil {
+				return err
 			}
-			return s.mapBookingToResponse(*existingBooking), nil
+			booking.TicketTypeID = &ticketType.ID
+			unitPrice = ticketType.Price
+		}
+		booking.PricePaid = unitPrice * float64(input.Quantity)
+		if err := bookingRepo.Create(&booking); err != nil {
+			return err
 		}
-		return nil, errors.New("you have already booked this event")
-	}
 
-	booking := models.Booking{
-		UserID:      userID,
-		EventID:     input.EventID,
-		BookingDate: time.Now(),
-		Status:      models.BookingStatusConfirmed,
-	}
+		if err := bookingRepo.AddStatusHistory(&models.BookingStatusHistory{
+			BookingID:   booking.ID,
+			ToStatus:    booking.Status,
+			ChangedByID: &userID,
+			Reason:      "booking created",
+		}); err != nil {
+			return err
+		}
 
-	if err := s.BookingRepo.Create(&booking); err != nil {
+		// A successful booking settles any place the user held in the queue
+		return s.WaitlistRepo.WithTx(tx).MarkClaimed(userID, input.EventID)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -119,6 +269,22 @@ func (s *BookingService) GetUserBookings(userID uint, page, pageSize int) (*Pagi
 	return s.createPaginatedResponse(bookings, total, page, pageSize)
 }
 
+// GetUserBookingsByCursor is GetUserBookings paged by an opaque cursor
+func (s *BookingService) GetUserBookingsByCursor(userID uint, cursor string, limit int) (*PaginatedBookings, error) {
+	position, err := repository.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	_, limit = s.normalizePagination(1, limit)
+
+	bookings, page, err := s.BookingRepo.GetUserBookingsByCursor(userID, position, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createCursorResponse(bookings, page, limit)
+}
+
 func (s *BookingService) CheckUserBooking(userID, eventID uint) (bool, *BookingResponse, error) {
 	hasBooking, booking, err := s.BookingRepo.CheckUserBooking(userID, eventID)
 	if err != nil {
@@ -133,30 +299,50 @@ func (s *BookingService) CheckUserBooking(userID, eventID uint) (bool, *BookingR
 	return true, bookingResp, nil
 }
 
-func (s *BookingService) UpdateBookingStatus(bookingID, userID uint, input UpdateBookingStatusInput) (*BookingResponse, error) {
+func (s *BookingService) UpdateBookingStatus(bookingID uint, actor Actor, input UpdateBookingStatusInput) (*BookingResponse, error) {
 	booking, err := s.BookingRepo.GetByID(bookingID)
 	if err != nil {
 		return nil, errors.New("booking not found")
 	}
 
-	if booking.UserID != userID {
+	actorID := actor.UserID
+	manager := actor.CanManage(booking.Event.OrganizationID)
+	if booking.UserID != actorID && !manager {
 		return nil, errors.New("unauthorized to update this booking")
 	}
 
-	var status models.BookingStatus
-	switch input.Status {
-	case string(models.BookingStatusConfirmed):
-		status = models.BookingStatusConfirmed
-	case string(models.BookingStatusCancelled):
-		status = models.BookingStatusCancelled
-	default:
+	status, ok := models.ParseBookingStatus(input.Status)
+	if !ok {
 		return nil, errors.New("invalid status")
 	}
 
-	if err := s.BookingRepo.UpdateStatus(bookingID, status); err != nil {
+	if !booking.Status.CanTransitionTo(status) {
+		return nil, utils.Errorf("%w: %s to %s", ErrInvalidTransition, booking.Status, status)
+	}
+
+	// Users may only confirm or cancel their own bookings; attendance and
+	// refunds are recorded by whoever manages the event.
+	if !manager && status != models.BookingStatusConfirmed && status != models.BookingStatusCancelled {
+		return nil, utils.Errorf("only the event's organizer can mark a booking as %s", status)
+	}
+
+	if status == models.BookingStatusConfirmed {
+		return s.confirmHold(booking, actorID, input.Reason)
+	}
+
+	err = s.BookingRepo.DB.Transaction(func(tx *gorm.DB) error {
+		return s.changeStatus(tx, booking, status, &actorID, input.Reason)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	if booking.Status.HoldsSeat() && !status.HoldsSeat() {
+		if err := s.promoteWaitlist(booking.EventID); err != nil {
+			log.Printf("[WAITLIST] Failed to promote waitlist for event %d: %v", booking.EventID, err)
+		}
+	}
+
 	updateBooking, err := s.BookingRepo.GetByID(bookingID)
 	if err != nil {
 		return nil, err
@@ -165,34 +351,389 @@ func (s *BookingService) UpdateBookingStatus(bookingID, userID uint, input Updat
 	return s.mapBookingToResponse(*updateBooking), nil
 }
 
-func (s *BookingService) GetAllBookings(page, pageSize int) (*PaginatedBookings, error) {
+// ConfirmBooking finalizes a pending booking while its seat hold is still valid
+func (s *BookingService) ConfirmBooking(bookingID, userID uint) (*BookingResponse, error) {
+	booking, err := s.BookingRepo.GetByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+
+	if booking.UserID != userID {
+		return nil, errors.New("unauthorized to update this booking")
+	}
+
+	if booking.Status != models.BookingStatusPending {
+		return nil, errors.New("only pending bookings can be confirmed")
+	}
+
+	return s.confirmHold(booking, userID, "")
+}
+
+// GetBookingHistory returns the status timeline of a booking, oldest first
+func (s *BookingService) GetBookingHistory(bookingID uint, actor Actor) ([]BookingStatusHistoryResponse, error) {
+	booking, err := s.BookingRepo.GetByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+
+	if booking.UserID != actor.UserID && !actor.CanManage(booking.Event.OrganizationID) {
+		return nil, errors.New("unauthorized to view this booking")
+	}
+
+	history, err := s.BookingRepo.GetStatusHistory(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]BookingStatusHistoryResponse, 0, len(history))
+	for _, h := range history {
+		resp := BookingStatusHistoryResponse{
+			ID:         h.ID,
+			FromStatus: string(h.FromStatus),
+			ToStatus:   string(h.ToStatus),
+			Reason:     h.Reason,
+			CreatedAt:  h.CreatedAt,
+		}
+		if h.ChangedBy != nil {
+			resp.ChangedBy = &UserBrief{
+				ID:    h.ChangedBy.ID,
+				Name:  h.ChangedBy.Name,
+				Email: h.ChangedBy.Email,
+			}
+		}
+		responses = append(responses, resp)
+	}
+
+	return responses, nil
+}
+
+// GetAllBookings lists bookings across the events the actor manages
+func (s *BookingService) GetAllBookings(actor Actor, page, pageSize int) (*PaginatedBookings, error) {
+	scope, err := actor.scope()
+	if err != nil {
+		return nil, err
+	}
+
+	page, pageSize = s.normalizePagination(page, pageSize)
+
+	bookings, total, err := s.BookingRepo.GetAllBookings(page, pageSize, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createPaginatedResponse(bookings, total, page, pageSize)
+}
+
+// GetAllBookingsByCursor is GetAllBookings paged by an opaque cursor
+func (s *BookingService) GetAllBookingsByCursor(actor Actor, cursor string, limit int) (*PaginatedBookings, error) {
+	scope, err := actor.scope()
+	if err != nil {
+		return nil, err
+	}
+
+	position, err := repository.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	_, limit = s.normalizePagination(1, limit)
+
+	bookings, page, err := s.BookingRepo.GetAllBookingsByCursor(position, limit, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createCursorResponse(bookings, page, limit)
+}
+
+// GetEventAttendees lists the bookings of an event the actor manages
+func (s *BookingService) GetEventAttendees(actor Actor, eventID uint, page, pageSize int) (*PaginatedBookings, error) {
+	if err := s.checkManagedEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+
 	page, pageSize = s.normalizePagination(page, pageSize)
-	var bookings []models.Booking
-	var total int64
-	
-	// First count total bookings
-	if err := s.BookingRepo.DB.Model(&models.Booking{}).Count(&total).Error; err != nil {
+
+	bookings, total, err := s.BookingRepo.GetEventBookings(eventID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createPaginatedResponse(bookings, total, page, pageSize)
+}
+
+// GetDeletedBookings lists the trashed bookings of the events the actor manages
+func (s *BookingService) GetDeletedBookings(actor Actor, page, pageSize int) (*PaginatedBookings, error) {
+	scope, err := actor.scope()
+	if err != nil {
 		return nil, err
 	}
-	
-	// Then get paginated bookings with all needed relations
-	offset := (page - 1) * pageSize
-	query := s.BookingRepo.DB.
-		Preload("User").
-		Preload("Event").
-		Offset(offset).
-		Limit(pageSize).
-		Order("created_at DESC")
-
-	if err := query.Find(&bookings).Error; err != nil {
+
+	page, pageSize = s.normalizePagination(page, pageSize)
+
+	bookings, total, err := s.BookingRepo.GetDeletedBookings(page, pageSize, scope)
+	if err != nil {
 		return nil, err
 	}
 
 	return s.createPaginatedResponse(bookings, total, page, pageSize)
 }
 
+// RestoreBooking takes a booking out of the trash. Bookings of a trashed
+// event come back by restoring the event, and a booking that holds seats is
+// only restored while the event still has room for it.
+func (s *BookingService) RestoreBooking(actor Actor, id uint) (*BookingResponse, error) {
+	booking, err := s.BookingRepo.GetDeletedBooking(id)
+	if err != nil || !actor.CanManage(booking.Event.OrganizationID) {
+		return nil, errors.New("booking not found in trash")
+	}
+
+	if booking.Event.DeletedAt.Valid {
+		return nil, errors.New("the booking's event is in the trash, restore the event instead")
+	}
+
+	err = s.BookingRepo.DB.Transaction(func(tx *gorm.DB) error {
+		event, err := s.EventRepo.WithTx(tx).GetEventForUpdate(booking.EventID)
+		if err != nil {
+			return errors.New("event not found")
+		}
+
+		if booking.Status.HoldsSeat() {
+			if err := s.checkAvailability(tx, event, booking.UserID, booking.Quantity); err != nil {
+				return err
+			}
+		}
+
+		return s.BookingRepo.WithTx(tx).Restore(id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	restored, err := s.BookingRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapBookingToResponse(*restored), nil
+}
+
+// checkManagedEvent makes sure the actor manages the event. Events of other
+// organizations are reported as not found.
+func (s *BookingService) checkManagedEvent(actor Actor, eventID uint) error {
+	scope, err := actor.scope()
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.EventRepo.GetOrganizationEvent(eventID, scope); err != nil {
+		return errors.New("event not found")
+	}
+	return nil
+}
+
 // Helper Methods
 
+// changeStatus moves a booking along its lifecycle and records who made the
+// change and why. The update only applies while the booking is still in the
+// status it was read in, so concurrent requests cannot skip a transition.
+func (s *BookingService) changeStatus(tx *gorm.DB, booking *models.Booking, to models.BookingStatus, actorID *uint, reason string) error {
+	if !booking.Status.CanTransitionTo(to) {
+		return utils.Errorf("%w: %s to %s", ErrInvalidTransition, booking.Status, to)
+	}
+
+	bookingRepo := s.BookingRepo.WithTx(tx)
+
+	changed, err := bookingRepo.TransitionStatus(booking.ID, booking.Status, to)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return ErrStatusChanged
+	}
+
+	return bookingRepo.AddStatusHistory(&models.BookingStatusHistory{
+		BookingID:   booking.ID,
+		FromStatus:  booking.Status,
+		ToStatus:    to,
+		ChangedByID: actorID,
+		Reason:      strings.TrimSpace(reason),
+	})
+}
+
+// confirmHold turns a pending booking into a confirmed one if its seat hold
+// has not expired yet
+func (s *BookingService) confirmHold(booking *models.Booking, actorID uint, reason string) (*BookingResponse, error) {
+	err := s.BookingRepo.DB.Transaction(func(tx *gorm.DB) error {
+		bookingRepo := s.BookingRepo.WithTx(tx)
+
+		confirmed, err := bookingRepo.ConfirmHold(booking.ID)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return ErrHoldExpired
+		}
+
+		return bookingRepo.AddStatusHistory(&models.BookingStatusHistory{
+			BookingID:   booking.ID,
+			FromStatus:  models.BookingStatusPending,
+			ToStatus:    models.BookingStatusConfirmed,
+			ChangedByID: &actorID,
+			Reason:      strings.TrimSpace(reason),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	confirmedBooking, err := s.BookingRepo.GetByID(booking.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mapBookingToResponse(*confirmedBooking), nil
+}
+
+// requireVerifiedEmail keeps users who have not confirmed their email from
+// taking seats
+func (s *BookingService) requireVerifiedEmail(userID uint) error {
+	user, err := s.UserRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !user.IsEmailVerified() {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// buildAttendees validates the seat count and returns one attendee per seat.
+// A single-seat booking without attendee details is made out to the user,
+// and the user is recognised by email among listed attendees.
+func (s *BookingService) buildAttendees(userID uint, input *CreateBookingInput) ([]models.Attendee, error) {
+	if input.Quantity == 0 {
+		input.Quantity = 1
+	}
+	if input.Quantity < 1 {
+		return nil, errors.New("quantity must be at least 1")
+	}
+	if input.Quantity > s.Config.MaxSeatsPerBooking {
+		return nil, utils.Errorf("a booking cannot include more than %d seats", s.Config.MaxSeatsPerBooking)
+	}
+
+	madeOutToUser := len(input.Attendees) == 0 && input.Quantity == 1
+	if !madeOutToUser && len(input.Attendees) != input.Quantity {
+		return nil, utils.Errorf("expected %d attendees, got %d", input.Quantity, len(input.Attendees))
+	}
+
+	user, err := s.UserRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if madeOutToUser {
+		return []models.Attendee{{UserID: &user.ID, Name: user.Name, Email: user.Email}}, nil
+	}
+
+	attendees := make([]models.Attendee, 0, len(input.Attendees))
+	holderFound := false
+	for _, a := range input.Attendees {
+		name := strings.TrimSpace(a.Name)
+		if name == "" {
+			return nil, errors.New("every attendee needs a name")
+		}
+		attendee := models.Attendee{
+			Name:  name,
+			Email: strings.TrimSpace(strings.ToLower(a.Email)),
+		}
+		if !holderFound && attendee.Email == user.Email {
+			attendee.UserID = &user.ID
+			holderFound = true
+		}
+		attendees = append(attendees, attendee)
+	}
+
+	return attendees, nil
+}
+
+// resolveTicketType finds the tier being bought. Events with tiers require one
+// to be chosen, while events without tiers are sold at their base price.
+func (s *BookingService) resolveTicketType(tx *gorm.DB, event *models.Event, ticketTypeID uint) (*models.TicketType, error) {
+	eventRepo := s.EventRepo.WithTx(tx)
+
+	if ticketTypeID == 0 {
+		count, err := eventRepo.CountTicketTypes(event.ID)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, errors.New("ticket type is required for this event")
+		}
+		return nil, nil
+	}
+
+	ticketType, err := eventRepo.GetTicketType(event.ID, ticketTypeID)
+	if err != nil {
+		return nil, errors.New("ticket type not found for this event")
+	}
+
+	if !ticketType.OnSale(time.Now()) {
+		return nil, errors.New("this ticket type is not on sale")
+	}
+
+	return ticketType, nil
+}
+
+// checkTicketQuota verifies that a tier has room for the requested seats. Like
+// checkAvailability it relies on the caller holding the event's row lock.
+func (s *BookingService) checkTicketQuota(tx *gorm.DB, ticketType *models.TicketType, seats int) error {
+	if ticketType.Quota == 0 {
+		return nil
+	}
+
+	sold, err := s.BookingRepo.WithTx(tx).CountActiveSeatsByTicketType(ticketType.ID)
+	if err != nil {
+		return err
+	}
+	if int(sold)+seats > ticketType.Quota {
+		return ErrTicketTypeSoldOut
+	}
+
+	return nil
+}
+
+// checkAvailability verifies that the event can take the requested seats for
+// the user. It must run inside the transaction holding the event's row lock.
+// Seats offered to other waitlisted users count as taken until their offer
+// expires, while the user's own offer is free for them to claim.
+func (s *BookingService) checkAvailability(tx *gorm.DB, event *models.Event, userID uint, seats int) error {
+	bookingRepo := s.BookingRepo.WithTx(tx)
+
+	if event.Capacity > 0 {
+		booked, err := bookingRepo.CountActiveSeats(event.ID)
+		if err != nil {
+			return err
+		}
+		offered, err := s.WaitlistRepo.WithTx(tx).CountActiveOffers(event.ID, userID)
+		if err != nil {
+			return err
+		}
+		if int(booked+offered)+seats > event.Capacity {
+			return ErrEventSoldOut
+		}
+	}
+
+	if event.MaxTicketsPerUser > 0 {
+		held, err := bookingRepo.CountUserActiveSeats(event.ID, userID)
+		if err != nil {
+			return err
+		}
+		if int(held)+seats > event.MaxTicketsPerUser {
+			return ErrTicketLimitReached
+		}
+	}
+
+	return nil
+}
+
 func (s *BookingService) normalizePagination(page, pageSize int) (int, int) {
 	if page < 1 {
 		page = 1
@@ -226,15 +767,36 @@ func (s *BookingService) createPaginatedResponse(bookings []models.Booking, tota
 	}, nil
 }
 
+func (s *BookingService) createCursorResponse(bookings []models.Booking, page repository.CursorPage, limit int) (*PaginatedBookings, error) {
+	result, err := s.createPaginatedResponse(bookings, 0, 0, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result.TotalPages = 0
+	result.NextCursor = page.Next
+	result.PrevCursor = page.Prev
+	return result, nil
+}
+
 func (s *BookingService) mapBookingToResponse(booking models.Booking) *BookingResponse {
 	response := &BookingResponse{
-		ID:          booking.ID,
-		UserID:      booking.UserID,
-		EventID:     booking.EventID,
-		BookingDate: booking.BookingDate,
-		Status:      string(booking.Status),
-		CreatedAt:   booking.CreatedAt,
-		UpdatedAt:   booking.UpdatedAt,
+		ID:            booking.ID,
+		UserID:        booking.UserID,
+		EventID:       booking.EventID,
+		BookingDate:   booking.BookingDate,
+		HoldExpiresAt: booking.HoldExpiresAt,
+		Quantity:      booking.Quantity,
+		PricePaid:     booking.PricePaid,
+		Attendees:     make([]AttendeeBrief, 0, len(booking.Attendees)),
+		Status:        string(booking.Status),
+		CreatedAt:     booking.CreatedAt,
+		UpdatedAt:     booking.UpdatedAt,
+	}
+
+	if booking.DeletedAt.Valid {
+		deletedAt := booking.DeletedAt.Time
+		response.DeletedAt = &deletedAt
 	}
 
 	// Add User details if available
@@ -256,5 +818,316 @@ func (s *BookingService) mapBookingToResponse(booking models.Booking) *BookingRe
 		}
 	}
 
+	for _, a := range booking.Attendees {
+		response.Attendees = append(response.Attendees, AttendeeBrief{
+			ID:    a.ID,
+			Name:  a.Name,
+			Email: a.Email,
+		})
+	}
+
+	// Add the purchased ticket tier if any
+	if booking.TicketType != nil {
+		response.TicketType = &TicketTypeBrief{
+			ID:    booking.TicketType.ID,
+			Name:  booking.TicketType.Name,
+			Price: booking.TicketType.Price,
+		}
+	}
+
 	return response
 }
+
+// Waitlist
+
+func (s *BookingService) JoinWaitlist(userID uint, input JoinWaitlistInput) (*WaitlistEntryResponse, error) {
+	var entry models.WaitlistEntry
+
+	if err := s.requireVerifiedEmail(userID); err != nil {
+		return nil, err
+	}
+
+	err := s.BookingRepo.DB.Transaction(func(tx *gorm.DB) error {
+		waitlistRepo := s.WaitlistRepo.WithTx(tx)
+
+		event, err := s.EventRepo.WithTx(tx).GetEventForUpdate(input.EventID)
+		if err != nil {
+			return errors.New("event not found")
+		}
+
+		if !event.IsBookable(time.Now()) {
+			return ErrEventNotBookable
+		}
+
+		hasBooking, _, err := s.BookingRepo.WithTx(tx).CheckUserBooking(userID, input.EventID)
+		if err != nil {
+			return err
+		}
+		if hasBooking {
+			return errors.New("you have already booked this event")
+		}
+
+		onWaitlist, _, err := waitlistRepo.GetActiveEntry(userID, input.EventID)
+		if err != nil {
+			return err
+		}
+		if onWaitlist {
+			return errors.New("you are already on the waitlist for this event")
+		}
+
+		// Only sold-out events have a queue; otherwise the user should just book
+		if err := s.checkAvailability(tx, event, userID, 1); !errors.Is(err, ErrEventSoldOut) {
+			if err != nil {
+				return err
+			}
+			return errors.New("event still has seats available")
+		}
+
+		entry = models.WaitlistEntry{
+			UserID:  userID,
+			EventID: input.EventID,
+			Status:  models.WaitlistStatusWaiting,
+		}
+		return waitlistRepo.Create(&entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.WaitlistRepo.GetByID(entry.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mapWaitlistEntryToResponse(*created)
+}
+
+func (s *BookingService) GetUserWaitlist(userID uint) ([]WaitlistEntryResponse, error) {
+	entries, err := s.WaitlistRepo.GetUserEntries(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mapWaitlistEntries(entries)
+}
+
+func (s *BookingService) GetEventWaitlist(actor Actor, eventID uint) ([]WaitlistEntryResponse, error) {
+	if err := s.checkManagedEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.WaitlistRepo.GetEventEntries(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mapWaitlistEntries(entries)
+}
+
+func (s *BookingService) LeaveWaitlist(entryID, userID uint) error {
+	entry, err := s.WaitlistRepo.GetByID(entryID)
+	if err != nil {
+		return errors.New("waitlist entry not found")
+	}
+
+	if entry.UserID != userID {
+		return errors.New("unauthorized to update this waitlist entry")
+	}
+
+	if entry.Status != models.WaitlistStatusWaiting && entry.Status != models.WaitlistStatusOffered {
+		return errors.New("waitlist entry is no longer active")
+	}
+
+	if err := s.WaitlistRepo.UpdateStatus(entryID, models.WaitlistStatusLeft); err != nil {
+		return err
+	}
+
+	// Declining an offer frees the seat for the next person in line
+	if entry.Status == models.WaitlistStatusOffered {
+		if err := s.promoteWaitlist(entry.EventID); err != nil {
+			log.Printf("[WAITLIST] Failed to promote waitlist for event %d: %v", entry.EventID, err)
+		}
+	}
+
+	return nil
+}
+
+// ClaimWaitlistOffer turns an offered waitlist spot into a seat hold. Like
+// any other booking, the hold must be confirmed before it expires.
+func (s *BookingService) ClaimWaitlistOffer(entryID, userID uint, input ClaimWaitlistInput) (*BookingResponse, error) {
+	entry, err := s.WaitlistRepo.GetByID(entryID)
+	if err != nil {
+		return nil, errors.New("waitlist entry not found")
+	}
+
+	if entry.UserID != userID {
+		return nil, errors.New("unauthorized to claim this waitlist entry")
+	}
+
+	if err := s.requireVerifiedEmail(userID); err != nil {
+		return nil, err
+	}
+
+	if entry.Status != models.WaitlistStatusOffered {
+		return nil, errors.New("no seat has been offered for this waitlist entry")
+	}
+
+	if entry.OfferExpiresAt == nil || entry.OfferExpiresAt.Before(time.Now()) {
+		return nil, ErrOfferExpired
+	}
+
+	return s.CreateBooking(userID, CreateBookingInput{EventID: entry.EventID, TicketTypeID: input.TicketTypeID})
+}
+
+// promoteWaitlist offers every free seat of an event to the users at the head
+// of its queue. Each offer holds the seat for the configured claim window.
+func (s *BookingService) promoteWaitlist(eventID uint) error {
+	var offered []models.WaitlistEntry
+
+	err := s.BookingRepo.DB.Transaction(func(tx *gorm.DB) error {
+		waitlistRepo := s.WaitlistRepo.WithTx(tx)
+
+		event, err := s.EventRepo.WithTx(tx).GetEventForUpdate(eventID)
+		if err != nil {
+			return utils.Errorf("event not found: %w", err)
+		}
+
+		// Seats of events that are not taking bookings are not offered
+		if !event.IsBookable(time.Now()) {
+			return nil
+		}
+
+		free := -1
+		if event.Capacity > 0 {
+			booked, err := s.BookingRepo.WithTx(tx).CountActiveSeats(eventID)
+			if err != nil {
+				return err
+			}
+			offers, err := waitlistRepo.CountActiveOffers(eventID, 0)
+			if err != nil {
+				return err
+			}
+			free = event.Capacity - int(booked+offers)
+			if free <= 0 {
+				return nil
+			}
+		}
+
+		offered, err = waitlistRepo.NextWaiting(eventID, free)
+		if err != nil {
+			return err
+		}
+
+		expiresAt := time.Now().Add(s.Config.WaitlistClaimWindow)
+		for _, entry := range offered {
+			if err := waitlistRepo.Offer(entry.ID, expiresAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range offered {
+		log.Printf("[WAITLIST] Offered a seat for event %d to user %d (entry %d)", eventID, entry.UserID, entry.ID)
+	}
+	return nil
+}
+
+// releaseExpiredHolds cancels unconfirmed bookings whose hold has lapsed and
+// offers the freed seats to the affected events' waitlists
+func (s *BookingService) releaseExpiredHolds() {
+	eventIDs, err := s.BookingRepo.ReleaseExpiredHolds(0)
+	if err != nil {
+		log.Printf("[HOLDS] Failed to release expired holds: %v", err)
+		return
+	}
+
+	for _, eventID := range eventIDs {
+		log.Printf("[HOLDS] Released expired seat holds for event %d", eventID)
+		if err := s.promoteWaitlist(eventID); err != nil {
+			log.Printf("[WAITLIST] Failed to promote waitlist for event %d: %v", eventID, err)
+		}
+	}
+}
+
+// processWaitlists expires lapsed offers and fills any free seats from the queue
+func (s *BookingService) processWaitlists() {
+	expired, err := s.WaitlistRepo.ExpireOffers(time.Now())
+	if err != nil {
+		log.Printf("[WAITLIST] Failed to expire offers: %v", err)
+		return
+	}
+
+	waiting, err := s.WaitlistRepo.EventsWithWaiting()
+	if err != nil {
+		log.Printf("[WAITLIST] Failed to load queued events: %v", err)
+		return
+	}
+
+	eventIDs := make(map[uint]struct{}, len(expired)+len(waiting))
+	for _, id := range append(expired, waiting...) {
+		eventIDs[id] = struct{}{}
+	}
+
+	for eventID := range eventIDs {
+		if err := s.promoteWaitlist(eventID); err != nil {
+			log.Printf("[WAITLIST] Failed to promote waitlist for event %d: %v", eventID, err)
+		}
+	}
+}
+
+func (s *BookingService) mapWaitlistEntries(entries []models.WaitlistEntry) ([]WaitlistEntryResponse, error) {
+	responses := make([]WaitlistEntryResponse, 0, len(entries))
+
+	for _, entry := range entries {
+		resp, err := s.mapWaitlistEntryToResponse(entry)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *resp)
+	}
+
+	return responses, nil
+}
+
+func (s *BookingService) mapWaitlistEntryToResponse(entry models.WaitlistEntry) (*WaitlistEntryResponse, error) {
+	response := &WaitlistEntryResponse{
+		ID:             entry.ID,
+		UserID:         entry.UserID,
+		EventID:        entry.EventID,
+		Status:         string(entry.Status),
+		OfferedAt:      entry.OfferedAt,
+		OfferExpiresAt: entry.OfferExpiresAt,
+		CreatedAt:      entry.CreatedAt,
+	}
+
+	if entry.Status == models.WaitlistStatusWaiting {
+		ahead, err := s.WaitlistRepo.CountWaitingBefore(entry.EventID, entry.ID)
+		if err != nil {
+			return nil, err
+		}
+		response.Position = int(ahead) + 1
+	}
+
+	if entry.User.ID != 0 {
+		response.User = &UserBrief{
+			ID:    entry.User.ID,
+			Name:  entry.User.Name,
+			Email: entry.User.Email,
+		}
+	}
+
+	if entry.Event.ID != 0 {
+		response.Event = &EventBrief{
+			ID:       entry.Event.ID,
+			Name:     entry.Event.Name,
+			Venue:    entry.Event.Venue,
+			ImageURL: entry.Event.ImageURL,
+		}
+	}
+
+	return response, nil
+}
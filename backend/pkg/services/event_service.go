@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"fmt"
+	"math"
 	"mime/multipart"
 	"os"
 	"strings"
@@ -12,49 +13,238 @@ import (
 	"github.com/robaa12/mawid/internal/utils"
 	"github.com/robaa12/mawid/pkg/models"
 	"github.com/robaa12/mawid/pkg/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidEventTransition = errors.New("invalid event status transition")
+	// ErrCategoryInUse is returned when deleting a category that still has
+	// events and neither a target category nor a cascade was requested
+	ErrCategoryInUse = errors.New("category still has events")
+	// ErrInvalidCursor is returned for pagination cursors that are malformed
+	// or were issued for a listing with another sort order
+	ErrInvalidCursor = repository.ErrInvalidCursor
+	// ErrCategoryExists is returned when a category would share its name
+	// with another one under the same parent and organization
+	ErrCategoryExists = repository.ErrCategoryExists
+)
+
+// categoryDeletionBatch is how many events a cascading category deletion
+// loads at a time
+const categoryDeletionBatch = 100
+
+// maxNearbyRadiusKm bounds the radius of a "near me" search
+const maxNearbyRadiusKm = 500
+
+// defaultEventDuration is how long an event runs when it is created without
+// an end time or duration
+const defaultEventDuration = 2 * time.Hour
+
+// Search suggestions are capped at maxSuggestions and kept for
+// suggestionCacheTTL per prefix. Shorter prefixes than minSuggestPrefix
+// match too much to be useful and get no suggestions.
+const (
+	defaultSuggestions = 5
+	maxSuggestions     = 10
+	minSuggestPrefix   = 2
+	maxSuggestPrefix   = 64
+	suggestionCacheTTL = time.Minute
 )
 
 type EventService struct {
 	EventRepo      *repository.EventRepository
+	OrgRepo        *repository.OrganizationRepository
 	StorageService *utils.StorageService
 	BookingRepo    *repository.BookingRepository
+	WaitlistRepo   *repository.WaitlistRepository
 	cache          *utils.Cache
 	cacheMutex     sync.RWMutex
 }
 
 type (
 	CreateEventInput struct {
-		Name        string   `json:"name" binding:"required"`
-		Description string   `json:"description" binding:"required"`
-		CategoryID  uint     `json:"category_id" binding:"required"`
-		EventDate   string   `json:"event_date" binding:"required"`
-		Venue       string   `json:"venue" binding:"required"`
-		Price       float64  `json:"price" binding:"required,min=0"`
-		Tags        []string `json:"tags"`
+		Name              string   `json:"name" binding:"required"`
+		Description       string   `json:"description" binding:"required"`
+		CategoryID        uint     `json:"category_id" binding:"required"`
+		EventDate         string   `json:"event_date" binding:"required"`
+		EndDate           string   `json:"end_date"`
+		DurationMinutes   int      `json:"duration_minutes" binding:"min=0"`
+		TimeZone          string   `json:"time_zone"`
+		Venue             string   `json:"venue"`
+		VenueID           uint     `json:"venue_id"`
+		Price             float64  `json:"price" binding:"required,min=0"`
+		Capacity          int      `json:"capacity" binding:"min=0"`
+		MaxTicketsPerUser int      `json:"max_tickets_per_user" binding:"min=0"`
+		Tags              []string `json:"tags"`
+		Status            string   `json:"status"`
+		PublishAt         string   `json:"publish_at"`
+		OrganizationID    *uint    `json:"organization_id"`
 	}
 
 	UpdateEventInput struct {
-		Name        string   `json:"name"`
-		Description string   `json:"description"`
-		CategoryID  uint     `json:"category_id"`
-		EventDate   string   `json:"event_date"`
-		Venue       string   `json:"venue"`
-		Price       float64  `json:"price"`
-		Tags        []string `json:"tags"`
+		Name              string   `json:"name"`
+		Description       string   `json:"description"`
+		CategoryID        uint     `json:"category_id"`
+		EventDate         string   `json:"event_date"`
+		EndDate           string   `json:"end_date"`
+		DurationMinutes   *int     `json:"duration_minutes"`
+		TimeZone          string   `json:"time_zone"`
+		Venue             string   `json:"venue"`
+		VenueID           *uint    `json:"venue_id"`
+		Price             *float64 `json:"price"`
+		Capacity          *int     `json:"capacity"`
+		MaxTicketsPerUser *int     `json:"max_tickets_per_user"`
+		Tags              []string `json:"tags"`
 	}
 
 	EventResponse struct {
-		ID          uint            `json:"id"`
-		Name        string          `json:"name"`
-		Description string          `json:"description"`
-		Category    models.Category `json:"category"`
-		EventDate   time.Time       `json:"event_date"`
-		Venue       string          `json:"venue"`
-		Price       float64         `json:"price"`
-		ImageURL    string          `json:"image_url"`
-		Tags        []models.Tag    `json:"tags"`
-		CreatedAt   time.Time       `json:"created_at"`
-		UpdatedAt   time.Time       `json:"updated_at"`
+		ID                uint                    `json:"id"`
+		Name              string                  `json:"name"`
+		Description       string                  `json:"description"`
+		Category          models.Category         `json:"category"`
+		OrganizationID    *uint                   `json:"organization_id"`
+		EventDate         time.Time               `json:"event_date"`
+		EndDate           time.Time               `json:"end_date"`
+		DurationMinutes   int                     `json:"duration_minutes"`
+		TimeZone          string                  `json:"time_zone"`
+		LocalEventDate    time.Time               `json:"local_event_date"`
+		LocalEndDate      time.Time               `json:"local_end_date"`
+		Venue             string                  `json:"venue"`
+		VenueID           *uint                   `json:"venue_id"`
+		VenueDetails      *models.Venue           `json:"venue_details,omitempty"`
+		DistanceKm        *float64                `json:"distance_km,omitempty"`
+		Match             *repository.SearchMatch `json:"match,omitempty"`
+		Price             float64                 `json:"price"`
+		Capacity          int                     `json:"capacity"`
+		MaxTicketsPerUser int                     `json:"max_tickets_per_user"`
+		RemainingSeats    *int                    `json:"remaining_seats"`
+		SoldOut           bool                    `json:"sold_out"`
+		ImageURL          string                  `json:"image_url"`
+		Tags              []models.Tag            `json:"tags"`
+		TicketTypes       []TicketTypeResponse    `json:"ticket_types"`
+		SeriesID          *uint                   `json:"series_id,omitempty"`
+		Status            models.EventStatus      `json:"status"`
+		PublishAt         *time.Time              `json:"publish_at,omitempty"`
+		StatusNote        string                  `json:"status_note,omitempty"`
+		CreatedAt         time.Time               `json:"created_at"`
+		UpdatedAt         time.Time               `json:"updated_at"`
+		DeletedAt         *time.Time              `json:"deleted_at,omitempty"`
+		// translations are applied by Localize rather than sent as they are
+		translations []models.EventTranslation
+	}
+
+	// CategoryInput describes a category to create or update. Without a slug
+	// one is derived from the name.
+	CategoryInput struct {
+		Name           string `json:"name" binding:"required,max=100"`
+		Slug           string `json:"slug" binding:"max=120"`
+		Description    string `json:"description"`
+		ParentID       *uint  `json:"parent_id"`
+		SortOrder      int    `json:"sort_order"`
+		IconURL        string `json:"icon_url" binding:"omitempty,url,max=255"`
+		OrganizationID *uint  `json:"organization_id"`
+	}
+
+	// CategoryNode is a category in the category tree. EventCount covers the
+	// listed events of the category and all of its subcategories.
+	CategoryNode struct {
+		models.Category
+		EventCount int64           `json:"event_count"`
+		Children   []*CategoryNode `json:"children"`
+	}
+
+	// EventListFilter narrows and orders event listings. The category
+	// includes its subcategories, and tags match any of the given tags, or
+	// all of them when MatchAllTags is set. From and To select the events
+	// taking place during that window and the price bounds apply to the
+	// cheapest ticket.
+	EventListFilter struct {
+		CategoryID   uint
+		Tags         []string
+		MatchAllTags bool
+		From         *time.Time
+		To           *time.Time
+		MinPrice     *float64
+		MaxPrice     *float64
+		FreeOnly     bool
+		VenueID      uint
+		Status       string
+		Sort         string
+	}
+
+	// TrashedCategory is a category waiting in the trash
+	TrashedCategory struct {
+		models.Category
+		DeletedAt time.Time `json:"deleted_at"`
+	}
+
+	// DeleteCategoryInput says what happens to a category's events when it
+	// is deleted: they are moved to MoveTo, or trashed along with it when
+	// Force is set
+	DeleteCategoryInput struct {
+		MoveTo uint
+		Force  bool
+	}
+
+	// CategoryDeletionReport describes what deleting a category affected
+	CategoryDeletionReport struct {
+		CategoryID      uint  `json:"category_id"`
+		MovedTo         *uint `json:"moved_to,omitempty"`
+		EventsMoved     int   `json:"events_moved"`
+		EventsDeleted   int   `json:"events_deleted"`
+		BookingsDeleted int64 `json:"bookings_deleted"`
+		SeriesDeleted   int   `json:"series_deleted"`
+		// SubcategoriesMoved counts the subcategories lifted to the deleted
+		// category's own parent
+		SubcategoriesMoved int64  `json:"subcategories_moved"`
+		EventIDs           []uint `json:"event_ids"`
+	}
+
+	// PurgeReport counts what a purge removed for good
+	PurgeReport struct {
+		Events     int   `json:"events"`
+		Bookings   int64 `json:"bookings"`
+		Categories int64 `json:"categories"`
+		Images     int   `json:"images"`
+	}
+
+	UpdateEventStatusInput struct {
+		Status    string `json:"status" binding:"required"`
+		PublishAt string `json:"publish_at"`
+		Note      string `json:"note" binding:"max=500"`
+	}
+
+	TicketTypeInput struct {
+		Name         string  `json:"name" binding:"required"`
+		Description  string  `json:"description"`
+		Price        float64 `json:"price" binding:"min=0"`
+		Quota        int     `json:"quota" binding:"min=0"`
+		SaleStartsAt string  `json:"sale_starts_at"`
+		SaleEndsAt   string  `json:"sale_ends_at"`
+	}
+
+	TicketTypeResponse struct {
+		ID             uint       `json:"id"`
+		Name           string     `json:"name"`
+		Description    string     `json:"description"`
+		Price          float64    `json:"price"`
+		Quota          int        `json:"quota"`
+		RemainingSeats *int       `json:"remaining_seats"`
+		SoldOut        bool       `json:"sold_out"`
+		OnSale         bool       `json:"on_sale"`
+		SaleStartsAt   *time.Time `json:"sale_starts_at"`
+		SaleEndsAt     *time.Time `json:"sale_ends_at"`
+	}
+
+	VenueInput struct {
+		Name               string   `json:"name" binding:"required"`
+		Address            string   `json:"address" binding:"required"`
+		City               string   `json:"city"`
+		Country            string   `json:"country"`
+		Latitude           *float64 `json:"latitude" binding:"required,min=-90,max=90"`
+		Longitude          *float64 `json:"longitude" binding:"required,min=-180,max=180"`
+		DefaultCapacity    int      `json:"default_capacity" binding:"min=0"`
+		AccessibilityNotes string   `json:"accessibility_notes"`
 	}
 
 	PaginatedEvents struct {
@@ -63,16 +253,24 @@ type (
 		Page       int             `json:"page"`
 		PageSize   int             `json:"page_size"`
 		TotalPages int             `json:"total_pages"`
+		// Facets is only filled in for the public event listing
+		Facets *repository.EventFacets `json:"facets,omitempty"`
+		// NextCursor and PrevCursor are set for cursor pagination, which
+		// leaves Total, Page and TotalPages at zero
+		NextCursor string `json:"next_cursor,omitempty"`
+		PrevCursor string `json:"prev_cursor,omitempty"`
 	}
 )
 
-func NewEventService(eventRepo *repository.EventRepository, storageService *utils.StorageService, bookingRepo *repository.BookingRepository) *EventService {
+func NewEventService(eventRepo *repository.EventRepository, orgRepo *repository.OrganizationRepository, storageService *utils.StorageService, bookingRepo *repository.BookingRepository, waitlistRepo *repository.WaitlistRepository) *EventService {
 	fmt.Println("[CACHE INIT] Creating new event service with cache")
 
 	service := &EventService{
 		EventRepo:      eventRepo,
+		OrgRepo:        orgRepo,
 		StorageService: storageService,
 		BookingRepo:    bookingRepo,
+		WaitlistRepo:   waitlistRepo,
 		cache:          utils.NewCache(),
 	}
 
@@ -99,26 +297,97 @@ func NewEventService(eventRepo *repository.EventRepository, storageService *util
 		}()
 	}()
 
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			service.publishDueEvents()
+		}
+	}()
+
+	// Series are extended on startup too, so deployments restarting more
+	// often than the ticker fires still get new occurrences
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+
+		for {
+			if err := service.ExtendSeries(); err != nil {
+				fmt.Println("[SERIES] Failed to extend recurring series:", err)
+			}
+			<-ticker.C
+		}
+	}()
+
 	return service
 }
 
-func (s *EventService) CreateEvent(input CreateEventInput, image *multipart.FileHeader) (*EventResponse, error) {
-	eventDate, err := s.parseEventDate(input.EventDate)
+// CreateEvent adds an event to the actor's organization. Admins may create
+// events for any organization, or platform events when none is given.
+func (s *EventService) CreateEvent(actor Actor, input CreateEventInput, image *multipart.FileHeader) (*EventResponse, error) {
+	organizationID, err := s.resolveOrganization(actor, input.OrganizationID)
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err := s.EventRepo.GetCategoryByID(input.CategoryID); err != nil {
-		return nil, errors.New("category not found")
+	timeZone, loc, err := s.resolveTimeZone(input.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	eventDate, err := s.parseEventTime(input.EventDate, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	endDate := eventDate.Add(defaultEventDuration)
+	switch {
+	case input.EndDate != "":
+		if endDate, err = s.parseEventTime(input.EndDate, loc); err != nil {
+			return nil, err
+		}
+	case input.DurationMinutes > 0:
+		endDate = eventDate.Add(time.Duration(input.DurationMinutes) * time.Minute)
+	}
+
+	if !endDate.After(eventDate) {
+		return nil, errors.New("event must end after it starts")
+	}
+
+	status, publishAt, err := s.resolveInitialStatus(input.Status, input.PublishAt, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCategory(input.CategoryID, organizationID); err != nil {
+		return nil, err
+	}
+
+	if input.Capacity < 0 || input.MaxTicketsPerUser < 0 {
+		return nil, errors.New("capacity and ticket limit cannot be negative")
+	}
+
+	venueID, venueName, capacity, err := s.resolveVenue(input.VenueID, input.Venue, input.Capacity)
+	if err != nil {
+		return nil, err
 	}
 
 	newEvent := models.Event{
-		Name:        input.Name,
-		Description: input.Description,
-		CategoryID:  input.CategoryID,
-		EventDate:   eventDate,
-		Venue:       input.Venue,
-		Price:       input.Price,
+		Name:              input.Name,
+		Description:       input.Description,
+		CategoryID:        input.CategoryID,
+		OrganizationID:    organizationID,
+		EventDate:         eventDate,
+		EndDate:           endDate,
+		TimeZone:          timeZone,
+		Venue:             venueName,
+		VenueID:           venueID,
+		Price:             input.Price,
+		Capacity:          capacity,
+		MaxTicketsPerUser: input.MaxTicketsPerUser,
+		Status:            status,
+		PublishAt:         publishAt,
 	}
 
 	if image != nil {
@@ -153,10 +422,126 @@ func (s *EventService) CreateEvent(input CreateEventInput, image *multipart.File
 	return s.mapEventToResponse(*completeEvent), nil
 }
 
-func (s *EventService) GetAllEvents(page, pageSize int, categoryID uint) (*PaginatedEvents, error) {
+func (f EventListFilter) repositoryFilter() (repository.EventFilter, error) {
+	filter := repository.EventFilter{
+		CategoryID:   f.CategoryID,
+		Tags:         normalizeTagNames(f.Tags),
+		MatchAllTags: f.MatchAllTags,
+		From:         f.From,
+		To:           f.To,
+		MinPrice:     f.MinPrice,
+		MaxPrice:     f.MaxPrice,
+		FreeOnly:     f.FreeOnly,
+		VenueID:      f.VenueID,
+	}
+
+	if f.From != nil && f.To != nil && f.To.Before(*f.From) {
+		return filter, errors.New("the end of the date range must not be before its start")
+	}
+	if (f.MinPrice != nil && *f.MinPrice < 0) || (f.MaxPrice != nil && *f.MaxPrice < 0) {
+		return filter, errors.New("prices must not be negative")
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MaxPrice < *f.MinPrice {
+		return filter, errors.New("the maximum price must not be below the minimum price")
+	}
+
+	if f.Status != "" {
+		status, ok := models.ParseEventStatus(f.Status)
+		if !ok {
+			return filter, utils.Errorf("unknown event status %q", f.Status)
+		}
+		filter.Status = status
+	}
+
+	sort, ok := repository.ParseEventSort(f.Sort)
+	if !ok {
+		return filter, utils.Errorf("unknown sort order %q, expected date, price, price_desc, newest, popularity or relevance", f.Sort)
+	}
+	filter.Sort = sort
+
+	return filter, nil
+}
+
+// GetAllEvents lists publicly listed events along with facet counts for
+// building filters
+func (s *EventService) GetAllEvents(page, pageSize int, listFilter EventListFilter) (*PaginatedEvents, error) {
+	page, pageSize = s.normalizePagination(page, pageSize)
+
+	filter, err := listFilter.repositoryFilter()
+	if err != nil {
+		return nil, err
+	}
+	filter.PublicOnly = true
+
+	events, total, err := s.EventRepo.GetAll(page, pageSize, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.createPaginatedResponse(events, total, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Facets, err = s.EventRepo.GetFacets(filter)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAllEventsByCursor is GetAllEvents paged by an opaque cursor instead of
+// a page number. Facets are only computed for the first page.
+func (s *EventService) GetAllEventsByCursor(cursor string, limit int, listFilter EventListFilter) (*PaginatedEvents, error) {
+	position, err := repository.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	_, limit = s.normalizePagination(1, limit)
+
+	filter, err := listFilter.repositoryFilter()
+	if err != nil {
+		return nil, err
+	}
+	filter.PublicOnly = true
+
+	events, page, err := s.EventRepo.GetAllByCursor(position, limit, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.createCursorResponse(events, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if position == nil {
+		result.Facets, err = s.EventRepo.GetFacets(filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// GetManagedEvents lists the events the actor manages in every publication
+// state, optionally narrowed to one status. Organizers only see their own
+// organization's events.
+func (s *EventService) GetManagedEvents(actor Actor, page, pageSize int, listFilter EventListFilter) (*PaginatedEvents, error) {
+	scope, err := actor.scope()
+	if err != nil {
+		return nil, err
+	}
+
 	page, pageSize = s.normalizePagination(page, pageSize)
 
-	events, total, err := s.EventRepo.GetAll(page, pageSize, categoryID)
+	filter, err := listFilter.repositoryFilter()
+	if err != nil {
+		return nil, err
+	}
+	filter.OrganizationID = scope
+
+	events, total, err := s.EventRepo.GetAll(page, pageSize, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -164,7 +549,22 @@ func (s *EventService) GetAllEvents(page, pageSize int, categoryID uint) (*Pagin
 	return s.createPaginatedResponse(events, total, page, pageSize)
 }
 
-func (s *EventService) GetEventByID(id uint) (*EventResponse, error) {
+// GetEventByID returns an event. Drafts and events waiting for their publish
+// time are only returned to those who manage the event.
+func (s *EventService) GetEventByID(id uint, viewer Actor) (*EventResponse, error) {
+	event, err := s.getEventResponse(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !viewer.CanManage(event.OrganizationID) && !isPubliclyVisible(event) {
+		return nil, errors.New("event not found")
+	}
+
+	return event, nil
+}
+
+func (s *EventService) getEventResponse(id uint) (*EventResponse, error) {
 	cacheKey := fmt.Sprintf("event_%d", id)
 
 	s.cacheMutex.RLock()
@@ -173,7 +573,7 @@ func (s *EventService) GetEventByID(id uint) (*EventResponse, error) {
 
 	if found {
 		if eventData, ok := cachedData.(*EventResponse); ok {
-			return eventData, nil
+			return s.withAvailability(eventData)
 		}
 	}
 
@@ -188,16 +588,23 @@ func (s *EventService) GetEventByID(id uint) (*EventResponse, error) {
 	s.cache.Set(cacheKey, result, 5*time.Minute)
 	s.cacheMutex.Unlock()
 
-	return result, nil
+	return s.withAvailability(result)
 }
 
-func (s *EventService) UpdateEvent(id uint, input UpdateEventInput, image *multipart.FileHeader) (*EventResponse, error) {
-	existingEvent, err := s.EventRepo.GetEventByID(id)
+func (s *EventService) UpdateEvent(actor Actor, id uint, input UpdateEventInput, image *multipart.FileHeader) (*EventResponse, error) {
+	existingEvent, err := s.managedEvent(actor, id)
 	if err != nil {
-		return nil, errors.New("event not found")
+		return nil, err
 	}
 
-	s.updateEventFields(existingEvent, input)
+	if err := s.updateEventFields(existingEvent, input); err != nil {
+		return nil, err
+	}
+
+	// An occurrence edited on its own no longer follows series-wide edits
+	if existingEvent.SeriesID != nil {
+		existingEvent.SeriesOverride = true
+	}
 
 	if image != nil {
 		oldImg := existingEvent.ImageURL
@@ -244,238 +651,1489 @@ func (s *EventService) UpdateEvent(id uint, input UpdateEventInput, image *multi
 		s.cacheRecentEvents()
 	}()
 
-	return eventResp, nil
+	return s.withAvailability(eventResp)
 }
 
-func (s *EventService) SearchEvents(query string, page, pageSize int) (*PaginatedEvents, error) {
+// SearchEvents runs a full-text search over the publicly listed events. Each
+// result carries its rank and highlighted name and description.
+func (s *EventService) SearchEvents(query string, page, pageSize int, listFilter EventListFilter) (*PaginatedEvents, error) {
 	page, pageSize = s.normalizePagination(page, pageSize)
 
-	events, total, err := s.EventRepo.SearchByName(query, page, pageSize)
+	filter, err := listFilter.repositoryFilter()
 	if err != nil {
 		return nil, err
 	}
+	filter.PublicOnly = true
 
-	return s.createPaginatedResponse(events, total, page, pageSize)
-}
-
-func (s *EventService) GetAllCategories() ([]models.Category, error) {
-
-	categories, err := s.EventRepo.GetAllCategories()
+	events, matches, total, err := s.EventRepo.Search(query, page, pageSize, filter)
 	if err != nil {
 		return nil, err
 	}
-	return categories, nil
-}
-
-func (s *EventService) GetCategoryByID(id uint) (*models.Category, error) {
-	return s.EventRepo.GetCategoryByID(id)
-}
 
-func (s *EventService) CreateCategory(name string) (*models.Category, error) {
-	category := models.Category{Name: name}
-	if err := s.EventRepo.CreateCategory(&category); err != nil {
+	result, err := s.createPaginatedResponse(events, total, page, pageSize)
+	if err != nil {
 		return nil, err
 	}
-	return &category, nil
+
+	setSearchMatches(result.Events, matches)
+	return result, nil
 }
 
-func (s *EventService) UpdateCategory(id uint, name string) (*models.Category, error) {
-	category, err := s.EventRepo.GetCategoryByID(id)
+// SearchEventsByCursor is SearchEvents paged by an opaque cursor
+func (s *EventService) SearchEventsByCursor(query, cursor string, limit int, listFilter EventListFilter) (*PaginatedEvents, error) {
+	position, err := repository.DecodeCursor(cursor)
 	if err != nil {
-		return nil, errors.New("category not found")
-	}
-	category.Name = name
-	if err := s.EventRepo.UpdateCategory(category); err != nil {
 		return nil, err
 	}
-	return category, nil
-}
+	_, limit = s.normalizePagination(1, limit)
 
-func (s *EventService) DeleteCategory(id uint) error {
-	cat, err := s.EventRepo.GetCategoryByID(id)
+	filter, err := listFilter.repositoryFilter()
 	if err != nil {
-		return fmt.Errorf("category not found: %w", err)
+		return nil, err
 	}
+	filter.PublicOnly = true
 
-	eventsToDelete, _, err := s.EventRepo.GetAll(1, 1000, id)
+	events, matches, page, err := s.EventRepo.SearchByCursor(query, position, limit, filter)
 	if err != nil {
-		return fmt.Errorf("failed to fetch events for category: %w", err)
+		return nil, err
 	}
 
-	fmt.Printf("Deleting category %s (ID: %d) with %d associated events\n",
-		cat.Name, cat.ID, len(eventsToDelete))
-
-	for _, evt := range eventsToDelete {
-		if err := s.DeleteEvent(evt.ID); err != nil {
-			return fmt.Errorf("failed to delete event %d: %w", evt.ID, err)
-		}
+	result, err := s.createCursorResponse(events, page, limit)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.EventRepo.DeleteCategory(id)
-}
-
-func (s *EventService) parseEventDate(dateStr string) (time.Time, error) {
-	return time.Parse("2006-01-02T15:04:05Z", dateStr)
+	setSearchMatches(result.Events, matches)
+	return result, nil
 }
 
-func (s *EventService) normalizePagination(page, pageSize int) (int, int) {
-	if page < 1 {
-		page = 1
+// GetSuggestions completes a search being typed from event names, venues,
+// tags and categories. Results are cached briefly per prefix, so a burst of
+// keystrokes from many users hits the database once.
+func (s *EventService) GetSuggestions(query string, limit int) ([]repository.Suggestion, error) {
+	runes := []rune(strings.ToLower(strings.Join(strings.Fields(query), " ")))
+	if len(runes) < minSuggestPrefix {
+		return []repository.Suggestion{}, nil
 	}
+	if len(runes) > maxSuggestPrefix {
+		runes = runes[:maxSuggestPrefix]
+	}
+	prefix := string(runes)
 
 	switch {
-	case pageSize < 1:
-		pageSize = 10
-	case pageSize > 100:
-		pageSize = 100
+	case limit < 1:
+		limit = defaultSuggestions
+	case limit > maxSuggestions:
+		limit = maxSuggestions
 	}
-	return page, pageSize
-}
 
-func (s *EventService) createPaginatedResponse(events []models.Event, total int64, page, pageSize int) (*PaginatedEvents, error) {
-	eventResponses := make([]EventResponse, 0, len(events))
+	cacheKey := fmt.Sprintf("suggest_%d_%s", limit, prefix)
+	if cached, found := s.cache.Get(cacheKey); found {
+		if suggestions, ok := cached.([]repository.Suggestion); ok {
+			return suggestions, nil
+		}
+	}
 
-	for _, event := range events {
-		eventResponses = append(eventResponses, *s.mapEventToResponse(event))
+	suggestions, err := s.EventRepo.GetSuggestions(prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	if suggestions == nil {
+		suggestions = []repository.Suggestion{}
 	}
 
-	totalPages := 1
-	if total > 0 {
-		totalPages = (int(total) + pageSize - 1) / pageSize
+	s.cache.Set(cacheKey, suggestions, suggestionCacheTTL)
+	return suggestions, nil
+}
+
+func setSearchMatches(events []EventResponse, matches map[uint]repository.SearchMatch) {
+	for i := range events {
+		if match, ok := matches[events[i].ID]; ok {
+			events[i].Match = &match
+		}
 	}
+}
 
-	return &PaginatedEvents{
-		Events:     eventResponses,
-		Total:      total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
-	}, nil
+func (s *EventService) GetAllCategories() (CategoryList, error) {
+
+	categories, err := s.EventRepo.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
 }
 
-func (s *EventService) processTags(event *models.Event, tagNames []string) error {
-	if len(tagNames) == 0 {
-		return nil
+// GetCategoryTree nests the categories under their parents, each node
+// counting the listed events of its whole subtree
+func (s *EventService) GetCategoryTree() (CategoryTree, error) {
+	categories, err := s.EventRepo.GetAllCategories()
+	if err != nil {
+		return nil, err
 	}
 
-	var tagsToAdd []models.Tag
+	counts, err := s.EventRepo.CountEventsByCategory(true)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, name := range tagNames {
-		name = strings.TrimSpace(name)
-		if name == "" {
-			continue
-		}
+	nodes := make(map[uint]*CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &CategoryNode{Category: category, EventCount: counts[category.ID], Children: []*CategoryNode{}}
+	}
 
-		tag, err := s.EventRepo.FindOrCreateTag(name)
-		if err != nil {
-			continue
+	// Categories arrive in display order, so appending keeps siblings sorted
+	roots := []*CategoryNode{}
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentID != nil {
+			// A parent that is not listed, such as one in the trash, leaves
+			// the category at the top level
+			if parent, ok := nodes[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
 		}
-		tagsToAdd = append(tagsToAdd, *tag)
+		roots = append(roots, node)
 	}
 
-	if len(tagsToAdd) > 0 {
-		if err := s.EventRepo.DB.Model(event).Association("Tags").Replace(tagsToAdd); err != nil {
-			return fmt.Errorf("failed to associate tags with event: %w", err)
-		}
+	for _, root := range roots {
+		sumEventCounts(root)
 	}
-	return nil
+	return roots, nil
 }
 
-func (s *EventService) updateEventTags(event *models.Event, tagNames []string) error {
-	if err := s.EventRepo.DB.Model(event).Association("Tags").Clear(); err != nil {
-		return err
+// sumEventCounts adds the event counts of a node's descendants to its own
+func sumEventCounts(node *CategoryNode) int64 {
+	for _, child := range node.Children {
+		node.EventCount += sumEventCounts(child)
 	}
+	return node.EventCount
+}
 
-	return s.processTags(event, tagNames)
+func (s *EventService) GetCategoryByID(id uint) (*models.Category, error) {
+	return s.EventRepo.GetCategoryByID(id)
 }
 
-func (s *EventService) updateEventFields(event *models.Event, input UpdateEventInput) error {
-	if input.Name != "" {
-		event.Name = input.Name
+func (s *EventService) GetCategoryBySlug(slug string) (*models.Category, error) {
+	category, err := s.EventRepo.GetCategoryBySlug(slug)
+	if err != nil {
+		return nil, errors.New("category not found")
 	}
+	return category, nil
+}
 
-	if input.Description != "" {
-		event.Description = input.Description
+// CreateCategory adds a category. Organizers' categories are private to their
+// organization; admins create shared categories unless they name one.
+func (s *EventService) CreateCategory(actor Actor, input CategoryInput) (*models.Category, error) {
+	organizationID, err := s.resolveOrganization(actor, input.OrganizationID)
+	if err != nil {
+		return nil, err
 	}
 
-	if input.CategoryID != 0 {
-		if _, err := s.EventRepo.GetCategoryByID(input.CategoryID); err != nil {
-			return errors.New("category not found")
+	category := models.Category{OrganizationID: organizationID}
+	if err := s.applyCategoryInput(&category, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.EventRepo.CreateCategory(&category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// UpdateCategory replaces a category's details. The slug is kept unless a
+// new one is given, and the organization never changes.
+func (s *EventService) UpdateCategory(actor Actor, id uint, input CategoryInput) (*models.Category, error) {
+	category, err := s.managedCategory(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyCategoryInput(category, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.EventRepo.UpdateCategory(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *EventService) applyCategoryInput(category *models.Category, input CategoryInput) error {
+	if err := s.checkCategoryParent(category, input.ParentID); err != nil {
+		return err
+	}
+
+	slug, err := s.categorySlug(category, input)
+	if err != nil {
+		return err
+	}
+
+	category.Name = strings.TrimSpace(input.Name)
+	category.Slug = slug
+	category.Description = input.Description
+	category.ParentID = input.ParentID
+	category.SortOrder = input.SortOrder
+	category.IconURL = input.IconURL
+	return nil
+}
+
+// categorySlug picks the slug for a category being saved. A requested slug
+// must be free; otherwise the current slug is kept, or a new category gets
+// a free one derived from its name.
+func (s *EventService) categorySlug(category *models.Category, input CategoryInput) (string, error) {
+	if input.Slug != "" {
+		slug := utils.Slugify(input.Slug)
+		if slug == "" {
+			return "", errors.New("slug must contain letters or digits")
+		}
+		if slug == category.Slug {
+			return slug, nil
+		}
+
+		exists, err := s.EventRepo.CategorySlugExists(slug, category.ID)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return "", utils.Errorf("slug %q is already in use", slug)
+		}
+		return slug, nil
+	}
+
+	if category.Slug != "" {
+		return category.Slug, nil
+	}
+
+	return utils.UniqueSlug(utils.Slugify(input.Name), "category", func(slug string) (bool, error) {
+		return s.EventRepo.CategorySlugExists(slug, category.ID)
+	})
+}
+
+// checkCategoryParent makes sure a category may be nested under parentID:
+// the parent must be open to the category's organization and must not be
+// the category itself or one of its subcategories
+func (s *EventService) checkCategoryParent(category *models.Category, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+	if category.ID != 0 && *parentID == category.ID {
+		return errors.New("a category cannot be its own parent")
+	}
+
+	parent, err := s.EventRepo.GetCategoryByID(*parentID)
+	if err != nil {
+		return errors.New("parent category not found")
+	}
+	if parent.OrganizationID != nil && (category.OrganizationID == nil || *parent.OrganizationID != *category.OrganizationID) {
+		return errors.New("parent category belongs to another organization")
+	}
+
+	if category.ID == 0 {
+		return nil
+	}
+	for ancestor := parent; ancestor.ParentID != nil; {
+		if *ancestor.ParentID == category.ID {
+			return errors.New("a category cannot be moved under one of its own subcategories")
+		}
+		if ancestor, err = s.EventRepo.GetCategoryByID(*ancestor.ParentID); err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// DeleteCategory moves a category to the trash. A category that still has
+// events or recurring series is only deleted when they are moved to another
+// category or, with Force, trashed along with it. Either way the whole
+// change happens in one transaction.
+func (s *EventService) DeleteCategory(actor Actor, id uint, input DeleteCategoryInput) (*CategoryDeletionReport, error) {
+	cat, err := s.managedCategory(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.MoveTo != 0 && input.Force {
+		return nil, errors.New("choose either a category to move the events to or a forced delete, not both")
+	}
+
+	events, series, err := s.EventRepo.CountCategoryUsage(id)
+	if err != nil {
+		return nil, err
+	}
+	subcategories, err := s.EventRepo.CountSubcategories(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CategoryDeletionReport{CategoryID: id, EventIDs: []uint{}}
+
+	if input.MoveTo == 0 && !input.Force && (events > 0 || series > 0 || subcategories > 0) {
+		return nil, utils.Errorf("%w: %d events, %d recurring series and %d subcategories use it, move them to another category or force the deletion",
+			ErrCategoryInUse, events, series, subcategories)
+	}
+
+	if input.MoveTo != 0 {
+		if input.MoveTo == id {
+			return nil, errors.New("cannot move events to the category being deleted")
+		}
+		// Events keep their organization, so the target must be open to it
+		if err := s.checkCategory(input.MoveTo, cat.OrganizationID); err != nil {
+			return nil, err
+		}
+		report.MovedTo = &input.MoveTo
+	}
+
+	fmt.Printf("Deleting category %s (ID: %d) with %d associated events\n", cat.Name, cat.ID, events)
+
+	// Trashed events share the category's deletion time so restoring the
+	// category brings them back too
+	at := time.Now()
+	err = s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+		eventRepo := s.EventRepo.WithTx(tx)
+
+		if input.MoveTo != 0 {
+			moved, err := eventRepo.MoveCategoryEvents(id, input.MoveTo)
+			if err != nil {
+				return utils.Errorf("failed to move events: %w", err)
+			}
+			report.EventsMoved = len(moved)
+			report.EventIDs = append(report.EventIDs, moved...)
+		} else if input.Force {
+			if err := s.cascadeCategory(tx, id, at, report); err != nil {
+				return err
+			}
+		}
+
+		// Subcategories are not deleted with their parent but move up a level
+		lifted, err := eventRepo.ReparentCategories(id, cat.ParentID)
+		if err != nil {
+			return utils.Errorf("failed to move subcategories: %w", err)
+		}
+		report.SubcategoriesMoved = lifted
+
+		return eventRepo.SoftDeleteCategory(id, at)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateEventsCache(report.EventIDs)
+	return report, nil
+}
+
+// cascadeCategory trashes every event of a category, a batch at a time, and
+// ends the recurring series filed under it. Occurrences of those series that
+// were moved to another category stay on as standalone events.
+func (s *EventService) cascadeCategory(tx *gorm.DB, categoryID uint, at time.Time, report *CategoryDeletionReport) error {
+	eventRepo := s.EventRepo.WithTx(tx)
+
+	allSeries, err := eventRepo.GetCategorySeries(categoryID)
+	if err != nil {
+		return err
+	}
+	for _, series := range allSeries {
+		if err := eventRepo.DetachSeriesEvents(series.ID); err != nil {
+			return utils.Errorf("failed to detach events of series %d: %w", series.ID, err)
+		}
+		if err := eventRepo.DeleteSeries(series.ID); err != nil {
+			return utils.Errorf("failed to delete series %d: %w", series.ID, err)
+		}
+		report.SeriesDeleted++
+	}
+
+	var lastID uint
+	for {
+		batch, err := eventRepo.GetCategoryEventsAfter(categoryID, lastID, categoryDeletionBatch)
+		if err != nil {
+			return utils.Errorf("failed to fetch events for category: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		for i := range batch {
+			evt := &batch[i]
+			if err := s.excludeOccurrence(eventRepo, evt); err != nil {
+				return utils.Errorf("failed to exclude occurrence from its series: %w", err)
+			}
+
+			bookings, err := s.trashEvent(tx, evt, at)
+			if err != nil {
+				return utils.Errorf("failed to delete event %d: %w", evt.ID, err)
+			}
+
+			report.EventsDeleted++
+			report.BookingsDeleted += bookings
+			report.EventIDs = append(report.EventIDs, evt.ID)
+			lastID = evt.ID
+		}
+	}
+}
+
+// managedEvent loads an event the actor may manage. Events of other
+// organizations are reported as not found.
+func (s *EventService) managedEvent(actor Actor, id uint) (*models.Event, error) {
+	scope, err := actor.scope()
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := s.EventRepo.GetOrganizationEvent(id, scope)
+	if err != nil {
+		return nil, errors.New("event not found")
+	}
+	return event, nil
+}
+
+// managedCategory loads a category the actor may manage. Shared categories
+// are left to admins.
+func (s *EventService) managedCategory(actor Actor, id uint) (*models.Category, error) {
+	scope, err := actor.scope()
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := s.EventRepo.GetOrganizationCategory(id, scope)
+	if err != nil {
+		return nil, errors.New("category not found")
+	}
+	return category, nil
+}
+
+// checkCategory makes sure events of the given organization may be filed
+// under a category: shared categories are open to all, the rest only to
+// their own organization
+func (s *EventService) checkCategory(categoryID uint, organizationID *uint) error {
+	category, err := s.EventRepo.GetCategoryByID(categoryID)
+	if err != nil {
+		return errors.New("category not found")
+	}
+
+	if category.OrganizationID != nil && (organizationID == nil || *category.OrganizationID != *organizationID) {
+		return errors.New("category belongs to another organization")
+	}
+	return nil
+}
+
+// resolveOrganization picks the organization a new record belongs to.
+// Organizers always create records for their own organization.
+func (s *EventService) resolveOrganization(actor Actor, requested *uint) (*uint, error) {
+	if !actor.IsAdmin() {
+		return actor.scope()
+	}
+
+	if requested == nil || *requested == 0 {
+		return nil, nil
+	}
+	if _, err := s.OrgRepo.GetByID(*requested); err != nil {
+		return nil, errors.New("organization not found")
+	}
+	return requested, nil
+}
+
+func (s *EventService) GetAllVenues(city string) ([]models.Venue, error) {
+	return s.EventRepo.GetAllVenues(strings.TrimSpace(city))
+}
+
+func (s *EventService) GetVenueByID(id uint) (*models.Venue, error) {
+	venue, err := s.EventRepo.GetVenueByID(id)
+	if err != nil {
+		return nil, errors.New("venue not found")
+	}
+	return venue, nil
+}
+
+func (s *EventService) CreateVenue(input VenueInput) (*models.Venue, error) {
+	venue := models.Venue{}
+	applyVenueInput(&venue, input)
+
+	if err := s.EventRepo.CreateVenue(&venue); err != nil {
+		return nil, err
+	}
+	return &venue, nil
+}
+
+// UpdateVenue saves the venue and carries a new name over to its events
+func (s *EventService) UpdateVenue(id uint, input VenueInput) (*models.Venue, error) {
+	venue, err := s.EventRepo.GetVenueByID(id)
+	if err != nil {
+		return nil, errors.New("venue not found")
+	}
+
+	renamed := venue.Name != input.Name
+	applyVenueInput(venue, input)
+
+	if err := s.EventRepo.UpdateVenue(venue); err != nil {
+		return nil, err
+	}
+
+	if renamed {
+		if err := s.EventRepo.SyncVenueName(id, venue.Name); err != nil {
+			return nil, utils.Errorf("failed to rename venue on its events: %w", err)
+		}
+	}
+
+	eventIDs, err := s.EventRepo.GetVenueEventIDs(id)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateEventsCache(eventIDs)
+
+	return venue, nil
+}
+
+// DeleteVenue removes a venue that no event refers to
+func (s *EventService) DeleteVenue(id uint) error {
+	if _, err := s.EventRepo.GetVenueByID(id); err != nil {
+		return errors.New("venue not found")
+	}
+
+	eventIDs, err := s.EventRepo.GetVenueEventIDs(id)
+	if err != nil {
+		return err
+	}
+	if len(eventIDs) > 0 {
+		return utils.Errorf("venue is used by %d events, move them to another venue first", len(eventIDs))
+	}
+
+	return s.EventRepo.DeleteVenue(id)
+}
+
+// GetNearbyEvents lists upcoming public events within radiusKm of a point,
+// nearest first
+func (s *EventService) GetNearbyEvents(lat, lng, radiusKm float64, page, pageSize int) (*PaginatedEvents, error) {
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, errors.New("latitude must be within ±90 and longitude within ±180")
+	}
+	if radiusKm <= 0 || radiusKm > maxNearbyRadiusKm {
+		return nil, utils.Errorf("radius must be between 0 and %d km", maxNearbyRadiusKm)
+	}
+
+	page, pageSize = s.normalizePagination(page, pageSize)
+
+	events, distances, total, err := s.EventRepo.GetNearby(lat, lng, radiusKm, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.createPaginatedResponse(events, total, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result.Events {
+		distance := math.Round(distances[result.Events[i].ID]*100) / 100
+		result.Events[i].DistanceKm = &distance
+	}
+	return result, nil
+}
+
+// resolveVenue works out an event's venue name and capacity. A venue record,
+// when given, supplies whichever of the two were left empty.
+func (s *EventService) resolveVenue(venueID uint, name string, capacity int) (*uint, string, int, error) {
+	name = strings.TrimSpace(name)
+	if venueID == 0 {
+		if name == "" {
+			return nil, "", 0, errors.New("a venue or venue_id is required")
+		}
+		return nil, name, capacity, nil
+	}
+
+	venue, err := s.EventRepo.GetVenueByID(venueID)
+	if err != nil {
+		return nil, "", 0, errors.New("venue not found")
+	}
+
+	if name == "" {
+		name = venue.Name
+	}
+	if capacity == 0 {
+		capacity = venue.DefaultCapacity
+	}
+	return &venue.ID, name, capacity, nil
+}
+
+func applyVenueInput(venue *models.Venue, input VenueInput) {
+	venue.Name = strings.TrimSpace(input.Name)
+	venue.Address = strings.TrimSpace(input.Address)
+	venue.City = strings.TrimSpace(input.City)
+	venue.Country = strings.TrimSpace(input.Country)
+	venue.Latitude = *input.Latitude
+	venue.Longitude = *input.Longitude
+	venue.DefaultCapacity = input.DefaultCapacity
+	venue.AccessibilityNotes = input.AccessibilityNotes
+}
+
+func (s *EventService) parseEventDate(dateStr string) (time.Time, error) {
+	return s.parseEventTime(dateStr, time.UTC)
+}
+
+// parseEventTime accepts RFC 3339 timestamps with any UTC offset. Timestamps
+// without an offset are read as wall-clock time in the given location. The
+// result is always in UTC.
+func (s *EventService) parseEventTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.UTC(), nil
+	}
+
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	return time.Time{}, utils.Errorf("invalid date %q, expected RFC 3339 such as 2025-06-01T19:30:00+03:00", value)
+}
+
+// resolveTimeZone validates an IANA time zone name, defaulting to UTC
+func (s *EventService) resolveTimeZone(name string) (string, *time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "UTC", time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return "", nil, utils.Errorf("unknown time zone %q", name)
+	}
+	return loc.String(), loc, nil
+}
+
+func (s *EventService) normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+
+	switch {
+	case pageSize < 1:
+		pageSize = 10
+	case pageSize > 100:
+		pageSize = 100
+	}
+	return page, pageSize
+}
+
+func (s *EventService) createPaginatedResponse(events []models.Event, total int64, page, pageSize int) (*PaginatedEvents, error) {
+	eventResponses := make([]EventResponse, 0, len(events))
+
+	for _, event := range events {
+		eventResponses = append(eventResponses, *s.mapEventToResponse(event))
+	}
+
+	if err := s.applyAvailability(eventResponses); err != nil {
+		return nil, err
+	}
+
+	totalPages := 1
+	if total > 0 {
+		totalPages = (int(total) + pageSize - 1) / pageSize
+	}
+
+	return &PaginatedEvents{
+		Events:     eventResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func (s *EventService) createCursorResponse(events []models.Event, page repository.CursorPage, limit int) (*PaginatedEvents, error) {
+	result, err := s.createPaginatedResponse(events, 0, 0, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result.TotalPages = 0
+	result.NextCursor = page.Next
+	result.PrevCursor = page.Prev
+	return result, nil
+}
+
+func (s *EventService) processTags(event *models.Event, tagNames []string) error {
+	if len(tagNames) == 0 {
+		return nil
+	}
+
+	var tagsToAdd []models.Tag
+
+	for _, name := range normalizeTagNames(tagNames) {
+		tag, err := s.EventRepo.FindOrCreateTag(name)
+		if err != nil {
+			continue
+		}
+		tagsToAdd = append(tagsToAdd, *tag)
+	}
+
+	if len(tagsToAdd) > 0 {
+		if err := s.EventRepo.DB.Model(event).Association("Tags").Replace(tagsToAdd); err != nil {
+			return utils.Errorf("failed to associate tags with event: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *EventService) updateEventTags(event *models.Event, tagNames []string) error {
+	if err := s.EventRepo.DB.Model(event).Association("Tags").Clear(); err != nil {
+		return err
+	}
+
+	return s.processTags(event, tagNames)
+}
+
+func (s *EventService) updateEventFields(event *models.Event, input UpdateEventInput) error {
+	if input.Name != "" {
+		event.Name = input.Name
+	}
+
+	if input.Description != "" {
+		event.Description = input.Description
+	}
+
+	if input.CategoryID != 0 {
+		if err := s.checkCategory(input.CategoryID, event.OrganizationID); err != nil {
+			return err
+		}
+		event.CategoryID = input.CategoryID
+	}
+
+	if input.TimeZone != "" {
+		timeZone, _, err := s.resolveTimeZone(input.TimeZone)
+		if err != nil {
+			return err
+		}
+		event.TimeZone = timeZone
+	}
+
+	// Moving the start keeps the event's duration unless a new end is given
+	duration := event.Duration()
+	if duration == 0 {
+		duration = defaultEventDuration
+	}
+
+	if input.EventDate != "" {
+		parsedDate, err := s.parseEventTime(input.EventDate, event.Location())
+		if err == nil {
+			event.EventDate = parsedDate
+			event.EndDate = parsedDate.Add(duration)
+		} else {
+			return errors.New("invalid date format")
+		}
+	}
+
+	if input.EndDate != "" {
+		endDate, err := s.parseEventTime(input.EndDate, event.Location())
+		if err != nil {
+			return errors.New("invalid end date format")
+		}
+		event.EndDate = endDate
+	} else if input.DurationMinutes != nil {
+		event.EndDate = event.EventDate.Add(time.Duration(*input.DurationMinutes) * time.Minute)
+	}
+
+	if !event.EndDate.After(event.EventDate) {
+		return errors.New("event must end after it starts")
+	}
+
+	if input.VenueID != nil {
+		if *input.VenueID == 0 {
+			event.VenueID = nil
+		} else {
+			venue, err := s.EventRepo.GetVenueByID(*input.VenueID)
+			if err != nil {
+				return errors.New("venue not found")
+			}
+			event.VenueID = &venue.ID
+			event.Venue = venue.Name
+		}
+		event.VenueDetails = nil
+	}
+
+	if input.Venue != "" {
+		event.Venue = input.Venue
+	}
+
+	if input.Price != nil {
+		if *input.Price < 0 {
+			return errors.New("price cannot be negative")
+		}
+		event.Price = *input.Price
+	}
+
+	if input.Capacity != nil {
+		if *input.Capacity < 0 {
+			return errors.New("capacity cannot be negative")
+		}
+		if *input.Capacity > 0 {
+			booked, err := s.BookingRepo.CountActiveSeats(event.ID)
+			if err != nil {
+				return err
+			}
+			if int64(*input.Capacity) < booked {
+				return utils.Errorf("capacity cannot be lower than the %d seats already booked", booked)
+			}
+		}
+		event.Capacity = *input.Capacity
+	}
+
+	if input.MaxTicketsPerUser != nil {
+		if *input.MaxTicketsPerUser < 0 {
+			return errors.New("ticket limit cannot be negative")
+		}
+		event.MaxTicketsPerUser = *input.MaxTicketsPerUser
+	}
+
+	return nil
+}
+
+func (s *EventService) mapEventToResponse(event models.Event) *EventResponse {
+	var tags []models.Tag
+	if len(event.Tags) > 0 {
+		tags = make([]models.Tag, len(event.Tags))
+		copy(tags, event.Tags)
+	}
+
+	response := &EventResponse{
+		ID:                event.ID,
+		Name:              event.Name,
+		Description:       event.Description,
+		Category:          event.Category,
+		OrganizationID:    event.OrganizationID,
+		EventDate:         event.EventDate.UTC(),
+		EndDate:           event.EndDate.UTC(),
+		DurationMinutes:   int(event.Duration() / time.Minute),
+		TimeZone:          event.Location().String(),
+		LocalEventDate:    event.EventDate.In(event.Location()),
+		LocalEndDate:      event.EndDate.In(event.Location()),
+		Venue:             event.Venue,
+		VenueID:           event.VenueID,
+		VenueDetails:      event.VenueDetails,
+		Price:             event.Price,
+		Capacity:          event.Capacity,
+		MaxTicketsPerUser: event.MaxTicketsPerUser,
+		ImageURL:          event.ImageURL,
+		Tags:              tags,
+		TicketTypes:       s.mapTicketTypes(event.TicketTypes),
+		SeriesID:          event.SeriesID,
+		Status:            event.Status,
+		PublishAt:         event.PublishAt,
+		StatusNote:        event.StatusNote,
+		CreatedAt:         event.CreatedAt,
+		UpdatedAt:         event.UpdatedAt,
+		translations:      event.Translations,
+	}
+
+	if event.DeletedAt.Valid {
+		deletedAt := event.DeletedAt.Time
+		response.DeletedAt = &deletedAt
+	}
+
+	return response
+}
+
+func (s *EventService) mapTicketTypes(ticketTypes []models.TicketType) []TicketTypeResponse {
+	responses := make([]TicketTypeResponse, 0, len(ticketTypes))
+	for _, t := range ticketTypes {
+		responses = append(responses, TicketTypeResponse{
+			ID:           t.ID,
+			Name:         t.Name,
+			Description:  t.Description,
+			Price:        t.Price,
+			Quota:        t.Quota,
+			SaleStartsAt: t.SaleStartsAt,
+			SaleEndsAt:   t.SaleEndsAt,
+		})
+	}
+	return responses
+}
+
+// applyAvailability fills in remaining seats and the sold-out flag from the
+// current bookings. Events without a capacity keep a nil RemainingSeats.
+// Ticket tiers get the same treatment against their own quota.
+func (s *EventService) applyAvailability(events []EventResponse) error {
+	ids := make([]uint, 0, len(events))
+	var tierIDs []uint
+	for _, evt := range events {
+		if evt.Capacity > 0 {
+			ids = append(ids, evt.ID)
+		}
+		for _, t := range evt.TicketTypes {
+			if t.Quota > 0 {
+				tierIDs = append(tierIDs, t.ID)
+			}
+		}
+	}
+
+	booked, err := s.BookingRepo.CountActiveSeatsByEvents(ids)
+	if err != nil {
+		return err
+	}
+
+	sold, err := s.BookingRepo.CountActiveSeatsByTicketTypes(tierIDs)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range events {
+		events[i].RemainingSeats = nil
+		events[i].SoldOut = false
+		if events[i].Capacity > 0 {
+			remaining := max(events[i].Capacity-int(booked[events[i].ID]), 0)
+			events[i].RemainingSeats = &remaining
+			events[i].SoldOut = remaining == 0
+		}
+
+		// Tiers may be shared with a cached response, so update a fresh copy
+		tiers := make([]TicketTypeResponse, len(events[i].TicketTypes))
+		copy(tiers, events[i].TicketTypes)
+		for j := range tiers {
+			tiers[j].RemainingSeats = nil
+			tiers[j].SoldOut = events[i].SoldOut
+			window := models.TicketType{SaleStartsAt: tiers[j].SaleStartsAt, SaleEndsAt: tiers[j].SaleEndsAt}
+			tiers[j].OnSale = window.OnSale(now)
+			if tiers[j].Quota == 0 {
+				continue
+			}
+
+			remaining := max(tiers[j].Quota-int(sold[tiers[j].ID]), 0)
+			if events[i].RemainingSeats != nil {
+				remaining = min(remaining, *events[i].RemainingSeats)
+			}
+			tiers[j].RemainingSeats = &remaining
+			tiers[j].SoldOut = remaining == 0
 		}
-		event.CategoryID = input.CategoryID
+		events[i].TicketTypes = tiers
 	}
+	return nil
+}
 
-	if input.EventDate != "" {
-		parsedDate, err := s.parseEventDate(input.EventDate)
-		if err == nil {
-			event.EventDate = parsedDate
-		} else {
-			return errors.New("invalid date format")
+// withAvailability returns a copy of a (possibly cached) event response with
+// up-to-date seat availability, leaving the cached value untouched.
+func (s *EventService) withAvailability(event *EventResponse) (*EventResponse, error) {
+	events := []EventResponse{*event}
+	if err := s.applyAvailability(events); err != nil {
+		return nil, err
+	}
+	return &events[0], nil
+}
+
+func (s *EventService) GetTicketTypes(eventID uint) ([]TicketTypeResponse, error) {
+	event, err := s.GetEventByID(eventID, Actor{})
+	if err != nil {
+		return nil, errors.New("event not found")
+	}
+
+	return event.TicketTypes, nil
+}
+
+func (s *EventService) CreateTicketType(actor Actor, eventID uint, input TicketTypeInput) (*models.TicketType, error) {
+	if _, err := s.managedEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+
+	ticketType := models.TicketType{EventID: eventID}
+	if err := s.applyTicketTypeInput(&ticketType, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.EventRepo.CreateTicketType(&ticketType); err != nil {
+		return nil, err
+	}
+
+	s.invalidateEventCache(eventID)
+	return &ticketType, nil
+}
+
+func (s *EventService) UpdateTicketType(actor Actor, eventID, id uint, input TicketTypeInput) (*models.TicketType, error) {
+	if _, err := s.managedEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+
+	ticketType, err := s.EventRepo.GetTicketType(eventID, id)
+	if err != nil {
+		return nil, errors.New("ticket type not found")
+	}
+
+	if err := s.applyTicketTypeInput(ticketType, input); err != nil {
+		return nil, err
+	}
+
+	if ticketType.Quota > 0 {
+		sold, err := s.BookingRepo.CountActiveSeatsByTicketType(id)
+		if err != nil {
+			return nil, err
+		}
+		if int64(ticketType.Quota) < sold {
+			return nil, utils.Errorf("quota cannot be lower than the %d tickets already sold", sold)
 		}
 	}
 
-	if input.Venue != "" {
-		event.Venue = input.Venue
+	if err := s.EventRepo.UpdateTicketType(ticketType); err != nil {
+		return nil, err
+	}
+
+	s.invalidateEventCache(eventID)
+	return ticketType, nil
+}
+
+func (s *EventService) DeleteTicketType(actor Actor, eventID, id uint) error {
+	if _, err := s.managedEvent(actor, eventID); err != nil {
+		return err
+	}
+
+	if _, err := s.EventRepo.GetTicketType(eventID, id); err != nil {
+		return errors.New("ticket type not found")
+	}
+
+	hasBookings, err := s.BookingRepo.HasTicketTypeBookings(id)
+	if err != nil {
+		return err
+	}
+	if hasBookings {
+		return errors.New("cannot delete a ticket type that has bookings")
 	}
 
-	if input.Price >= 0 {
-		event.Price = input.Price
+	if err := s.EventRepo.DeleteTicketType(id); err != nil {
+		return err
 	}
 
+	s.invalidateEventCache(eventID)
 	return nil
 }
 
-func (s *EventService) mapEventToResponse(event models.Event) *EventResponse {
-	var tags []models.Tag
-	if len(event.Tags) > 0 {
-		tags = make([]models.Tag, len(event.Tags))
-		copy(tags, event.Tags)
+func (s *EventService) applyTicketTypeInput(ticketType *models.TicketType, input TicketTypeInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return errors.New("ticket type name is required")
+	}
+	if input.Price < 0 || input.Quota < 0 {
+		return errors.New("price and quota cannot be negative")
+	}
+
+	ticketType.Name = name
+	ticketType.Description = input.Description
+	ticketType.Price = input.Price
+	ticketType.Quota = input.Quota
+	ticketType.SaleStartsAt = nil
+	ticketType.SaleEndsAt = nil
+
+	if input.SaleStartsAt != "" {
+		startsAt, err := s.parseEventDate(input.SaleStartsAt)
+		if err != nil {
+			return errors.New("invalid sale start date format")
+		}
+		ticketType.SaleStartsAt = &startsAt
+	}
+
+	if input.SaleEndsAt != "" {
+		endsAt, err := s.parseEventDate(input.SaleEndsAt)
+		if err != nil {
+			return errors.New("invalid sale end date format")
+		}
+		ticketType.SaleEndsAt = &endsAt
+	}
+
+	if ticketType.SaleStartsAt != nil && ticketType.SaleEndsAt != nil && !ticketType.SaleEndsAt.After(*ticketType.SaleStartsAt) {
+		return errors.New("sale end must be after sale start")
+	}
+
+	return nil
+}
+
+// invalidateEventCache drops the cached copies of an event after a change
+func (s *EventService) invalidateEventCache(id uint) {
+	s.invalidateEventsCache([]uint{id})
+}
+
+// invalidateEventsCache drops the cached copies of several events at once
+func (s *EventService) invalidateEventsCache(ids []uint) {
+	s.cacheMutex.Lock()
+	for _, id := range ids {
+		s.cache.Delete(fmt.Sprintf("event_%d", id))
+	}
+	s.cache.Delete("recent_events")
+	s.cacheMutex.Unlock()
+
+	go func() {
+		fmt.Println("[CACHE TRIGGER] Event changed, refreshing cache")
+		s.cacheRecentEvents()
+	}()
+}
+
+// UpdateEventStatus moves an event through its publication lifecycle.
+// Cancelling keeps the event and its bookings on record: active bookings are
+// cancelled and the waitlist is closed instead of anything being deleted.
+func (s *EventService) UpdateEventStatus(actor Actor, id uint, input UpdateEventStatusInput) (*EventResponse, error) {
+	next, ok := models.ParseEventStatus(input.Status)
+	if !ok {
+		return nil, utils.Errorf("unknown event status %q", input.Status)
+	}
+
+	event, err := s.managedEvent(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	current := event.Status
+	if current == models.EventStatusScheduled && event.IsLive(now) {
+		current = models.EventStatusPublished
+	}
+	if !current.CanTransitionTo(next) {
+		return nil, utils.Errorf("%w: %s to %s", ErrInvalidEventTransition, current, next)
+	}
+
+	event.Status = next
+	event.StatusNote = strings.TrimSpace(input.Note)
+
+	switch next {
+	case models.EventStatusScheduled:
+		if input.PublishAt == "" {
+			return nil, errors.New("a publish time is required to schedule an event")
+		}
+		publishAt, err := s.parseEventTime(input.PublishAt, event.Location())
+		if err != nil {
+			return nil, errors.New("invalid publish time format")
+		}
+		if !publishAt.After(now) {
+			return nil, errors.New("publish time must be in the future")
+		}
+		event.PublishAt = &publishAt
+	case models.EventStatusPublished:
+		if event.PublishAt == nil || event.PublishAt.After(now) {
+			event.PublishAt = &now
+		}
+	case models.EventStatusDraft:
+		event.PublishAt = nil
+	}
+
+	if next == models.EventStatusCancelled {
+		reason := "event cancelled"
+		if event.StatusNote != "" {
+			reason += ": " + event.StatusNote
+		}
+
+		err = s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+			if err := s.EventRepo.WithTx(tx).SetStatus(event); err != nil {
+				return err
+			}
+
+			cancelled, err := s.BookingRepo.WithTx(tx).CancelEventBookings(id, &actor.UserID, reason)
+			if err != nil {
+				return utils.Errorf("failed to cancel bookings: %w", err)
+			}
+			fmt.Printf("[EVENT STATUS] Event %d cancelled, %d bookings cancelled\n", id, cancelled)
+
+			return s.WaitlistRepo.WithTx(tx).CloseByEvent(id)
+		})
+	} else {
+		err = s.EventRepo.SetStatus(event)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateEventCache(id)
+	return s.getEventResponse(id)
+}
+
+// resolveInitialStatus works out the status of a new event. Without an
+// explicit status an event is published right away, or scheduled when a
+// publish time is given.
+func (s *EventService) resolveInitialStatus(status, publishAt string, loc *time.Location) (models.EventStatus, *time.Time, error) {
+	now := time.Now()
+
+	if status == "" {
+		status = string(models.EventStatusPublished)
+		if publishAt != "" {
+			status = string(models.EventStatusScheduled)
+		}
+	}
+
+	parsed, ok := models.ParseEventStatus(status)
+	if !ok {
+		return "", nil, utils.Errorf("unknown event status %q", status)
+	}
+
+	switch parsed {
+	case models.EventStatusDraft:
+		return parsed, nil, nil
+	case models.EventStatusPublished:
+		return parsed, &now, nil
+	case models.EventStatusScheduled:
+		if publishAt == "" {
+			return "", nil, errors.New("a publish time is required to schedule an event")
+		}
+		at, err := s.parseEventTime(publishAt, loc)
+		if err != nil {
+			return "", nil, errors.New("invalid publish time format")
+		}
+		if !at.After(now) {
+			return models.EventStatusPublished, &at, nil
+		}
+		return parsed, &at, nil
+	}
+
+	return "", nil, utils.Errorf("new events cannot start out as %s", parsed)
+}
+
+// publishDueEvents publishes scheduled events whose time has come
+func (s *EventService) publishDueEvents() {
+	ids, err := s.EventRepo.PublishDue(time.Now())
+	if err != nil {
+		fmt.Println("[EVENT STATUS] Failed to publish scheduled events:", err)
+		return
 	}
 
-	return &EventResponse{
-		ID:          event.ID,
-		Name:        event.Name,
-		Description: event.Description,
-		Category:    event.Category,
-		EventDate:   event.EventDate,
-		Venue:       event.Venue,
-		Price:       event.Price,
-		ImageURL:    event.ImageURL,
-		Tags:        tags,
-		CreatedAt:   event.CreatedAt,
-		UpdatedAt:   event.UpdatedAt,
+	if len(ids) > 0 {
+		fmt.Printf("[EVENT STATUS] Published %d scheduled events\n", len(ids))
+		s.invalidateEventsCache(ids)
 	}
 }
 
-func (s *EventService) DeleteEvent(id uint) error {
-	evt, err := s.EventRepo.GetEventByID(id)
+// isPubliclyVisible reports whether anyone may view the event. Cancelled
+// events stay viewable so attendees can see what happened.
+func isPubliclyVisible(event *EventResponse) bool {
+	state := models.Event{Status: event.Status, PublishAt: event.PublishAt}
+	return state.IsLive(time.Now()) || event.Status == models.EventStatusCancelled
+}
+
+func (s *EventService) DeleteEvent(actor Actor, id uint) error {
+	evt, err := s.managedEvent(actor, id)
 	if err != nil {
 		return err
 	}
 
-	if err := s.BookingRepo.DeleteBookingsByEvent(id); err != nil {
-		return fmt.Errorf("failed to delete associated bookings: %w", err)
+	// Record a series occurrence as an exception so the series does not
+	// generate it again
+	if err := s.excludeOccurrence(s.EventRepo, evt); err != nil {
+		return utils.Errorf("failed to exclude occurrence from its series: %w", err)
+	}
+
+	return s.deleteEvent(evt, time.Now())
+}
+
+func (s *EventService) deleteEvent(evt *models.Event, at time.Time) error {
+	err := s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+		_, err := s.trashEvent(tx, evt, at)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
-	if evt.ImageURL != "" {
-		_ = s.StorageService.DeleteFile(evt.ImageURL)
+	s.invalidateEventCache(evt.ID)
+	return nil
+}
+
+// trashEvent moves an event to the trash together with its bookings, all
+// stamped with the same time, and returns how many bookings went with it.
+// The waitlist is closed, while tags, ticket types and the image are kept
+// until the event is purged.
+func (s *EventService) trashEvent(tx *gorm.DB, evt *models.Event, at time.Time) (int64, error) {
+	bookings, err := s.BookingRepo.WithTx(tx).SoftDeleteByEvent(evt.ID, at)
+	if err != nil {
+		return 0, utils.Errorf("failed to delete associated bookings: %w", err)
 	}
 
-	err = s.EventRepo.Delete(id)
+	if err := s.WaitlistRepo.WithTx(tx).CloseByEvent(evt.ID); err != nil {
+		return 0, utils.Errorf("failed to close the waitlist: %w", err)
+	}
 
-	if err == nil {
-		s.cacheMutex.Lock()
-		s.cache.Delete(fmt.Sprintf("event_%d", id))
-		s.cache.Delete("recent_events")
-		s.cacheMutex.Unlock()
+	return bookings, s.EventRepo.WithTx(tx).SoftDelete(evt.ID, at)
+}
 
-		go func() {
-			fmt.Println("[CACHE TRIGGER] Event deleted, refreshing cache")
-			s.cacheRecentEvents()
-		}()
+// GetDeletedEvents lists the trashed events the actor manages
+func (s *EventService) GetDeletedEvents(actor Actor, page, pageSize int) (*PaginatedEvents, error) {
+	scope, err := actor.scope()
+	if err != nil {
+		return nil, err
+	}
+
+	page, pageSize = s.normalizePagination(page, pageSize)
+
+	events, total, err := s.EventRepo.GetDeletedEvents(page, pageSize, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createPaginatedResponse(events, total, page, pageSize)
+}
+
+// RestoreEvent takes an event out of the trash together with the bookings
+// deleted with it. Tag links and ticket types were kept and come back as is.
+func (s *EventService) RestoreEvent(actor Actor, id uint) (*EventResponse, error) {
+	scope, err := actor.scope()
+	if err != nil {
+		return nil, err
+	}
+
+	evt, err := s.EventRepo.GetDeletedEvent(id, scope)
+	if err != nil {
+		return nil, errors.New("event not found in trash")
+	}
+
+	if evt.Category.DeletedAt.Valid {
+		return nil, errors.New("the event's category is in the trash, restore the category first")
+	}
+
+	err = s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+		return s.restoreEvent(tx, evt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateEventCache(id)
+	return s.getEventResponse(id)
+}
+
+// restoreEvent brings back an event and the bookings trashed with it. A
+// series occurrence comes back as a standalone event, since its series
+// recorded the deletion as an exception and moved on without it.
+func (s *EventService) restoreEvent(tx *gorm.DB, evt *models.Event) error {
+	eventRepo := s.EventRepo.WithTx(tx)
+
+	bookings, err := s.BookingRepo.WithTx(tx).RestoreByEvent(evt.ID)
+	if err != nil {
+		return utils.Errorf("failed to restore bookings: %w", err)
+	}
+
+	if err := eventRepo.Restore(evt.ID); err != nil {
+		return err
+	}
+
+	if evt.SeriesID != nil {
+		if err := eventRepo.DetachFromSeries(evt.ID); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("[TRASH] Restored event %d with %d bookings\n", evt.ID, bookings)
+	return nil
+}
+
+// GetDeletedCategories lists the trashed categories the actor manages
+func (s *EventService) GetDeletedCategories(actor Actor) ([]TrashedCategory, error) {
+	scope, err := actor.scope()
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.EventRepo.GetDeletedCategories(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	trashed := make([]TrashedCategory, 0, len(categories))
+	for _, category := range categories {
+		trashed = append(trashed, TrashedCategory{Category: category, DeletedAt: category.DeletedAt.Time})
+	}
+	return trashed, nil
+}
+
+// RestoreCategory takes a category out of the trash along with the events
+// that were deleted with it
+func (s *EventService) RestoreCategory(actor Actor, id uint) (*models.Category, error) {
+	scope, err := actor.scope()
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := s.EventRepo.GetDeletedCategory(id, scope)
+	if err != nil {
+		return nil, errors.New("category not found in trash")
+	}
+
+	events, err := s.EventRepo.GetEventsDeletedWith(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range events {
+			if err := s.restoreEvent(tx, &events[i]); err != nil {
+				return utils.Errorf("failed to restore event %d: %w", events[i].ID, err)
+			}
+		}
+		eventRepo := s.EventRepo.WithTx(tx)
+
+		// A category whose parent is gone comes back at the top level
+		if category.ParentID != nil {
+			if _, err := eventRepo.GetCategoryByID(*category.ParentID); err != nil {
+				if err := eventRepo.SetCategoryParent(id, nil); err != nil {
+					return err
+				}
+			}
+		}
+		return eventRepo.RestoreCategory(id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(events))
+	for _, evt := range events {
+		ids = append(ids, evt.ID)
+	}
+	s.invalidateEventsCache(ids)
+
+	return s.EventRepo.GetCategoryByID(id)
+}
+
+// PurgeTrash removes events, bookings and categories that have been in the
+// trash for longer than olderThan for good, along with the events' images
+func (s *EventService) PurgeTrash(olderThan time.Duration) (*PurgeReport, error) {
+	cutoff := time.Now().Add(-olderThan)
+	report := &PurgeReport{}
+
+	events, err := s.EventRepo.GetPurgeableEvents(cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, evt := range events {
+		var bookings int64
+		err := s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+			var err error
+			if bookings, err = s.BookingRepo.WithTx(tx).PurgeByEvent(evt.ID); err != nil {
+				return err
+			}
+			if err := s.WaitlistRepo.WithTx(tx).DeleteByEvent(evt.ID); err != nil {
+				return err
+			}
+			return s.EventRepo.WithTx(tx).Purge(evt.ID)
+		})
+		if err != nil {
+			return report, utils.Errorf("failed to purge event %d: %w", evt.ID, err)
+		}
+
+		report.Events++
+		report.Bookings += bookings
+
+		if evt.ImageURL != "" {
+			if err := s.StorageService.DeleteFile(evt.ImageURL); err != nil {
+				fmt.Printf("[TRASH] Failed to delete image of event %d: %v\n", evt.ID, err)
+			} else {
+				report.Images++
+			}
+		}
+	}
+
+	bookings, err := s.BookingRepo.PurgeDeleted(cutoff)
+	if err != nil {
+		return report, utils.Errorf("failed to purge bookings: %w", err)
 	}
+	report.Bookings += bookings
 
-	return err
+	if report.Categories, err = s.EventRepo.PurgeCategories(cutoff); err != nil {
+		return report, utils.Errorf("failed to purge categories: %w", err)
+	}
+
+	return report, nil
 }
 
 func (s *EventService) GetRecentEvents() (*PaginatedEvents, error) {
@@ -489,7 +2147,14 @@ func (s *EventService) GetRecentEvents() (*PaginatedEvents, error) {
 	if found && !shouldForceRefresh {
 		if events, ok := cachedStuff.(*PaginatedEvents); ok {
 			fmt.Println("[CACHE HIT] Serving recent events from cache")
-			return events, nil
+
+			// Seat counts change with every booking, so refresh them on a copy
+			fresh := *events
+			fresh.Events = append([]EventResponse(nil), events.Events...)
+			if err := s.applyAvailability(fresh.Events); err != nil {
+				return nil, err
+			}
+			return &fresh, nil
 		}
 	}
 
@@ -508,7 +2173,7 @@ func (s *EventService) cacheRecentEvents() (*PaginatedEvents, error) {
 	s.cacheMutex.Lock()
 	defer s.cacheMutex.Unlock()
 
-	evts, _, err := s.EventRepo.GetAll(1, 8, 0)
+	evts, _, err := s.EventRepo.GetAll(1, 8, repository.EventFilter{PublicOnly: true})
 	if err != nil {
 		fmt.Println("[CACHE ERROR] Failed to fetch events for cache:", err)
 		return nil, err
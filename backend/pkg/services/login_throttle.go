@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/robaa12/mawid/pkg/models"
+	"gorm.io/gorm"
+)
+
+// ErrTooManyLoginAttempts is returned while an email or client IP has to
+// wait before trying to log in again
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts, please try again later")
+
+// LoginThrottledError is an ErrTooManyLoginAttempts that tells how long the
+// caller has to wait
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return ErrTooManyLoginAttempts.Error()
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
+// After loginFreeAttempts failures each further attempt on an email has to
+// wait twice as long as the one before, up to loginDelayMax
+const (
+	loginFreeAttempts = 2
+	loginDelayBase    = time.Second
+	loginDelayMax     = 30 * time.Second
+)
+
+// dummyUser is checked against when nobody has the email that was typed in,
+// so unknown emails take as long to reject as wrong passwords
+var dummyUser = func() models.User {
+	user := models.User{Password: "mawid-login-timing"}
+	_ = user.HashPassword()
+	return user
+}()
+
+// UnlockUser lifts the login lockout of a user's email. Lockouts of client
+// IPs run out on their own.
+func (s *AuthService) UnlockUser(userID uint) (*models.User, error) {
+	user, err := s.UserRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if err := s.ThrottleRepo.Clear(models.LoginThrottleEmail, throttleSubject(user.Email)); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Login lockout lifted for user %d: %s", user.ID, user.Email)
+	return user, nil
+}
+
+// PurgeLoginThrottles drops failed login and password reset counters that
+// no longer count
+func (s *AuthService) PurgeLoginThrottles() (int64, error) {
+	return s.ThrottleRepo.PurgeExpired(time.Now().Add(-max(s.lockoutDuration(), passwordResetWindow)))
+}
+
+// checkLoginThrottle rejects a login while the email or the client IP is
+// locked out, or while the email is still waiting out its last failure
+func (s *AuthService) checkLoginThrottle(email, ip string, now time.Time) error {
+	var wait time.Duration
+
+	if throttle, err := s.ThrottleRepo.Get(models.LoginThrottleEmail, throttleSubject(email)); err == nil {
+		wait = max(wait, s.throttleWait(throttle, now, true))
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if ip != "" {
+		if throttle, err := s.ThrottleRepo.Get(models.LoginThrottleIP, ip); err == nil {
+			wait = max(wait, s.throttleWait(throttle, now, false))
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	if wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// throttleWait is how long until the next attempt is allowed. Progressive
+// delays only apply to emails; a shared IP would otherwise slow down
+// everyone behind it.
+func (s *AuthService) throttleWait(throttle *models.LoginThrottle, now time.Time, progressive bool) time.Duration {
+	if throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+		return throttle.LockedUntil.Sub(now)
+	}
+	if !progressive || throttle.LastFailureAt.Before(now.Add(-s.lockoutDuration())) {
+		return 0
+	}
+
+	next := throttle.LastFailureAt.Add(loginDelay(throttle.Failures))
+	if next.After(now) {
+		return next.Sub(now)
+	}
+	return 0
+}
+
+// recordLoginFailure counts a failed login against the email and the client
+// IP, locking out whichever reached its limit
+func (s *AuthService) recordLoginFailure(email, ip string, now time.Time) {
+	window := s.lockoutDuration()
+	limits := []struct {
+		scope, subject string
+		max            int
+	}{
+		{models.LoginThrottleEmail, throttleSubject(email), s.Config.LoginMaxAttempts},
+		{models.LoginThrottleIP, ip, s.Config.LoginIPMaxAttempts},
+	}
+
+	for _, limit := range limits {
+		if limit.subject == "" {
+			continue
+		}
+
+		throttle, err := s.ThrottleRepo.RecordFailure(limit.scope, limit.subject, now, now.Add(-window))
+		if err != nil {
+			log.Printf("Error recording failed login for %s %s: %v", limit.scope, limit.subject, err)
+			continue
+		}
+		if limit.max <= 0 || throttle.Failures < limit.max {
+			continue
+		}
+
+		if err := s.ThrottleRepo.Lock(limit.scope, limit.subject, now.Add(window)); err != nil {
+			log.Printf("Error locking out %s %s: %v", limit.scope, limit.subject, err)
+			continue
+		}
+		log.Printf("Login locked out for %s %s after %d failed attempts", limit.scope, limit.subject, throttle.Failures)
+	}
+}
+
+func (s *AuthService) lockoutDuration() time.Duration {
+	if s.Config.LoginLockoutDuration <= 0 {
+		return 15 * time.Minute
+	}
+	return s.Config.LoginLockoutDuration
+}
+
+func loginDelay(failures int) time.Duration {
+	if failures <= loginFreeAttempts {
+		return 0
+	}
+
+	shift := failures - loginFreeAttempts - 1
+	if shift >= 5 {
+		return loginDelayMax
+	}
+	return min(loginDelayBase<<shift, loginDelayMax)
+}
+
+// throttleSubject fits a typed in email into the throttle table, which only
+// has to tell emails apart
+func throttleSubject(email string) string {
+	if len(email) > 100 {
+		return email[:100]
+	}
+	return email
+}
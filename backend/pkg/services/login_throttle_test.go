@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robaa12/mawid/config"
+	"github.com/robaa12/mawid/pkg/models"
+)
+
+func TestLoginDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 0},
+		{failures: 1, want: 0},
+		{failures: 2, want: 0},
+		{failures: 3, want: time.Second},
+		{failures: 4, want: 2 * time.Second},
+		{failures: 5, want: 4 * time.Second},
+		{failures: 6, want: 8 * time.Second},
+		{failures: 7, want: 16 * time.Second},
+		{failures: 8, want: 30 * time.Second},
+		{failures: 9, want: 30 * time.Second},
+		{failures: 1000, want: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := loginDelay(tt.failures); got != tt.want {
+			t.Errorf("loginDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestThrottleWait(t *testing.T) {
+	s := &AuthService{Config: &config.Config{LoginLockoutDuration: 15 * time.Minute}}
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	at := func(offset time.Duration) *time.Time {
+		value := now.Add(offset)
+		return &value
+	}
+
+	tests := []struct {
+		name        string
+		failures    int
+		lastFailure time.Duration
+		lockedUntil *time.Time
+		progressive bool
+		want        time.Duration
+	}{
+		{name: "free attempts", failures: 2, progressive: true, want: 0},
+		{name: "first delay", failures: 3, lastFailure: -300 * time.Millisecond, progressive: true, want: 700 * time.Millisecond},
+		{name: "first delay waited out", failures: 3, lastFailure: -time.Second, progressive: true, want: 0},
+		{name: "delay doubles", failures: 6, lastFailure: -time.Second, progressive: true, want: 7 * time.Second},
+		{name: "delay is capped", failures: 12, lastFailure: -10 * time.Second, progressive: true, want: 20 * time.Second},
+		{name: "stale counter", failures: 12, lastFailure: -16 * time.Minute, progressive: true, want: 0},
+		{name: "locked out", failures: 5, lockedUntil: at(5 * time.Minute), progressive: true, want: 5 * time.Minute},
+		{name: "lockout over", failures: 3, lastFailure: -20 * time.Minute, lockedUntil: at(-5 * time.Minute), progressive: true, want: 0},
+		{name: "IPs get no delay", failures: 12, progressive: false, want: 0},
+		{name: "IPs get locked out", failures: 50, lockedUntil: at(time.Minute), progressive: false, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttle := &models.LoginThrottle{
+				Failures:      tt.failures,
+				LastFailureAt: now.Add(tt.lastFailure),
+				LockedUntil:   tt.lockedUntil,
+			}
+			if got := s.throttleWait(throttle, now, tt.progressive); got != tt.want {
+				t.Errorf("throttleWait = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/robaa12/mawid/internal/utils"
+	"github.com/robaa12/mawid/pkg/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidResetToken  = errors.New("invalid or expired password reset token")
+	ErrIncorrectPassword  = errors.New("current password is incorrect")
+	ErrPasswordNotChanged = errors.New("new password must be different from the current one")
+)
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// Reset emails for one address are limited to passwordResetMaxRequests until
+// passwordResetWindow passes without a request, so the endpoint cannot be
+// used to flood someone's inbox
+const (
+	passwordResetMaxRequests = 3
+	passwordResetWindow      = time.Hour
+)
+
+// RequestPasswordReset mails a single-use reset link to the user with the
+// given email. The work happens in the background and the caller always
+// gets the same answer at the same speed, so the endpoint cannot be used to
+// find out who has an account. Sessions are left alone until the password
+// actually changes, or anyone knowing an email could log its owner out.
+func (s *AuthService) RequestPasswordReset(input ForgotPasswordInput) {
+	email := strings.TrimSpace(strings.ToLower(input.Email))
+	go func() {
+		if err := s.sendPasswordReset(email, time.Now()); err != nil {
+			log.Printf("Error handling password reset request for %s: %v", email, err)
+		}
+	}()
+}
+
+func (s *AuthService) sendPasswordReset(email string, now time.Time) error {
+	// Requests are counted by the email typed in, registered or not
+	throttle, err := s.ThrottleRepo.RecordFailure(models.LoginThrottleReset, throttleSubject(email), now, now.Add(-passwordResetWindow))
+	if err != nil {
+		return utils.Errorf("failed to count password reset request: %w", err)
+	}
+	if throttle.Failures > passwordResetMaxRequests {
+		log.Printf("Password reset for %s not sent after %d recent requests", email, throttle.Failures)
+		return nil
+	}
+
+	user, err := s.UserRepo.GetByEmail(email)
+	if err != nil {
+		log.Printf("Password reset requested for unknown email: %s", email)
+		return nil
+	}
+
+	token, err := utils.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := s.Config.PasswordResetTTL
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	record := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := s.TokenRepo.CreatePasswordResetToken(record); err != nil {
+		return utils.Errorf("failed to store password reset token: %w", err)
+	}
+
+	link := s.Config.PasswordResetURL + "?token=" + url.QueryEscape(token)
+	msg := utils.MailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nWe received a request to reset your Mawid password. Open the link below to choose a new one:\n\n%s\n\n"+
+			"The link expires in %d minutes and can be used once. If you did not ask for a reset, you can ignore this email.\n",
+			user.Name, link, int(ttl.Minutes())),
+	}
+	if err := s.Mailer.Send(msg); err != nil {
+		return utils.Errorf("failed to send password reset email: %w", err)
+	}
+
+	log.Printf("Password reset requested for user %d", user.ID)
+	return nil
+}
+
+// ResetPassword sets a new password with a token from a reset email. The
+// token and any others outstanding for the user are spent, and every
+// session of the user is revoked.
+func (s *AuthService) ResetPassword(input ResetPasswordInput) error {
+	if err := validatePasswordStrength(input.NewPassword); err != nil {
+		return err
+	}
+
+	hashed, err := hashPassword(input.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.TokenRepo.DB.Transaction(func(tx *gorm.DB) error {
+		tokens := s.TokenRepo.WithTx(tx)
+		users := s.UserRepo.WithTx(tx)
+
+		reset, err := tokens.GetPasswordResetTokenForUpdate(utils.HashToken(input.Token))
+		if err != nil {
+			return ErrInvalidResetToken
+		}
+
+		now := time.Now()
+		if reset.UsedAt != nil || !reset.ExpiresAt.After(now) {
+			return ErrInvalidResetToken
+		}
+
+		user, err := users.GetByID(reset.UserID)
+		if err != nil {
+			return ErrInvalidResetToken
+		}
+
+		if err := users.SetPassword(user.ID, hashed); err != nil {
+			return utils.Errorf("failed to update password: %w", err)
+		}
+		// Following the link proves the user reads mail at this address
+		if !user.IsEmailVerified() {
+			if _, err := users.MarkEmailVerified(user.ID, user.Email, now); err != nil {
+				return err
+			}
+		}
+		if err := tokens.UsePasswordResetTokens(user.ID, now); err != nil {
+			return err
+		}
+
+		log.Printf("Password reset for user %d, revoking all sessions", user.ID)
+		return tokens.RevokeUserTokens(user.ID, now)
+	})
+}
+
+// ChangePassword replaces the password of a logged in user who knows the
+// current one. Every session of the user is revoked, including the one the
+// request came with, and a new session is returned in its place.
+func (s *AuthService) ChangePassword(session Session, input ChangePasswordInput) (*AuthResponse, error) {
+	user, err := s.UserRepo.GetByID(session.UserID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if err := user.CheckPassword(input.CurrentPassword); err != nil {
+		log.Printf("Failed password change attempt for user %d", user.ID)
+		return nil, ErrIncorrectPassword
+	}
+	if err := validatePasswordStrength(input.NewPassword); err != nil {
+		return nil, err
+	}
+	if input.NewPassword == input.CurrentPassword {
+		return nil, ErrPasswordNotChanged
+	}
+
+	hashed, err := hashPassword(input.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	var response *AuthResponse
+	err = s.TokenRepo.DB.Transaction(func(tx *gorm.DB) error {
+		tokens := s.TokenRepo.WithTx(tx)
+		now := time.Now()
+
+		if err := s.UserRepo.WithTx(tx).SetPassword(user.ID, hashed); err != nil {
+			return utils.Errorf("failed to update password: %w", err)
+		}
+		if err := tokens.UsePasswordResetTokens(user.ID, now); err != nil {
+			return err
+		}
+		if err := tokens.RevokeUserTokens(user.ID, now); err != nil {
+			return err
+		}
+
+		response, _, err = s.issueSession(tokens, *user, "")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Password changed for user %d, other sessions revoked", user.ID)
+	return response, nil
+}
+
+func hashPassword(password string) (string, error) {
+	user := models.User{Password: password}
+	if err := user.HashPassword(); err != nil {
+		return "", utils.Errorf("failed to hash password: %w", err)
+	}
+	return user.Password, nil
+}
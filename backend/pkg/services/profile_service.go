@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/robaa12/mawid/internal/utils"
+	"github.com/robaa12/mawid/pkg/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailTaken           = errors.New("user with this email already exists")
+	ErrAdminCannotBeDeleted = errors.New("admin accounts cannot be deleted")
+)
+
+var phoneRegex = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{5,18}[0-9]$`)
+
+// anonymizedName replaces the name of a user who deleted their account and
+// of the attendees that were them
+const anonymizedName = "Deleted user"
+
+// UpdateProfileInput holds the profile fields to change. Fields left out
+// keep their value; an empty phone removes it.
+type UpdateProfileInput struct {
+	Name         *string `json:"name" form:"name"`
+	Email        *string `json:"email" form:"email"`
+	Phone        *string `json:"phone" form:"phone"`
+	RemoveAvatar bool    `json:"remove_avatar" form:"remove_avatar"`
+}
+
+// DeleteAccountInput asks for the password again before an account is
+// deleted for good
+type DeleteAccountInput struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// UpdateProfile changes the user's own profile. A new email address has to
+// be verified again before the user can book, and a new avatar replaces the
+// stored one.
+func (s *AuthService) UpdateProfile(userID uint, input UpdateProfileInput, avatar *multipart.FileHeader) (*models.User, error) {
+	user, err := s.UserRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" || len(name) > 100 {
+			return nil, errors.New("name must be between 1 and 100 characters")
+		}
+		user.Name = name
+	}
+
+	emailChanged := false
+	if input.Email != nil {
+		email := strings.TrimSpace(strings.ToLower(*input.Email))
+		if !emailRegex.MatchString(email) {
+			return nil, errors.New("invalid email format")
+		}
+		if email != user.Email {
+			if existing, err := s.UserRepo.GetByEmail(email); err == nil && existing != nil {
+				return nil, ErrEmailTaken
+			}
+			user.Email = email
+			user.EmailVerifiedAt = nil
+			user.VerificationSentAt = nil
+			emailChanged = true
+		}
+	}
+
+	if input.Phone != nil {
+		phone := strings.TrimSpace(*input.Phone)
+		if phone != "" && !phoneRegex.MatchString(phone) {
+			return nil, errors.New("invalid phone number")
+		}
+		user.Phone = phone
+	}
+
+	oldAvatar := user.AvatarURL
+	if avatar != nil {
+		url, err := s.StorageService.UploadFile(avatar)
+		if err != nil {
+			return nil, err
+		}
+		user.AvatarURL = url
+	} else if input.RemoveAvatar {
+		user.AvatarURL = ""
+	}
+
+	if err := s.UserRepo.Update(user); err != nil {
+		if avatar != nil {
+			_ = s.StorageService.DeleteFile(user.AvatarURL)
+		}
+		return nil, utils.Errorf("failed to update profile: %w", err)
+	}
+
+	if oldAvatar != "" && oldAvatar != user.AvatarURL {
+		if err := s.StorageService.DeleteFile(oldAvatar); err != nil {
+			log.Printf("Error deleting old avatar of user %d: %v", user.ID, err)
+		}
+	}
+
+	if emailChanged {
+		log.Printf("User %d changed their email to %s, verification required", user.ID, user.Email)
+		if err := s.sendVerificationEmail(user); err != nil {
+			log.Printf("Error sending verification email to %s: %v", user.Email, err)
+		}
+	}
+
+	return user, nil
+}
+
+// DeleteAccount cancels the user's bookings for events that have not started
+// yet, takes them off waitlists, revokes their sessions and strips their
+// personal data. Booking records are kept, attached to the anonymized user,
+// so reports still add up.
+func (s *AuthService) DeleteAccount(session Session, input DeleteAccountInput) error {
+	user, err := s.UserRepo.GetByID(session.UserID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.Role == models.RoleAdmin {
+		return ErrAdminCannotBeDeleted
+	}
+	if err := user.CheckPassword(input.Password); err != nil {
+		log.Printf("Failed account deletion attempt for user %d", user.ID)
+		return ErrIncorrectPassword
+	}
+
+	// The placeholder keeps the email column unique while freeing the
+	// address, and the random password can never be typed in
+	suffix, err := utils.NewTokenID()
+	if err != nil {
+		return err
+	}
+	password, err := utils.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+	hashed, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	avatar := user.AvatarURL
+	now := time.Now()
+
+	err = s.UserRepo.DB.Transaction(func(tx *gorm.DB) error {
+		cancelled, err := s.BookingRepo.WithTx(tx).CancelUserBookings(user.ID, now, "account deleted")
+		if err != nil {
+			return utils.Errorf("failed to cancel bookings: %w", err)
+		}
+		log.Printf("User %d deleted their account, %d bookings cancelled", user.ID, cancelled)
+
+		if err := s.WaitlistRepo.WithTx(tx).CloseByUser(user.ID); err != nil {
+			return err
+		}
+		if err := s.BookingRepo.WithTx(tx).AnonymizeAttendees(user.ID, anonymizedName); err != nil {
+			return err
+		}
+
+		tokens := s.TokenRepo.WithTx(tx)
+		if err := tokens.UsePasswordResetTokens(user.ID, now); err != nil {
+			return err
+		}
+		if err := tokens.RevokeUserTokens(user.ID, now); err != nil {
+			return err
+		}
+
+		user.Name = anonymizedName
+		user.Email = fmt.Sprintf("deleted-%d-%s@users.invalid", user.ID, suffix[:8])
+		user.Password = hashed
+		user.Phone = ""
+		user.AvatarURL = ""
+		user.Role = models.RoleUser
+		user.OrganizationID = nil
+		user.Organization = nil
+		user.EmailVerifiedAt = nil
+		user.VerificationSentAt = nil
+		user.AnonymizedAt = &now
+		return s.UserRepo.WithTx(tx).Update(user)
+	})
+	if err != nil {
+		return err
+	}
+
+	if avatar != "" {
+		if err := s.StorageService.DeleteFile(avatar); err != nil {
+			log.Printf("Error deleting avatar of user %d: %v", user.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,829 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/robaa12/mawid/internal/utils"
+	"github.com/robaa12/mawid/pkg/models"
+	"github.com/robaa12/mawid/pkg/repository"
+	"gorm.io/gorm"
+)
+
+// Edit scopes for changes made through a single occurrence of a series
+const (
+	SeriesScopeThis      = "this"
+	SeriesScopeFollowing = "following"
+	SeriesScopeAll       = "all"
+)
+
+const (
+	// seriesHorizon is how far ahead occurrences of open-ended series are generated
+	seriesHorizon = 365 * 24 * time.Hour
+	// maxSeriesOccurrences caps the number of upcoming events a series keeps
+	// ahead of time
+	maxSeriesOccurrences = 500
+)
+
+type (
+	CreateSeriesInput struct {
+		Name              string   `json:"name" binding:"required"`
+		Description       string   `json:"description" binding:"required"`
+		CategoryID        uint     `json:"category_id" binding:"required"`
+		StartDate         string   `json:"start_date" binding:"required"`
+		DurationMinutes   int      `json:"duration_minutes" binding:"min=0"`
+		TimeZone          string   `json:"time_zone"`
+		RRule             string   `json:"rrule" binding:"required"`
+		ExDates           []string `json:"exdates"`
+		Venue             string   `json:"venue"`
+		VenueID           uint     `json:"venue_id"`
+		Price             float64  `json:"price" binding:"min=0"`
+		Capacity          int      `json:"capacity" binding:"min=0"`
+		MaxTicketsPerUser int      `json:"max_tickets_per_user" binding:"min=0"`
+		Tags              []string `json:"tags"`
+		OrganizationID    *uint    `json:"organization_id"`
+	}
+
+	// UpdateSeriesInput changes the series template. EventDate moves the
+	// series start, shifting every occurrence by the same amount.
+	UpdateSeriesInput struct {
+		UpdateEventInput
+		RRule   string   `json:"rrule"`
+		ExDates []string `json:"exdates"`
+	}
+
+	SeriesResponse struct {
+		ID                uint            `json:"id"`
+		Name              string          `json:"name"`
+		Description       string          `json:"description"`
+		Category          models.Category `json:"category"`
+		OrganizationID    *uint           `json:"organization_id"`
+		StartDate         time.Time       `json:"start_date"`
+		DurationMinutes   int             `json:"duration_minutes"`
+		TimeZone          string          `json:"time_zone"`
+		RRule             string          `json:"rrule"`
+		ExDates           []time.Time     `json:"exdates"`
+		Venue             string          `json:"venue"`
+		VenueID           *uint           `json:"venue_id"`
+		Price             float64         `json:"price"`
+		Capacity          int             `json:"capacity"`
+		MaxTicketsPerUser int             `json:"max_tickets_per_user"`
+		Tags              []string        `json:"tags"`
+		Occurrences       []EventResponse `json:"occurrences"`
+		CreatedAt         time.Time       `json:"created_at"`
+		UpdatedAt         time.Time       `json:"updated_at"`
+	}
+)
+
+func (s *EventService) CreateSeries(actor Actor, input CreateSeriesInput) (*SeriesResponse, error) {
+	organizationID, err := s.resolveOrganization(actor, input.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeZone, loc, err := s.resolveTimeZone(input.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, err := s.parseEventTime(input.StartDate, loc)
+	if err != nil {
+		return nil, errors.New("invalid start date format")
+	}
+
+	durationMinutes := input.DurationMinutes
+	if durationMinutes == 0 {
+		durationMinutes = int(defaultEventDuration / time.Minute)
+	}
+
+	rule, err := utils.ParseRRule(input.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	exDates, err := s.parseExDates(input.ExDates, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCategory(input.CategoryID, organizationID); err != nil {
+		return nil, err
+	}
+
+	if input.Capacity < 0 || input.MaxTicketsPerUser < 0 {
+		return nil, errors.New("capacity and ticket limit cannot be negative")
+	}
+
+	venueID, venueName, capacity, err := s.resolveVenue(input.VenueID, input.Venue, input.Capacity)
+	if err != nil {
+		return nil, err
+	}
+
+	series := models.EventSeries{
+		Name:              input.Name,
+		Description:       input.Description,
+		CategoryID:        input.CategoryID,
+		OrganizationID:    organizationID,
+		StartDate:         startDate,
+		DurationMinutes:   durationMinutes,
+		TimeZone:          timeZone,
+		RRule:             rule.String(),
+		ExDates:           exDates,
+		Venue:             venueName,
+		VenueID:           venueID,
+		Price:             input.Price,
+		Capacity:          capacity,
+		MaxTicketsPerUser: input.MaxTicketsPerUser,
+		Tags:              normalizeTagNames(input.Tags),
+	}
+
+	var affected []uint
+	err = s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.EventRepo.WithTx(tx).CreateSeries(&series); err != nil {
+			return err
+		}
+
+		affected, err = s.syncSeries(tx, &series)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateEventsCache(affected)
+	return s.GetSeries(series.ID)
+}
+
+func (s *EventService) GetSeries(id uint) (*SeriesResponse, error) {
+	series, err := s.EventRepo.GetSeriesByID(id)
+	if err != nil {
+		return nil, errors.New("series not found")
+	}
+
+	events, err := s.EventRepo.GetSeriesEvents(id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := make([]EventResponse, 0, len(events))
+	for _, evt := range events {
+		occurrences = append(occurrences, *s.mapEventToResponse(evt))
+	}
+	if err := s.applyAvailability(occurrences); err != nil {
+		return nil, err
+	}
+
+	return &SeriesResponse{
+		ID:                series.ID,
+		Name:              series.Name,
+		Description:       series.Description,
+		Category:          series.Category,
+		OrganizationID:    series.OrganizationID,
+		StartDate:         series.StartDate.UTC(),
+		DurationMinutes:   series.DurationMinutes,
+		TimeZone:          series.TimeZone,
+		RRule:             series.RRule,
+		ExDates:           series.ExDates,
+		Venue:             series.Venue,
+		VenueID:           series.VenueID,
+		Price:             series.Price,
+		Capacity:          series.Capacity,
+		MaxTicketsPerUser: series.MaxTicketsPerUser,
+		Tags:              series.Tags,
+		Occurrences:       occurrences,
+		CreatedAt:         series.CreatedAt,
+		UpdatedAt:         series.UpdatedAt,
+	}, nil
+}
+
+// UpdateSeries edits the whole series and regenerates its upcoming occurrences
+func (s *EventService) UpdateSeries(actor Actor, id uint, input UpdateSeriesInput) (*SeriesResponse, error) {
+	series, err := s.managedSeries(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	shift, err := s.seriesSchedule(series, &input, series.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.saveSeries(series, input, shift); err != nil {
+		return nil, err
+	}
+
+	return s.GetSeries(series.ID)
+}
+
+// UpdateEventInSeries applies an edit made through one occurrence to either
+// that occurrence and the ones after it, or to the whole series. Edits scoped
+// to the single occurrence go through UpdateEvent.
+func (s *EventService) UpdateEventInSeries(actor Actor, eventID uint, scope string, input UpdateSeriesInput) (*SeriesResponse, error) {
+	evt, series, err := s.getOccurrence(actor, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	shift, err := s.seriesSchedule(series, &input, *evt.OccurrenceDate)
+	if err != nil {
+		return nil, err
+	}
+
+	switch scope {
+	case SeriesScopeAll:
+		if err := s.saveSeries(series, input, shift); err != nil {
+			return nil, err
+		}
+		return s.GetSeries(series.ID)
+
+	case SeriesScopeFollowing:
+		following, err := s.splitSeries(series, *evt.OccurrenceDate, input, shift)
+		if err != nil {
+			return nil, err
+		}
+		return s.GetSeries(following.ID)
+
+	default:
+		return nil, utils.Errorf("invalid edit scope %q", scope)
+	}
+}
+
+// DeleteSeries removes a series together with all of its occurrences
+func (s *EventService) DeleteSeries(actor Actor, id uint) error {
+	if _, err := s.managedSeries(actor, id); err != nil {
+		return err
+	}
+
+	var trashed []uint
+	err := s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if trashed, err = s.trashSeriesEvents(tx, id, nil); err != nil {
+			return err
+		}
+		return s.EventRepo.WithTx(tx).DeleteSeries(id)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateEventsCache(trashed)
+	return nil
+}
+
+// DeleteEventInSeries deletes an occurrence and the ones after it, or the
+// whole series the occurrence belongs to
+func (s *EventService) DeleteEventInSeries(actor Actor, eventID uint, scope string) error {
+	evt, series, err := s.getOccurrence(actor, eventID)
+	if err != nil {
+		return err
+	}
+
+	switch scope {
+	case SeriesScopeAll:
+		return s.DeleteSeries(actor, series.ID)
+
+	case SeriesScopeFollowing:
+		splitAt := *evt.OccurrenceDate
+		if !splitAt.After(series.StartDate) {
+			return s.DeleteSeries(actor, series.ID)
+		}
+
+		if err := s.endSeriesBefore(series, splitAt); err != nil {
+			return err
+		}
+
+		var trashed []uint
+		err := s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+			if err := s.EventRepo.WithTx(tx).UpdateSeries(series); err != nil {
+				return err
+			}
+
+			var err error
+			trashed, err = s.trashSeriesEvents(tx, series.ID, &splitAt)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		s.invalidateEventsCache(trashed)
+		return nil
+
+	default:
+		return utils.Errorf("invalid delete scope %q", scope)
+	}
+}
+
+// trashSeriesEvents moves a series' occurrences to the trash, all of them or
+// those from the given time on, and returns their IDs
+func (s *EventService) trashSeriesEvents(tx *gorm.DB, seriesID uint, from *time.Time) ([]uint, error) {
+	events, err := s.EventRepo.WithTx(tx).GetSeriesEvents(seriesID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	at := time.Now()
+	ids := make([]uint, 0, len(events))
+	for i := range events {
+		if _, err := s.trashEvent(tx, &events[i], at); err != nil {
+			return nil, utils.Errorf("failed to delete event %d: %w", events[i].ID, err)
+		}
+		ids = append(ids, events[i].ID)
+	}
+	return ids, nil
+}
+
+// ExtendSeries generates occurrences that have come within the horizon of
+// open-ended series since they were last synced
+func (s *EventService) ExtendSeries() error {
+	allSeries, err := s.EventRepo.GetAllSeries()
+	if err != nil {
+		return err
+	}
+
+	for i := range allSeries {
+		var affected []uint
+		err := s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+			var err error
+			affected, err = s.syncSeries(tx, &allSeries[i])
+			return err
+		})
+		if err != nil {
+			fmt.Println("[SERIES] Failed to extend series", allSeries[i].ID, err)
+			continue
+		}
+		if len(affected) > 0 {
+			s.invalidateEventsCache(affected)
+		}
+	}
+	return nil
+}
+
+func (s *EventService) getOccurrence(actor Actor, eventID uint) (*models.Event, *models.EventSeries, error) {
+	evt, err := s.managedEvent(actor, eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if evt.SeriesID == nil || evt.OccurrenceDate == nil {
+		return nil, nil, errors.New("event is not part of a series")
+	}
+
+	series, err := s.EventRepo.GetSeriesByID(*evt.SeriesID)
+	if err != nil {
+		return nil, nil, errors.New("series not found")
+	}
+
+	return evt, series, nil
+}
+
+// managedSeries loads a series the actor may manage. Series of other
+// organizations are reported as not found.
+func (s *EventService) managedSeries(actor Actor, id uint) (*models.EventSeries, error) {
+	scope, err := actor.scope()
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := s.EventRepo.GetOrganizationSeries(id, scope)
+	if err != nil {
+		return nil, errors.New("series not found")
+	}
+	return series, nil
+}
+
+// saveSeries applies the input and shift to the series template, then
+// regenerates the upcoming occurrences in one transaction
+func (s *EventService) saveSeries(series *models.EventSeries, input UpdateSeriesInput, shift time.Duration) error {
+	if err := s.applySeriesInput(series, input, shift); err != nil {
+		return err
+	}
+
+	var affected []uint
+	err := s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.EventRepo.WithTx(tx).UpdateSeries(series); err != nil {
+			return err
+		}
+
+		var err error
+		affected, err = s.syncSeries(tx, series)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateEventsCache(affected)
+	return nil
+}
+
+// splitSeries ends the series just before splitAt and continues it as a new
+// series carrying the edit, which takes over the occurrences from splitAt on
+func (s *EventService) splitSeries(series *models.EventSeries, splitAt time.Time, input UpdateSeriesInput, shift time.Duration) (*models.EventSeries, error) {
+	if !splitAt.After(series.StartDate) {
+		if err := s.saveSeries(series, input, shift); err != nil {
+			return nil, err
+		}
+		return series, nil
+	}
+
+	rule, err := utils.ParseRRule(series.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	nextRule := *rule
+	if rule.Count > 0 {
+		nextRule.Count = rule.Count - rule.CountBefore(series.StartDate, splitAt)
+	}
+
+	following := *series
+	following.ID = 0
+	following.StartDate = splitAt
+	following.RRule = nextRule.String()
+	following.Tags = append([]string(nil), series.Tags...)
+	following.ExDates = nil
+	for _, ex := range series.ExDates {
+		if !ex.Before(splitAt) {
+			following.ExDates = append(following.ExDates, ex)
+		}
+	}
+
+	if err := s.endSeriesBefore(series, splitAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.applySeriesInput(&following, input, shift); err != nil {
+		return nil, err
+	}
+
+	var affected []uint
+	err = s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+		eventRepo := s.EventRepo.WithTx(tx)
+		if err := eventRepo.UpdateSeries(series); err != nil {
+			return err
+		}
+		if err := eventRepo.CreateSeries(&following); err != nil {
+			return err
+		}
+		if err := eventRepo.MoveSeriesEvents(series.ID, following.ID, splitAt); err != nil {
+			return err
+		}
+
+		for _, target := range []*models.EventSeries{series, &following} {
+			ids, err := s.syncSeries(tx, target)
+			if err != nil {
+				return err
+			}
+			affected = append(affected, ids...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateEventsCache(affected)
+	return &following, nil
+}
+
+// endSeriesBefore rewrites the rule so the series stops right before the
+// given occurrence
+func (s *EventService) endSeriesBefore(series *models.EventSeries, splitAt time.Time) error {
+	rule, err := utils.ParseRRule(series.RRule)
+	if err != nil {
+		return err
+	}
+
+	until := splitAt.Add(-time.Second).UTC()
+	rule.Count = 0
+	rule.Until = &until
+	series.RRule = rule.String()
+
+	var kept []time.Time
+	for _, ex := range series.ExDates {
+		if ex.Before(splitAt) {
+			kept = append(kept, ex)
+		}
+	}
+	series.ExDates = kept
+	return nil
+}
+
+// seriesSchedule works out how far an edit moves the series and turns a new
+// end time into a duration. reference is the start of the occurrence the
+// edit was made through.
+func (s *EventService) seriesSchedule(series *models.EventSeries, input *UpdateSeriesInput, reference time.Time) (time.Duration, error) {
+	loc := models.LoadLocation(series.TimeZone)
+	if input.TimeZone != "" {
+		var err error
+		if _, loc, err = s.resolveTimeZone(input.TimeZone); err != nil {
+			return 0, err
+		}
+	}
+
+	shift := time.Duration(0)
+	if input.EventDate != "" {
+		newDate, err := s.parseEventTime(input.EventDate, loc)
+		if err != nil {
+			return 0, errors.New("invalid date format")
+		}
+		shift = newDate.Sub(reference)
+	}
+
+	if input.EndDate != "" {
+		endDate, err := s.parseEventTime(input.EndDate, loc)
+		if err != nil {
+			return 0, errors.New("invalid end date format")
+		}
+		minutes := int(endDate.Sub(reference.Add(shift)) / time.Minute)
+		input.DurationMinutes = &minutes
+	}
+
+	return shift, nil
+}
+
+func (s *EventService) applySeriesInput(series *models.EventSeries, input UpdateSeriesInput, shift time.Duration) error {
+	if input.Name != "" {
+		series.Name = input.Name
+	}
+
+	if input.Description != "" {
+		series.Description = input.Description
+	}
+
+	if input.CategoryID != 0 {
+		if err := s.checkCategory(input.CategoryID, series.OrganizationID); err != nil {
+			return err
+		}
+		series.CategoryID = input.CategoryID
+	}
+
+	if input.VenueID != nil {
+		if *input.VenueID == 0 {
+			series.VenueID = nil
+		} else {
+			venue, err := s.EventRepo.GetVenueByID(*input.VenueID)
+			if err != nil {
+				return errors.New("venue not found")
+			}
+			series.VenueID = &venue.ID
+			series.Venue = venue.Name
+		}
+	}
+
+	if input.Venue != "" {
+		series.Venue = input.Venue
+	}
+
+	if input.Price != nil {
+		if *input.Price < 0 {
+			return errors.New("price cannot be negative")
+		}
+		series.Price = *input.Price
+	}
+
+	if input.Capacity != nil {
+		if *input.Capacity < 0 {
+			return errors.New("capacity cannot be negative")
+		}
+		series.Capacity = *input.Capacity
+	}
+
+	if input.MaxTicketsPerUser != nil {
+		if *input.MaxTicketsPerUser < 0 {
+			return errors.New("ticket limit cannot be negative")
+		}
+		series.MaxTicketsPerUser = *input.MaxTicketsPerUser
+	}
+
+	if input.Tags != nil {
+		series.Tags = normalizeTagNames(input.Tags)
+	}
+
+	if input.TimeZone != "" {
+		timeZone, _, err := s.resolveTimeZone(input.TimeZone)
+		if err != nil {
+			return err
+		}
+		series.TimeZone = timeZone
+	}
+
+	if input.DurationMinutes != nil {
+		if *input.DurationMinutes <= 0 {
+			return errors.New("event must end after it starts")
+		}
+		series.DurationMinutes = *input.DurationMinutes
+	}
+
+	if shift != 0 {
+		series.StartDate = series.StartDate.Add(shift)
+		for i := range series.ExDates {
+			series.ExDates[i] = series.ExDates[i].Add(shift)
+		}
+	}
+
+	if input.RRule != "" {
+		rule, err := utils.ParseRRule(input.RRule)
+		if err != nil {
+			return err
+		}
+		series.RRule = rule.String()
+	}
+
+	if input.ExDates != nil {
+		exDates, err := s.parseExDates(input.ExDates, models.LoadLocation(series.TimeZone))
+		if err != nil {
+			return err
+		}
+		series.ExDates = exDates
+	}
+
+	return nil
+}
+
+// syncSeries makes the upcoming occurrences of a series match its rule and
+// template. Occurrences are matched by calendar day, so moving the series to
+// another time of day updates events in place. Occurrences the rule no
+// longer produces are deleted, or detached into standalone events when they
+// already have bookings or were edited on their own. It returns the IDs of
+// every event it touched.
+func (s *EventService) syncSeries(tx *gorm.DB, series *models.EventSeries) ([]uint, error) {
+	eventRepo := s.EventRepo.WithTx(tx)
+	bookingRepo := s.BookingRepo.WithTx(tx)
+
+	rule, err := utils.ParseRRule(series.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expand in the series' zone so occurrences keep their local time of day
+	loc := models.LoadLocation(series.TimeZone)
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	wanted := make(map[string]time.Time)
+	for _, occ := range rule.Occurrences(series.StartDate.In(loc), series.ExDates, from, now.Add(seriesHorizon), maxSeriesOccurrences) {
+		wanted[occurrenceKey(occ, loc)] = occ.UTC()
+	}
+
+	existing, err := eventRepo.GetSeriesEvents(series.ID, &from)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.resolveTags(eventRepo, series.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	var affected []uint
+	for i := range existing {
+		evt := &existing[i]
+		key := occurrenceKey(*evt.OccurrenceDate, loc)
+		occ, ok := wanted[key]
+		if !ok {
+			if err := s.dropOccurrence(tx, evt); err != nil {
+				return nil, err
+			}
+			affected = append(affected, evt.ID)
+			continue
+		}
+		delete(wanted, key)
+
+		if evt.SeriesOverride || evt.Status == models.EventStatusCancelled {
+			continue
+		}
+
+		if series.Capacity > 0 {
+			booked, err := bookingRepo.CountActiveSeats(evt.ID)
+			if err != nil {
+				return nil, err
+			}
+			if int64(series.Capacity) < booked {
+				return nil, utils.Errorf("capacity cannot be lower than the %d seats already booked for the %s occurrence",
+					booked, evt.EventDate.Format("2006-01-02"))
+			}
+		}
+
+		applySeriesTemplate(evt, series, occ)
+		if err := eventRepo.UpdateOccurrence(evt); err != nil {
+			return nil, err
+		}
+		if err := tx.Model(evt).Association("Tags").Replace(tags); err != nil {
+			return nil, utils.Errorf("failed to associate tags with event: %w", err)
+		}
+		affected = append(affected, evt.ID)
+	}
+
+	missing := make([]time.Time, 0, len(wanted))
+	for _, occ := range wanted {
+		missing = append(missing, occ)
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Before(missing[j]) })
+
+	for _, occ := range missing {
+		evt := models.Event{Status: models.EventStatusPublished, PublishAt: &now}
+		applySeriesTemplate(&evt, series, occ)
+		if err := eventRepo.UpdateOccurrence(&evt); err != nil {
+			return nil, err
+		}
+		if len(tags) > 0 {
+			if err := tx.Model(&evt).Association("Tags").Replace(tags); err != nil {
+				return nil, utils.Errorf("failed to associate tags with event: %w", err)
+			}
+		}
+		affected = append(affected, evt.ID)
+	}
+
+	return affected, nil
+}
+
+// dropOccurrence removes an occurrence the rule no longer produces
+func (s *EventService) dropOccurrence(tx *gorm.DB, evt *models.Event) error {
+	eventRepo := s.EventRepo.WithTx(tx)
+
+	hasBookings, err := eventRepo.HasBookings(evt.ID)
+	if err != nil {
+		return err
+	}
+
+	if hasBookings || evt.SeriesOverride {
+		fmt.Printf("[SERIES] Keeping event %d as a standalone event, it is no longer part of series %d\n", evt.ID, *evt.SeriesID)
+		return eventRepo.DetachFromSeries(evt.ID)
+	}
+
+	if err := s.WaitlistRepo.WithTx(tx).DeleteByEvent(evt.ID); err != nil {
+		return err
+	}
+	return eventRepo.Purge(evt.ID)
+}
+
+// excludeOccurrence records a deleted occurrence as an exception date so the
+// series does not generate it again
+func (s *EventService) excludeOccurrence(eventRepo *repository.EventRepository, evt *models.Event) error {
+	if evt.SeriesID == nil || evt.OccurrenceDate == nil {
+		return nil
+	}
+
+	series, err := eventRepo.GetSeriesByID(*evt.SeriesID)
+	if err != nil {
+		return nil
+	}
+
+	series.ExDates = append(series.ExDates, *evt.OccurrenceDate)
+	return eventRepo.UpdateSeries(series)
+}
+
+func (s *EventService) resolveTags(eventRepo *repository.EventRepository, names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		tag, err := eventRepo.FindOrCreateTag(name)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, *tag)
+	}
+	return tags, nil
+}
+
+func (s *EventService) parseExDates(values []string, loc *time.Location) ([]time.Time, error) {
+	exDates := make([]time.Time, 0, len(values))
+	for _, value := range values {
+		exDate, err := s.parseEventTime(value, loc)
+		if err != nil {
+			return nil, utils.Errorf("invalid exception date %q", value)
+		}
+		exDates = append(exDates, exDate)
+	}
+	return exDates, nil
+}
+
+func applySeriesTemplate(evt *models.Event, series *models.EventSeries, occurrence time.Time) {
+	seriesID := series.ID
+	evt.Name = series.Name
+	evt.Description = series.Description
+	evt.CategoryID = series.CategoryID
+	evt.OrganizationID = series.OrganizationID
+	evt.EventDate = occurrence
+	duration := time.Duration(series.DurationMinutes) * time.Minute
+	if duration <= 0 {
+		duration = defaultEventDuration
+	}
+	evt.EndDate = occurrence.Add(duration)
+	evt.TimeZone = series.TimeZone
+	evt.Venue = series.Venue
+	evt.VenueID = series.VenueID
+	evt.Price = series.Price
+	evt.Capacity = series.Capacity
+	evt.MaxTicketsPerUser = series.MaxTicketsPerUser
+	evt.SeriesID = &seriesID
+	evt.OccurrenceDate = &occurrence
+}
+
+// occurrenceKey identifies an occurrence by its calendar day in the series' zone
+func occurrenceKey(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("2006-01-02")
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/robaa12/mawid/internal/utils"
+	"github.com/robaa12/mawid/pkg/models"
+	"github.com/robaa12/mawid/pkg/repository"
+	"gorm.io/gorm"
+)
+
+// ErrTagExists is returned when renaming a tag to the name of another tag.
+// Such tags should be merged instead.
+var ErrTagExists = errors.New("a tag with this name already exists")
+
+type (
+	RenameTagInput struct {
+		Name string `json:"name" binding:"required,max=100"`
+	}
+
+	// MergeTagsInput folds the source tags into the target tag
+	MergeTagsInput struct {
+		SourceIDs []uint `json:"source_ids" binding:"required,min=1"`
+		TargetID  uint   `json:"target_id" binding:"required"`
+	}
+)
+
+// GetTags lists every tag with the number of listed events carrying it
+func (s *EventService) GetTags() ([]repository.TagUsage, error) {
+	return s.EventRepo.GetTagUsage()
+}
+
+// RenameTag renames a tag on every event and series that uses it. Changing
+// only the letter case is allowed; taking another tag's name is not.
+func (s *EventService) RenameTag(id uint, input RenameTagInput) (*models.Tag, error) {
+	tag, err := s.EventRepo.GetTagByID(id)
+	if err != nil {
+		return nil, errors.New("tag not found")
+	}
+
+	name := normalizeTagName(input.Name)
+	if name == "" {
+		return nil, errors.New("tag name is required")
+	}
+	if existing, err := s.EventRepo.GetTagByName(name); err == nil && existing.ID != tag.ID {
+		return nil, utils.Errorf("%w, merge tag %d into tag %d instead", ErrTagExists, tag.ID, existing.ID)
+	}
+
+	oldName := tag.Name
+	tag.Name = name
+	err = s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+		eventRepo := s.EventRepo.WithTx(tx)
+		if err := eventRepo.UpdateTag(tag); err != nil {
+			return err
+		}
+		return s.rewriteSeriesTags(eventRepo, []string{oldName}, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateTaggedEvents([]uint{tag.ID})
+	return tag, nil
+}
+
+// MergeTags moves every event and series of the source tags over to the
+// target tag and deletes the source tags
+func (s *EventService) MergeTags(input MergeTagsInput) (*models.Tag, error) {
+	target, err := s.EventRepo.GetTagByID(input.TargetID)
+	if err != nil {
+		return nil, errors.New("target tag not found")
+	}
+
+	sourceIDs := make([]uint, 0, len(input.SourceIDs))
+	for _, id := range input.SourceIDs {
+		if id == target.ID {
+			return nil, errors.New("cannot merge a tag into itself")
+		}
+		sourceIDs = append(sourceIDs, id)
+	}
+
+	sources, err := s.EventRepo.GetTagsByIDs(sourceIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("source tags not found")
+	}
+
+	names := make([]string, 0, len(sources))
+	ids := make([]uint, 0, len(sources))
+	for _, tag := range sources {
+		names = append(names, tag.Name)
+		ids = append(ids, tag.ID)
+	}
+
+	// The event IDs are needed for the cache before the links are rewritten
+	eventIDs, err := s.EventRepo.GetTaggedEventIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+		eventRepo := s.EventRepo.WithTx(tx)
+		if err := eventRepo.MergeTags(ids, target.ID); err != nil {
+			return utils.Errorf("failed to merge tags: %w", err)
+		}
+		return s.rewriteSeriesTags(eventRepo, names, target.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateEventsCache(eventIDs)
+	return target, nil
+}
+
+// DeleteTag removes a tag from every event and series and deletes it
+func (s *EventService) DeleteTag(id uint) error {
+	tag, err := s.EventRepo.GetTagByID(id)
+	if err != nil {
+		return errors.New("tag not found")
+	}
+
+	eventIDs, err := s.EventRepo.GetTaggedEventIDs([]uint{id})
+	if err != nil {
+		return err
+	}
+
+	err = s.EventRepo.DB.Transaction(func(tx *gorm.DB) error {
+		eventRepo := s.EventRepo.WithTx(tx)
+		if err := eventRepo.DeleteTags([]uint{id}); err != nil {
+			return utils.Errorf("failed to delete tag: %w", err)
+		}
+		return s.rewriteSeriesTags(eventRepo, []string{tag.Name}, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateEventsCache(eventIDs)
+	return nil
+}
+
+// rewriteSeriesTags replaces the given tag names in series templates with
+// replacement, or drops them when replacement is empty, so future
+// occurrences do not bring the old tags back
+func (s *EventService) rewriteSeriesTags(eventRepo *repository.EventRepository, names []string, replacement string) error {
+	allSeries, err := eventRepo.GetSeriesWithTags(names)
+	if err != nil {
+		return err
+	}
+
+	for i := range allSeries {
+		series := &allSeries[i]
+
+		tags := make([]string, 0, len(series.Tags))
+		changed := false
+		for _, tag := range series.Tags {
+			if containsTagName(names, tag) {
+				changed = true
+				if replacement == "" {
+					continue
+				}
+				tag = replacement
+			}
+			tags = append(tags, tag)
+		}
+		if !changed {
+			continue
+		}
+
+		series.Tags = normalizeTagNames(tags)
+		if err := eventRepo.UpdateSeries(series); err != nil {
+			return utils.Errorf("failed to update series %d: %w", series.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *EventService) invalidateTaggedEvents(tagIDs []uint) {
+	eventIDs, err := s.EventRepo.GetTaggedEventIDs(tagIDs)
+	if err != nil {
+		fmt.Printf("[CACHE] Failed to look up events of tags %v: %v\n", tagIDs, err)
+		return
+	}
+	s.invalidateEventsCache(eventIDs)
+}
+
+// normalizeTagName trims a tag name and collapses runs of whitespace
+func normalizeTagName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// normalizeTagNames normalizes tag names and drops empty ones and names
+// that differ from an earlier one only in letter case
+func normalizeTagNames(names []string) []string {
+	var result []string
+	for _, name := range names {
+		name = normalizeTagName(name)
+		if name != "" && !containsTagName(result, name) {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func containsTagName(names []string, name string) bool {
+	for _, existing := range names {
+		if strings.EqualFold(existing, name) {
+			return true
+		}
+	}
+	return false
+}
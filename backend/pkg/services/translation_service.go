@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/robaa12/mawid/internal/utils"
+	"github.com/robaa12/mawid/pkg/models"
+)
+
+// ErrUnsupportedLocale is returned when translating content into a language
+// the platform does not serve
+var ErrUnsupportedLocale = errors.New("unsupported locale")
+
+// TranslationInput is the name and description of an event or category in
+// one locale. A description left empty falls back to the original one.
+type TranslationInput struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description"`
+}
+
+func (s *EventService) GetEventTranslations(actor Actor, eventID uint) ([]models.EventTranslation, error) {
+	if _, err := s.managedEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+	return s.EventRepo.GetEventTranslations(eventID)
+}
+
+// SetEventTranslation creates or replaces an event's translation into locale
+func (s *EventService) SetEventTranslation(actor Actor, eventID uint, locale string, input TranslationInput) (*models.EventTranslation, error) {
+	if !utils.IsSupportedLocale(locale) {
+		return nil, ErrUnsupportedLocale
+	}
+	if _, err := s.managedEvent(actor, eventID); err != nil {
+		return nil, err
+	}
+
+	translation := &models.EventTranslation{
+		EventID:     eventID,
+		Locale:      locale,
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+	}
+	if err := s.EventRepo.SaveEventTranslation(translation); err != nil {
+		return nil, err
+	}
+
+	s.invalidateEventCache(eventID)
+	return translation, nil
+}
+
+func (s *EventService) DeleteEventTranslation(actor Actor, eventID uint, locale string) error {
+	if _, err := s.managedEvent(actor, eventID); err != nil {
+		return err
+	}
+
+	found, err := s.EventRepo.DeleteEventTranslation(eventID, locale)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("translation not found")
+	}
+
+	s.invalidateEventCache(eventID)
+	return nil
+}
+
+func (s *EventService) GetCategoryTranslations(actor Actor, categoryID uint) ([]models.CategoryTranslation, error) {
+	if _, err := s.managedCategory(actor, categoryID); err != nil {
+		return nil, err
+	}
+	return s.EventRepo.GetCategoryTranslations(categoryID)
+}
+
+// SetCategoryTranslation creates or replaces a category's translation into
+// locale
+func (s *EventService) SetCategoryTranslation(actor Actor, categoryID uint, locale string, input TranslationInput) (*models.CategoryTranslation, error) {
+	if !utils.IsSupportedLocale(locale) {
+		return nil, ErrUnsupportedLocale
+	}
+	if _, err := s.managedCategory(actor, categoryID); err != nil {
+		return nil, err
+	}
+
+	translation := &models.CategoryTranslation{
+		CategoryID:  categoryID,
+		Locale:      locale,
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+	}
+	if err := s.EventRepo.SaveCategoryTranslation(translation); err != nil {
+		return nil, err
+	}
+
+	if err := s.invalidateCategoryEvents(categoryID); err != nil {
+		return nil, err
+	}
+	return translation, nil
+}
+
+func (s *EventService) DeleteCategoryTranslation(actor Actor, categoryID uint, locale string) error {
+	if _, err := s.managedCategory(actor, categoryID); err != nil {
+		return err
+	}
+
+	found, err := s.EventRepo.DeleteCategoryTranslation(categoryID, locale)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("translation not found")
+	}
+
+	return s.invalidateCategoryEvents(categoryID)
+}
+
+// invalidateCategoryEvents drops the cached events of a category, which
+// embed its translations
+func (s *EventService) invalidateCategoryEvents(categoryID uint) error {
+	eventIDs, err := s.EventRepo.GetCategoryEventIDs(categoryID)
+	if err != nil {
+		return err
+	}
+	s.invalidateEventsCache(eventIDs)
+	return nil
+}
+
+// Localized returns a copy of the event with its name, description and
+// category in locale where translated
+func (r EventResponse) Localized(locale string) EventResponse {
+	for _, translation := range r.translations {
+		if translation.Locale != locale {
+			continue
+		}
+		if translation.Name != "" {
+			r.Name = translation.Name
+		}
+		if translation.Description != "" {
+			r.Description = translation.Description
+		}
+		break
+	}
+	r.Category = r.Category.Localized(locale)
+	return r
+}
+
+func (r *EventResponse) Localize(locale string) any {
+	if r == nil {
+		return r
+	}
+	localized := r.Localized(locale)
+	return &localized
+}
+
+func (p *PaginatedEvents) Localize(locale string) any {
+	if p == nil {
+		return p
+	}
+	localized := *p
+	localized.Events = localizeEvents(p.Events, locale)
+	return &localized
+}
+
+func (r *SeriesResponse) Localize(locale string) any {
+	if r == nil {
+		return r
+	}
+	localized := *r
+	localized.Category = r.Category.Localized(locale)
+	localized.Occurrences = localizeEvents(r.Occurrences, locale)
+	return &localized
+}
+
+// CategoryList is a list of categories answered in the locale of a request
+type CategoryList []models.Category
+
+func (l CategoryList) Localize(locale string) any {
+	localized := make([]models.Category, 0, len(l))
+	for _, category := range l {
+		localized = append(localized, category.Localized(locale))
+	}
+	return localized
+}
+
+// CategoryTree is a category tree answered in the locale of a request
+type CategoryTree []*CategoryNode
+
+func (t CategoryTree) Localize(locale string) any {
+	localized := make([]*CategoryNode, 0, len(t))
+	for _, node := range t {
+		localized = append(localized, &CategoryNode{
+			Category:   node.Category.Localized(locale),
+			EventCount: node.EventCount,
+			Children:   CategoryTree(node.Children).Localize(locale).([]*CategoryNode),
+		})
+	}
+	return localized
+}
+
+func localizeEvents(events []EventResponse, locale string) []EventResponse {
+	if events == nil {
+		return nil
+	}
+	localized := make([]EventResponse, len(events))
+	for i, event := range events {
+		localized[i] = event.Localized(locale)
+	}
+	return localized
+}